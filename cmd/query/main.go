@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"time"
 
 	_ "modernc.org/sqlite"
 )
@@ -70,6 +71,185 @@ func main() {
 	}
 	fmt.Println()
 
+	// 查询下载进度
+	progressRows, err := db.Query(`
+		SELECT source_request_id, title, subscribe_status, download_progress, download_speed, download_eta
+		FROM subscription_tracking
+		WHERE subscribe_status IN ('downloading', 'downloaded')
+		ORDER BY updated_at DESC
+	`)
+	if err != nil {
+		log.Printf("查询下载进度失败: %v", err)
+	} else {
+		fmt.Println("╔═══════════════════════════════════════════╗")
+		fmt.Println("║         下载进度                           ║")
+		fmt.Println("╚═══════════════════════════════════════════╝")
+		hasProgress := false
+		for progressRows.Next() {
+			hasProgress = true
+			var sourceID, title, status, speed, eta string
+			var progress float64
+			if err := progressRows.Scan(&sourceID, &title, &status, &progress, &speed, &eta); err != nil {
+				log.Printf("扫描下载进度失败: %v", err)
+				continue
+			}
+			fmt.Printf("\n[请求 #%s] %s\n", sourceID, title)
+			fmt.Printf("  状态: %s\n", status)
+			fmt.Printf("  进度: %.1f%%\n", progress)
+			if speed != "" {
+				fmt.Printf("  速度: %s\n", speed)
+			}
+			if eta != "" {
+				fmt.Printf("  剩余: %s\n", eta)
+			}
+		}
+		progressRows.Close()
+		if !hasProgress {
+			fmt.Println("  (无下载中的任务)")
+		}
+		fmt.Println()
+	}
+
+	// 查询剧集单集进度
+	episodeRows, err := db.Query(`
+		SELECT r.title, e.source_request_id, e.season,
+			SUM(CASE WHEN e.status = 'transferred' THEN 1 ELSE 0 END) as transferred,
+			COUNT(*) as total
+		FROM episode_tracking e
+		LEFT JOIN requests r ON r.source_request_id = e.source_request_id
+		GROUP BY e.source_request_id, e.season
+		ORDER BY e.source_request_id, e.season
+	`)
+	if err != nil {
+		log.Printf("查询剧集进度失败: %v", err)
+	} else {
+		fmt.Println("╔═══════════════════════════════════════════╗")
+		fmt.Println("║         剧集入库进度                       ║")
+		fmt.Println("╚═══════════════════════════════════════════╝")
+		hasEpisodes := false
+		lastSourceID := ""
+		for episodeRows.Next() {
+			hasEpisodes = true
+			var title sql.NullString
+			var sourceID string
+			var season, transferred, episodeTotal int
+			if err := episodeRows.Scan(&title, &sourceID, &season, &transferred, &episodeTotal); err != nil {
+				log.Printf("扫描剧集进度失败: %v", err)
+				continue
+			}
+			if sourceID != lastSourceID {
+				fmt.Printf("\n[请求 #%s] %s\n", sourceID, title.String)
+				lastSourceID = sourceID
+			}
+			fmt.Printf("  S%02d: %d/%d\n", season, transferred, episodeTotal)
+		}
+		episodeRows.Close()
+		if !hasEpisodes {
+			fmt.Println("  (无剧集跟踪记录)")
+		}
+		fmt.Println()
+	}
+
+	// 查询部分入库的剧集
+	partialRows, err := db.Query(`
+		SELECT source_request_id, title, missing_episodes
+		FROM subscription_tracking
+		WHERE subscribe_status = 'partially_transferred'
+		ORDER BY source_request_id
+	`)
+	if err != nil {
+		log.Printf("查询部分入库剧集失败: %v", err)
+	} else {
+		fmt.Println("╔═══════════════════════════════════════════╗")
+		fmt.Println("║         部分入库（缺失已播出的集）         ║")
+		fmt.Println("╚═══════════════════════════════════════════╝")
+		hasPartial := false
+		for partialRows.Next() {
+			hasPartial = true
+			var sourceID, title, missing string
+			if err := partialRows.Scan(&sourceID, &title, &missing); err != nil {
+				log.Printf("扫描部分入库剧集失败: %v", err)
+				continue
+			}
+			fmt.Printf("[请求 #%s] %s\n  缺失: %s\n", sourceID, title, missing)
+		}
+		partialRows.Close()
+		if !hasPartial {
+			fmt.Println("  (无部分入库的剧集)")
+		}
+		fmt.Println()
+	}
+
+	// 查询处理耗时（最近 4 周，由跟踪器每日报告时统计）
+	sinceWeek := weekStart(time.Now()).AddDate(0, 0, -21).Format("2006-01-02")
+	statRows, err := db.Query(`
+		SELECT week, media_type, stage, samples, p50_seconds, p90_seconds, max_seconds
+		FROM latency_stats
+		WHERE week >= ?
+		ORDER BY week DESC, media_type ASC
+	`, sinceWeek)
+	if err != nil {
+		log.Printf("查询处理耗时失败: %v", err)
+	} else {
+		fmt.Println("╔═══════════════════════════════════════════╗")
+		fmt.Println("║         处理耗时（最近 4 周）              ║")
+		fmt.Println("╚═══════════════════════════════════════════╝")
+		hasStats := false
+		lastGroup := ""
+		for statRows.Next() {
+			var week, mediaType, stage string
+			var samples int
+			var p50, p90, longest int64
+			if err := statRows.Scan(&week, &mediaType, &stage, &samples, &p50, &p90, &longest); err != nil {
+				log.Printf("扫描处理耗时失败: %v", err)
+				continue
+			}
+			hasStats = true
+			if group := week + " " + mediaType; group != lastGroup {
+				fmt.Printf("\n[%s 周] %s\n", week, mediaType)
+				lastGroup = group
+			}
+			fmt.Printf("  %-10s 样本 %-3d 中位 %-10s P90 %-10s 最长 %s\n",
+				stage, samples, formatSeconds(p50), formatSeconds(p90), formatSeconds(longest))
+		}
+		statRows.Close()
+		if !hasStats {
+			fmt.Println("  (暂无耗时统计)")
+		}
+		fmt.Println()
+	}
+
+	slowRows, err := db.Query(`
+		SELECT title, media_type, week, total_seconds
+		FROM request_latency
+		WHERE week >= ? AND total_seconds IS NOT NULL
+		ORDER BY total_seconds DESC
+		LIMIT 5
+	`, sinceWeek)
+	if err != nil {
+		log.Printf("查询最慢请求失败: %v", err)
+	} else {
+		fmt.Println("╔═══════════════════════════════════════════╗")
+		fmt.Println("║         最慢的请求（最近 4 周）            ║")
+		fmt.Println("╚═══════════════════════════════════════════╝")
+		hasSlow := false
+		for slowRows.Next() {
+			var title, mediaType, week string
+			var total int64
+			if err := slowRows.Scan(&title, &mediaType, &week, &total); err != nil {
+				log.Printf("扫描最慢请求失败: %v", err)
+				continue
+			}
+			hasSlow = true
+			fmt.Printf("  %s (%s, %s 周): %s\n", title, mediaType, week, formatSeconds(total))
+		}
+		slowRows.Close()
+		if !hasSlow {
+			fmt.Println("  (暂无已完成的请求)")
+		}
+		fmt.Println()
+	}
+
 	// 查询 MP 链接和错误
 	linkRows, err := db.Query(`
 		SELECT source_request_id, mp_subscribe_id, state, last_error, retry_count
@@ -113,3 +293,23 @@ func main() {
 	}
 	fmt.Println()
 }
+
+// weekStart 返回所在周的周一零点
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return day.AddDate(0, 0, -offset)
+}
+
+// formatSeconds 将秒数格式化为 "2天3小时"、"5小时20分" 或 "12分"
+func formatSeconds(seconds int64) string {
+	minutes := (seconds + 30) / 60
+	switch {
+	case minutes >= 24*60:
+		return fmt.Sprintf("%d天%d小时", minutes/(24*60), minutes%(24*60)/60)
+	case minutes >= 60:
+		return fmt.Sprintf("%d小时%d分", minutes/60, minutes%60)
+	default:
+		return fmt.Sprintf("%d分", minutes)
+	}
+}
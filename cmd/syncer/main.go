@@ -24,7 +24,7 @@ func main() {
 	// 命令行参数
 	var (
 		showVersion = flag.Bool("version", false, "显示版本信息")
-		mode        = flag.String("mode", "once", "运行模式: once (单次同步) 或 daemon (守护进程)")
+		mode        = flag.String("mode", "once", "运行模式: once (单次同步)、daemon (守护进程)、import (导入 MP 已有订阅) 或 replay-webhooks (重放失败的 Webhook)")
 		dryRun      = flag.Bool("dry-run", false, "干跑模式（仅打印，不实际创建订阅）")
 	)
 	flag.Parse()
@@ -90,6 +90,11 @@ func main() {
 			errChan <- syncer.SyncOnce(ctx)
 		case "daemon":
 			errChan <- syncer.RunDaemon(ctx)
+		case "import":
+			_, err := syncer.ImportMPSubscriptions(ctx)
+			errChan <- err
+		case "replay-webhooks":
+			errChan <- syncer.ReplayWebhooks(ctx)
 		default:
 			errChan <- fmt.Errorf("未知的运行模式: %s", *mode)
 		}
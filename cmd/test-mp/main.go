@@ -11,6 +11,8 @@ import (
 	"os"
 	"strings"
 	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
 )
 
 func main() {
@@ -55,9 +57,9 @@ func main() {
 		fmt.Printf("  ❌ 失败: %v\n", err)
 	}
 
-	// 方式 2: Cookie
-	fmt.Println("\n  尝试方式 2: Cookie: resource_token=<token>")
-	if err := testSSEWithCookie(ctx, mpURL, token); err != nil {
+	// 方式 2: 资源令牌 Cookie（与同步器的 SSE 客户端相同）
+	fmt.Printf("\n  尝试方式 2: Cookie: %s=<resource token>\n", mp.ResourceTokenCookie)
+	if err := testSSEWithCookie(ctx, mpURL, username, password); err != nil {
 		fmt.Printf("  ❌ 失败: %v\n", err)
 	}
 
@@ -239,8 +241,19 @@ func testSSEWithBearer(ctx context.Context, baseURL, token string) error {
 	return nil
 }
 
-// testSSEWithCookie 使用 Cookie 测试 SSE
-func testSSEWithCookie(ctx context.Context, baseURL, token string) error {
+// testSSEWithCookie 使用资源令牌 Cookie 测试 SSE
+// 资源令牌由 MP 在 Bearer 认证的请求中通过 Set-Cookie 下发，Cookie 名称为 mp.ResourceTokenCookie
+func testSSEWithCookie(ctx context.Context, baseURL, username, password string) error {
+	mpClient, err := mp.NewClient(mp.ClientConfig{BaseURL: baseURL, Username: username, Password: password, RateLimitPS: 1}, ctx)
+	if err != nil {
+		return fmt.Errorf("create mp client: %w", err)
+	}
+	resourceToken, err := mpClient.GetResourceToken(ctx)
+	if err != nil {
+		return fmt.Errorf("get resource token: %w", err)
+	}
+	fmt.Printf("  已获取资源令牌，过期时间: %s\n", resourceToken.ExpiresAt.Format(time.RFC3339))
+
 	url := baseURL + "/api/v1/system/message"
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -248,7 +261,7 @@ func testSSEWithCookie(ctx context.Context, baseURL, token string) error {
 		return err
 	}
 
-	req.Header.Set("Cookie", fmt.Sprintf("resource_token=%s", token))
+	req.AddCookie(&http.Cookie{Name: mp.ResourceTokenCookie, Value: resourceToken.Value})
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Cache-Control", "no-cache")
 
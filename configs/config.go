@@ -5,6 +5,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Config 应用配置
@@ -15,18 +16,39 @@ type Config struct {
 	JellyFilter   string // approved, pending 等
 	JellyPageSize int
 
-	// TMDB 配置（可选，用于获取海报）
+	// TMDB 配置（可选，用于获取海报和剧集播出日期）
 	TMDPAPIKey string
 
 	// MoviePilot 配置
-	MPURL           string
-	MPUsername      string // MoviePilot 用户名（必需）
-	MPPassword      string // MoviePilot 密码（必需）
-	MPAuthScheme    string // bearer, x-api-token, query-token
-	MPRateLimitPS   int    // 每秒请求数限制
-	MPDryRun        bool
-	MPTVEpisodeMode string // season 或 episode
-	MPTokenRefresh  int    // Token 刷新间隔（小时），默认 24 小时
+	MPURL             string
+	MPUsername        string // MoviePilot 用户名（必需）
+	MPPassword        string // MoviePilot 密码（必需）
+	MPAuthScheme      string // bearer, x-api-token, query-token
+	MPRateLimitPS     int    // 每秒请求数限制
+	MPDryRun          bool
+	MPTVEpisodeMode   string   // season 或 episode
+	MPTokenRefresh    int      // Token 刷新间隔（小时），默认 24 小时
+	MPExistsKeywords  []string // "已存在"兜底关键词，仅在结构化信息不足时使用
+	MPAdoptExisting   bool     // 同步前接管 MP 中已有的订阅
+	MPCompletedPolicy string   // 入库后的订阅处理：keep, delete, best_version
+	MPBestVersionDays int      // best_version 策略下洗版的天数
+	MPCleanupInterval int      // 订阅清理检查间隔（分钟）
+	MPRetryTransfer   bool     // 入库失败时请求 MP 重新整理
+
+	// 媒体服务器配置（可选，用于确认入库后可观看）
+	MediaServerType         string // jellyfin 或 emby
+	MediaServerURL          string // 为空表示禁用
+	MediaServerAPIKey       string
+	MediaServerScan         bool // 入库后未找到时通知媒体服务器扫描目标目录
+	MediaServerConfirmHours int  // 入库后最多等待多少小时确认可观看
+
+	// 下载器配置（可选，直接检查 MP 为跟踪请求添加的种子是否健康）
+	DownloaderType       string // qbittorrent 或 transmission
+	DownloaderURL        string // 为空表示禁用
+	DownloaderUsername   string
+	DownloaderPassword   string
+	DownloaderStallHours int  // 种子停滞或没有做种者持续多少小时后告警
+	DownloaderReplace    bool // 告警时让 MP 删除种子并重新搜索其他资源
 
 	// 存储配置
 	StoreType string // sqlite 或 json
@@ -44,11 +66,58 @@ type Config struct {
 	TelegramEnabled bool
 	TelegramToken   string
 	TelegramChatIDs []string // 支持多个 chat ID
+	TelegramEvents  []string // 接收的通知事件，为空表示全部
+
+	// Webhook 通知配置
+	WebhookURLs       []string // 为空表示禁用
+	WebhookSecret     string   // HMAC-SHA256 签名密钥，为空时不签名
+	WebhookEvents     []string // 接收的通知事件，为空表示全部
+	WebhookMaxRetries int      // 投递失败后的最大重试次数
+
+	// 邮件通知配置
+	EmailSMTPHost     string // 为空表示禁用
+	EmailSMTPPort     int
+	EmailUsername     string
+	EmailPassword     string
+	EmailFrom         string
+	EmailTo           []string // 接收所有通知的地址
+	EmailMode         string   // immediate 或 digest（digest 仅守护进程模式）
+	EmailDigestTime   string   // 摘要发送时间，格式 HH:MM
+	EmailPerRequester bool     // 同时发送给 Jellyseerr 请求人的邮箱
+	EmailEvents       []string // 接收的通知事件，为空表示全部
+
+	// 企业微信应用消息配置
+	WeComCorpID  string // 为空表示禁用
+	WeComAgentID int
+	WeComSecret  string
+	WeComToUser  string   // 接收成员，多个用 | 分隔，@all 表示全部成员
+	WeComBaseURL string   // API 地址，可以改为代理地址
+	WeComEvents  []string // 接收的通知事件，为空表示全部
+
+	// Bark 推送配置
+	BarkDeviceKeys []string // 为空表示禁用
+	BarkServerURL  string
+	BarkGroup      string
+	BarkIcon       string
+	BarkEvents     []string // 接收的通知事件，为空表示全部
+
+	// Discord Webhook 配置
+	DiscordWebhookURL string // 为空表示禁用
+	DiscordUsername   string
+	DiscordAvatarURL  string
+	DiscordEvents     []string // 接收的通知事件，为空表示全部
 
 	// Tracker 配置
-	TrackerEnabled       bool
-	TrackerCheckInterval int  // 检查间隔（分钟）
-	TrackerSSEEnabled    bool // 是否启用 SSE 监听
+	TrackerEnabled           bool
+	TrackerCheckInterval     int    // 检查间隔（分钟）
+	TrackerSSEEnabled        bool   // 是否启用 SSE 监听
+	TrackerReconcileInterval int    // MP 订阅对账间隔（分钟），0 表示禁用
+	TrackerDriftAction       string // 订阅丢失时的处理方式：orphan 或 recreate
+	TrackerProgressStep      int    // 下载进度每跨过多少百分比通知一次，0 表示不通知
+	TrackerEscalateDays      int    // 订阅多少天仍未下载时在每日报告中提醒人工处理，0 表示不提醒
+	TrackerEpisodeLateHours  int    // 剧集播出多少小时后仍未下载视为超时，0 表示不提醒
+	TrackerWebhookAddr       string // MP 通知 Webhook 监听地址，如 :8090，为空表示禁用
+	TrackerWebhookToken      string // MP 通知 Webhook 的认证令牌
 
 	// SmartRetry 配置
 	SmartRetryEnabled      bool
@@ -74,14 +143,35 @@ func Load() (*Config, error) {
 		TMDPAPIKey: getEnv("TMDB_API_KEY", ""),
 
 		// MoviePilot 默认值
-		MPURL:           getEnv("MP_URL", ""),
-		MPUsername:      getEnv("MP_USERNAME", ""),
-		MPPassword:      getEnv("MP_PASSWORD", ""),
-		MPAuthScheme:    getEnv("MP_AUTH_SCHEME", "bearer"),
-		MPRateLimitPS:   getEnvAsInt("MP_RATE_LIMIT_PER_SEC", 3),
-		MPDryRun:        getEnvAsBool("MP_DRY_RUN", false),
-		MPTVEpisodeMode: getEnv("MP_TV_EPISODE_MODE", "season"),
-		MPTokenRefresh:  getEnvAsInt("MP_TOKEN_REFRESH_HOURS", 24),
+		MPURL:             getEnv("MP_URL", ""),
+		MPUsername:        getEnv("MP_USERNAME", ""),
+		MPPassword:        getEnv("MP_PASSWORD", ""),
+		MPAuthScheme:      getEnv("MP_AUTH_SCHEME", "bearer"),
+		MPRateLimitPS:     getEnvAsInt("MP_RATE_LIMIT_PER_SEC", 3),
+		MPDryRun:          getEnvAsBool("MP_DRY_RUN", false),
+		MPTVEpisodeMode:   getEnv("MP_TV_EPISODE_MODE", "season"),
+		MPTokenRefresh:    getEnvAsInt("MP_TOKEN_REFRESH_HOURS", 24),
+		MPExistsKeywords:  getEnvAsSlice("MP_EXISTS_KEYWORDS", ",", nil),
+		MPAdoptExisting:   getEnvAsBool("MP_ADOPT_EXISTING", true),
+		MPCompletedPolicy: getEnv("MP_COMPLETED_POLICY", "keep"),
+		MPBestVersionDays: getEnvAsInt("MP_BEST_VERSION_DAYS", 7),
+		MPCleanupInterval: getEnvAsInt("MP_CLEANUP_INTERVAL", 60),
+		MPRetryTransfer:   getEnvAsBool("MP_RETRY_TRANSFER", false),
+
+		// 媒体服务器配置
+		MediaServerType:         getEnv("MEDIA_SERVER_TYPE", "jellyfin"),
+		MediaServerURL:          getEnv("MEDIA_SERVER_URL", ""),
+		MediaServerAPIKey:       getEnv("MEDIA_SERVER_API_KEY", ""),
+		MediaServerScan:         getEnvAsBool("MEDIA_SERVER_SCAN", false),
+		MediaServerConfirmHours: getEnvAsInt("MEDIA_SERVER_CONFIRM_HOURS", 24),
+
+		// 下载器配置
+		DownloaderType:       getEnv("DOWNLOADER_TYPE", "qbittorrent"),
+		DownloaderURL:        getEnv("DOWNLOADER_URL", ""),
+		DownloaderUsername:   getEnv("DOWNLOADER_USERNAME", ""),
+		DownloaderPassword:   getEnv("DOWNLOADER_PASSWORD", ""),
+		DownloaderStallHours: getEnvAsInt("DOWNLOADER_STALL_HOURS", 6),
+		DownloaderReplace:    getEnvAsBool("DOWNLOADER_REPLACE", false),
 
 		// 存储配置
 		StoreType: getEnv("STORE_TYPE", "sqlite"),
@@ -99,11 +189,58 @@ func Load() (*Config, error) {
 		TelegramEnabled: getEnvAsBool("TELEGRAM_ENABLED", false),
 		TelegramToken:   getEnv("TELEGRAM_BOT_TOKEN", ""),
 		TelegramChatIDs: getEnvAsSlice("TELEGRAM_CHAT_IDS", ",", []string{}),
+		TelegramEvents:  getEnvAsSlice("TELEGRAM_EVENTS", ",", nil),
+
+		// Webhook 通知配置
+		WebhookURLs:       getEnvAsSlice("WEBHOOK_URLS", ",", nil),
+		WebhookSecret:     getEnv("WEBHOOK_SECRET", ""),
+		WebhookEvents:     getEnvAsSlice("WEBHOOK_EVENTS", ",", nil),
+		WebhookMaxRetries: getEnvAsInt("WEBHOOK_MAX_RETRIES", 3),
+
+		// 邮件通知配置
+		EmailSMTPHost:     getEnv("EMAIL_SMTP_HOST", ""),
+		EmailSMTPPort:     getEnvAsInt("EMAIL_SMTP_PORT", 587),
+		EmailUsername:     getEnv("EMAIL_USERNAME", ""),
+		EmailPassword:     getEnv("EMAIL_PASSWORD", ""),
+		EmailFrom:         getEnv("EMAIL_FROM", ""),
+		EmailTo:           getEnvAsSlice("EMAIL_TO", ",", nil),
+		EmailMode:         getEnv("EMAIL_MODE", "immediate"),
+		EmailDigestTime:   getEnv("EMAIL_DIGEST_TIME", "20:00"),
+		EmailPerRequester: getEnvAsBool("EMAIL_PER_REQUESTER", false),
+		EmailEvents:       getEnvAsSlice("EMAIL_EVENTS", ",", nil),
+
+		// 企业微信应用消息配置
+		WeComCorpID:  getEnv("WECOM_CORP_ID", ""),
+		WeComAgentID: getEnvAsInt("WECOM_AGENT_ID", 0),
+		WeComSecret:  getEnv("WECOM_SECRET", ""),
+		WeComToUser:  getEnv("WECOM_TO_USER", "@all"),
+		WeComBaseURL: getEnv("WECOM_BASE_URL", "https://qyapi.weixin.qq.com"),
+		WeComEvents:  getEnvAsSlice("WECOM_EVENTS", ",", nil),
+
+		// Bark 推送配置
+		BarkDeviceKeys: getEnvAsSlice("BARK_DEVICE_KEYS", ",", nil),
+		BarkServerURL:  getEnv("BARK_SERVER_URL", "https://api.day.app"),
+		BarkGroup:      getEnv("BARK_GROUP", "Jellyseerr"),
+		BarkIcon:       getEnv("BARK_ICON", ""),
+		BarkEvents:     getEnvAsSlice("BARK_EVENTS", ",", nil),
+
+		// Discord Webhook 配置
+		DiscordWebhookURL: getEnv("DISCORD_WEBHOOK_URL", ""),
+		DiscordUsername:   getEnv("DISCORD_USERNAME", ""),
+		DiscordAvatarURL:  getEnv("DISCORD_AVATAR_URL", ""),
+		DiscordEvents:     getEnvAsSlice("DISCORD_EVENTS", ",", nil),
 
 		// Tracker 配置
-		TrackerEnabled:       getEnvAsBool("TRACKER_ENABLED", true),
-		TrackerCheckInterval: getEnvAsInt("TRACKER_CHECK_INTERVAL", 5),
-		TrackerSSEEnabled:    getEnvAsBool("TRACKER_SSE_ENABLED", true),
+		TrackerEnabled:           getEnvAsBool("TRACKER_ENABLED", true),
+		TrackerCheckInterval:     getEnvAsInt("TRACKER_CHECK_INTERVAL", 5),
+		TrackerSSEEnabled:        getEnvAsBool("TRACKER_SSE_ENABLED", true),
+		TrackerReconcileInterval: getEnvAsInt("TRACKER_RECONCILE_INTERVAL", 60),
+		TrackerDriftAction:       getEnv("TRACKER_DRIFT_ACTION", "orphan"),
+		TrackerProgressStep:      getEnvAsInt("TRACKER_PROGRESS_STEP", 50),
+		TrackerEscalateDays:      getEnvAsInt("TRACKER_ESCALATE_DAYS", 14),
+		TrackerEpisodeLateHours:  getEnvAsInt("TRACKER_EPISODE_LATE_HOURS", 24),
+		TrackerWebhookAddr:       getEnv("TRACKER_WEBHOOK_ADDR", ""),
+		TrackerWebhookToken:      getEnv("TRACKER_WEBHOOK_TOKEN", ""),
 
 		// SmartRetry 配置
 		SmartRetryEnabled:       getEnvAsBool("SMART_RETRY_ENABLED", true),
@@ -146,6 +283,7 @@ func (c *Config) Validate() error {
 	// 规范化 URL（确保以 / 结尾）
 	c.JellyURL = strings.TrimRight(c.JellyURL, "/")
 	c.MPURL = strings.TrimRight(c.MPURL, "/")
+	c.MediaServerURL = strings.TrimRight(c.MediaServerURL, "/")
 
 	// 验证认证方案
 	validAuthSchemes := []string{"bearer", "x-api-token", "query-token"}
@@ -159,6 +297,78 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("MP_TV_EPISODE_MODE must be one of: %v", validEpisodeModes)
 	}
 
+	// 验证入库后的订阅处理策略
+	validCompletedPolicies := []string{"keep", "delete", "best_version"}
+	if c.MPCompletedPolicy != "" && !contains(validCompletedPolicies, c.MPCompletedPolicy) {
+		return fmt.Errorf("MP_COMPLETED_POLICY must be one of: %v", validCompletedPolicies)
+	}
+
+	// 验证订阅丢失处理方式
+	validDriftActions := []string{"orphan", "recreate"}
+	if c.TrackerDriftAction != "" && !contains(validDriftActions, c.TrackerDriftAction) {
+		return fmt.Errorf("TRACKER_DRIFT_ACTION must be one of: %v", validDriftActions)
+	}
+
+	// 验证媒体服务器配置
+	if c.MediaServerURL != "" {
+		validServerTypes := []string{"jellyfin", "emby"}
+		if !contains(validServerTypes, c.MediaServerType) {
+			return fmt.Errorf("MEDIA_SERVER_TYPE must be one of: %v", validServerTypes)
+		}
+		if c.MediaServerAPIKey == "" {
+			return fmt.Errorf("MEDIA_SERVER_API_KEY is required when MEDIA_SERVER_URL is set")
+		}
+	}
+
+	// 验证 Webhook 配置
+	if c.WebhookMaxRetries < 0 {
+		return fmt.Errorf("WEBHOOK_MAX_RETRIES must not be negative")
+	}
+
+	// 验证邮件配置
+	if c.EmailSMTPHost != "" {
+		if c.EmailFrom == "" {
+			return fmt.Errorf("EMAIL_FROM is required when EMAIL_SMTP_HOST is set")
+		}
+		if len(c.EmailTo) == 0 && !c.EmailPerRequester {
+			return fmt.Errorf("EMAIL_TO is required unless EMAIL_PER_REQUESTER is enabled")
+		}
+		validEmailModes := []string{"immediate", "digest"}
+		if !contains(validEmailModes, c.EmailMode) {
+			return fmt.Errorf("EMAIL_MODE must be one of: %v", validEmailModes)
+		}
+		if c.EmailMode == "digest" {
+			if _, err := time.Parse("15:04", c.EmailDigestTime); err != nil {
+				return fmt.Errorf("EMAIL_DIGEST_TIME must be in HH:MM format")
+			}
+		}
+	}
+
+	// 验证企业微信配置
+	if c.WeComCorpID != "" && (c.WeComSecret == "" || c.WeComAgentID == 0) {
+		return fmt.Errorf("WECOM_SECRET and WECOM_AGENT_ID are required when WECOM_CORP_ID is set")
+	}
+
+	// 验证下载器配置
+	if c.DownloaderURL != "" {
+		validDownloaders := []string{"qbittorrent", "transmission"}
+		if !contains(validDownloaders, c.DownloaderType) {
+			return fmt.Errorf("DOWNLOADER_TYPE must be one of: %v", validDownloaders)
+		}
+	}
+
+	// Webhook 接收服务必须配置令牌
+	if c.TrackerWebhookAddr != "" && c.TrackerWebhookToken == "" {
+		return fmt.Errorf("TRACKER_WEBHOOK_TOKEN is required when TRACKER_WEBHOOK_ADDR is set")
+	}
+
+	// 验证每日报告时间
+	if c.ReportEnabled {
+		if _, err := time.Parse("15:04", c.ReportTime); err != nil {
+			return fmt.Errorf("REPORT_TIME must be in HH:MM format: %w", err)
+		}
+	}
+
 	// 验证存储类型
 	validStoreTypes := []string{"sqlite", "json"}
 	if !contains(validStoreTypes, c.StoreType) {
@@ -69,6 +69,38 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "webhook without token",
+			cfg: &Config{
+				JellyURL:           "https://test.com",
+				JellyAPIKey:        "key",
+				MPURL:              "http://test.com",
+				MPUsername:         "user",
+				MPPassword:         "pass",
+				MPAuthScheme:       "bearer",
+				MPTVEpisodeMode:    "season",
+				StoreType:          "sqlite",
+				TrackerWebhookAddr: ":8090",
+			},
+			wantErr: true,
+		},
+		{
+			name: "email without recipients",
+			cfg: &Config{
+				JellyURL:        "https://test.com",
+				JellyAPIKey:     "key",
+				MPURL:           "http://test.com",
+				MPUsername:      "user",
+				MPPassword:      "pass",
+				MPAuthScheme:    "bearer",
+				MPTVEpisodeMode: "season",
+				StoreType:       "sqlite",
+				EmailSMTPHost:   "smtp.example.com",
+				EmailFrom:       "syncer@example.com",
+				EmailMode:       "immediate",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
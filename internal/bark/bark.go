@@ -0,0 +1,125 @@
+package bark
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/notify"
+	"go.uber.org/zap"
+)
+
+// DefaultServerURL Bark 官方服务器
+const DefaultServerURL = "https://api.day.app"
+
+// Config Bark 推送配置
+type Config struct {
+	ServerURL  string
+	DeviceKeys []string
+	Group      string // 通知分组
+	Icon       string // 通知图标地址
+}
+
+// Client Bark 推送通知，每个设备单独推送
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+	logger     *zap.Logger
+}
+
+// NewClient 创建 Bark 推送通知
+func NewClient(cfg Config, logger *zap.Logger) *Client {
+	cfg.ServerURL = strings.TrimRight(cfg.ServerURL, "/")
+	if cfg.ServerURL == "" {
+		cfg.ServerURL = DefaultServerURL
+	}
+	return &Client{
+		cfg: cfg,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		logger: logger,
+	}
+}
+
+// Name 实现 notify.Notifier
+func (c *Client) Name() string {
+	return "bark"
+}
+
+// Notify 实现 notify.Notifier，异步推送到所有设备
+func (c *Client) Notify(n *notify.Notification) {
+	go func() {
+		for _, key := range c.cfg.DeviceKeys {
+			if err := c.push(context.Background(), key, n); err != nil {
+				c.logger.Error("Failed to send bark push",
+					zap.String("event", string(n.Event)),
+					zap.String("title", n.Title),
+					zap.Error(err),
+				)
+			}
+		}
+	}()
+}
+
+// pushRequest /push 接口的请求体
+type pushRequest struct {
+	DeviceKey string `json:"device_key"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	Group     string `json:"group,omitempty"`
+	Icon      string `json:"icon,omitempty"`
+	URL       string `json:"url,omitempty"` // 点击通知后打开的地址
+}
+
+// pushResponse /push 接口的响应
+type pushResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// push 推送到单个设备
+func (c *Client) push(ctx context.Context, deviceKey string, n *notify.Notification) error {
+	rendered := notify.Render(n)
+	data, err := json.Marshal(pushRequest{
+		DeviceKey: deviceKey,
+		Title:     rendered.Title(),
+		Body:      rendered.Body(),
+		Group:     c.cfg.Group,
+		Icon:      c.cfg.Icon,
+		URL:       notify.TMDBURL(n),
+	})
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.cfg.ServerURL+"/push", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	var result pushResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("unexpected response (status %d): %s", resp.StatusCode, string(body))
+	}
+	if resp.StatusCode != http.StatusOK || result.Code != http.StatusOK {
+		return fmt.Errorf("push failed: code %d: %s", result.Code, result.Message)
+	}
+	return nil
+}
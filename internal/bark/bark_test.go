@@ -0,0 +1,54 @@
+package bark
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/notify"
+	"go.uber.org/zap"
+)
+
+func TestPush(t *testing.T) {
+	var got pushRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/push" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		if got.DeviceKey == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(pushResponse{Code: 400, Message: "failed to get device token"})
+			return
+		}
+		json.NewEncoder(w).Encode(pushResponse{Code: 200, Message: "success"})
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{ServerURL: server.URL + "/", Group: "Jellyseerr", Icon: "https://example.com/icon.png"}, zap.NewNop())
+	n := &notify.Notification{
+		Event:           notify.EventEpisodesTransferred,
+		Title:           "三体",
+		MediaType:       "tv",
+		TMDBID:          204541,
+		Episodes:        "S01E05",
+		EpisodeProgress: "5/30",
+	}
+	if err := client.push(context.Background(), "key", n); err != nil {
+		t.Fatalf("push() error = %v", err)
+	}
+
+	if got.DeviceKey != "key" || got.Title != "🆕 S01E05 已入库" || got.Body != "📺 三体\n📊 进度: 5/30" {
+		t.Errorf("unexpected push %+v", got)
+	}
+	if got.Group != "Jellyseerr" || got.Icon != "https://example.com/icon.png" || got.URL != "https://www.themoviedb.org/tv/204541" {
+		t.Errorf("unexpected push options %+v", got)
+	}
+
+	if err := client.push(context.Background(), "bad", n); err == nil {
+		t.Error("expected push error")
+	}
+}
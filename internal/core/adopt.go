@@ -0,0 +1,218 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/tracker"
+	"go.uber.org/zap"
+)
+
+// AdoptResult 接管 MP 已有订阅的结果
+type AdoptResult struct {
+	Subscriptions int // MP 中的订阅数
+	Pending       int // 待同步的本地请求数
+	Untracked     int // 已同步但没有跟踪记录的请求数
+	Adopted       int // 成功接管的请求数
+}
+
+// ImportMPSubscriptions 导入 MP 中已有的订阅
+// 先从 Jellyseerr 拉取请求写入本地，再按 TMDB ID 匹配 MP 订阅并接管
+func (s *Syncer) ImportMPSubscriptions(ctx context.Context) (*AdoptResult, error) {
+	s.logger.Info("importing existing MoviePilot subscriptions")
+
+	requests, err := s.jellyClient.FetchAllApprovedRequests(ctx, s.cfg.JellyPageSize)
+	if err != nil {
+		return nil, fmt.Errorf("fetch approved requests: %w", err)
+	}
+
+	for _, req := range requests {
+		if err := s.processRequest(ctx, req); err != nil {
+			s.logger.Error("process request failed",
+				zap.Int("request_id", req.ID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	result, err := s.adoptExistingSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("import completed",
+		zap.Int("subscriptions", result.Subscriptions),
+		zap.Int("pending", result.Pending),
+		zap.Int("untracked", result.Untracked),
+		zap.Int("adopted", result.Adopted),
+	)
+
+	return result, nil
+}
+
+// adoptExistingSubscriptions 将待同步请求、以及已同步但没有跟踪记录的请求与 MP 已有订阅匹配
+// 匹配成功的请求直接建立 MPLink 和跟踪记录，不再重复创建订阅
+func (s *Syncer) adoptExistingSubscriptions(ctx context.Context) (*AdoptResult, error) {
+	result := &AdoptResult{}
+
+	pending, err := s.store.ListPendingRequests(0)
+	if err != nil {
+		return nil, fmt.Errorf("list pending requests: %w", err)
+	}
+	untracked, err := s.store.ListUntrackedRequests(0)
+	if err != nil {
+		return nil, fmt.Errorf("list untracked requests: %w", err)
+	}
+	result.Pending = len(pending)
+	result.Untracked = len(untracked)
+	candidates := append(pending, untracked...)
+	if len(candidates) == 0 {
+		return result, nil
+	}
+
+	subs, err := s.mpClient.ListSubscribes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list mp subscriptions: %w", err)
+	}
+	result.Subscriptions = len(subs)
+
+	// 按 TMDB ID 建立索引
+	movies := make(map[int]*mp.Subscription)
+	shows := make(map[int]map[int]*mp.Subscription)
+	for i := range subs {
+		sub := &subs[i]
+		if sub.TMDBID == 0 {
+			continue
+		}
+		if sub.IsTV() {
+			if shows[sub.TMDBID] == nil {
+				shows[sub.TMDBID] = make(map[int]*mp.Subscription)
+			}
+			shows[sub.TMDBID][sub.Season] = sub
+		} else {
+			movies[sub.TMDBID] = sub
+		}
+	}
+
+	for _, req := range candidates {
+		var matched []*mp.Subscription
+		switch req.MediaType {
+		case store.MediaTypeMovie:
+			if sub, ok := movies[req.TMDBID]; ok {
+				matched = append(matched, sub)
+			}
+		case store.MediaTypeTV:
+			matched = matchSeasonSubscriptions(req, shows[req.TMDBID])
+		}
+		if len(matched) == 0 {
+			continue
+		}
+
+		if err := s.adoptSubscription(req, matched); err != nil {
+			s.logger.Error("adopt subscription failed",
+				zap.String("source_request_id", req.SourceRequestID),
+				zap.String("title", req.Title),
+				zap.Error(err),
+			)
+			continue
+		}
+		result.Adopted++
+	}
+
+	return result, nil
+}
+
+// matchSeasonSubscriptions 匹配剧集请求的各季订阅
+// 只有请求的每一季都已在 MP 中订阅时才接管，否则交给正常订阅流程补齐
+func matchSeasonSubscriptions(req *store.Request, bySeason map[int]*mp.Subscription) []*mp.Subscription {
+	if len(bySeason) == 0 {
+		return nil
+	}
+
+	seasons, err := req.GetSeasons()
+	if err != nil || len(seasons) == 0 {
+		// 未指定季时，任意一季的订阅都视为匹配
+		for _, sub := range bySeason {
+			return []*mp.Subscription{sub}
+		}
+		return nil
+	}
+
+	matched := make([]*mp.Subscription, 0, len(seasons))
+	for _, season := range seasons {
+		sub, ok := bySeason[season]
+		if !ok {
+			return nil
+		}
+		matched = append(matched, sub)
+	}
+	return matched
+}
+
+// adoptSubscription 为请求建立 MPLink、跟踪记录和事件
+func (s *Syncer) adoptSubscription(req *store.Request, subs []*mp.Subscription) error {
+	first := subs[0]
+
+	// 已同步的请求保留原有的订阅 ID
+	link, err := s.store.GetMPLink(req.SourceRequestID)
+	if err != nil {
+		return fmt.Errorf("get mp link: %w", err)
+	}
+	if link == nil || link.State != store.StatusSynced || link.MPSubscribeID == "" {
+		link = &store.MPLink{
+			SourceRequestID: req.SourceRequestID,
+			MPSubscribeID:   strconv.Itoa(first.ID),
+			State:           store.StatusSynced,
+		}
+		if err := s.store.SaveMPLink(link); err != nil {
+			return fmt.Errorf("save mp link: %w", err)
+		}
+	}
+
+	subscribeTime := time.Now()
+	if createdAt, ok := first.CreatedAt(); ok {
+		subscribeTime = createdAt
+	}
+
+	ids := make([]int, 0, len(subs))
+	for _, sub := range subs {
+		ids = append(ids, sub.ID)
+	}
+
+	// 已有跟踪记录时以存储中的状态为准
+	tracking := &store.SubscriptionTracking{
+		SourceRequestID: req.SourceRequestID,
+		TMDBID:          req.TMDBID,
+		Title:           req.Title,
+		MediaType:       req.MediaType,
+		SubscribeStatus: store.TrackingPending,
+	}
+	err = s.tracker.Apply(tracking, tracker.Change{
+		To: store.TrackingSubscribed,
+		Payload: &store.AdoptedPayload{
+			SubscribeIDs:  ids,
+			SubscribeDate: first.Date,
+		},
+		At: subscribeTime,
+	})
+	if err != nil {
+		return fmt.Errorf("track adopted subscription: %w", err)
+	}
+
+	if err := s.store.UpdateRequestStatus(req.SourceRequestID, store.StatusSynced); err != nil {
+		return fmt.Errorf("update request status: %w", err)
+	}
+
+	s.logger.Info("adopted existing MoviePilot subscription",
+		zap.String("source_request_id", req.SourceRequestID),
+		zap.String("title", req.Title),
+		zap.Int("tmdb_id", req.TMDBID),
+		zap.Ints("subscribe_ids", ids),
+	)
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/configs"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/bark"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/discord"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/email"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/notify"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/telegram"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/webhook"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/wecom"
+	"go.uber.org/zap"
+)
+
+// newNotifier 按配置创建通知渠道，并按各渠道的事件过滤汇总到分发器
+// 需要后台运行的渠道（如邮件摘要）随 ctx 退出；返回的 Webhook 发送器和邮件通知用于退出前等待发送完成，未启用时为 nil
+func newNotifier(ctx context.Context, cfg *configs.Config, st store.Store, logger *zap.Logger) (*notify.Mux, *webhook.Sender, *email.Notifier, error) {
+	mux := notify.NewMux(logger)
+	var webhooks *webhook.Sender
+	var mailer *email.Notifier
+
+	// 创建 Telegram Bot（如果启用）
+	if cfg.TelegramEnabled {
+		events, err := notify.ParseEvents(cfg.TelegramEvents)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("parse TELEGRAM_EVENTS: %w", err)
+		}
+		logger.Info("Telegram bot enabled, initializing...",
+			zap.Int("chat_count", len(cfg.TelegramChatIDs)),
+		)
+		tgBot, err := telegram.NewBot(cfg.TelegramToken, cfg.TelegramChatIDs, logger)
+		if err != nil {
+			// 即使失败也继续，只是没有 Telegram 通知
+			logger.Error("Failed to create telegram bot", zap.Error(err))
+		} else if tgBot.IsEnabled() {
+			mux.Add(tgBot, events)
+		}
+	} else {
+		logger.Info("Telegram bot disabled in config")
+	}
+
+	// 创建 Webhook 发送器（如果配置）
+	if len(cfg.WebhookURLs) > 0 {
+		events, err := notify.ParseEvents(cfg.WebhookEvents)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("parse WEBHOOK_EVENTS: %w", err)
+		}
+		webhooks = newWebhookSender(cfg, st, logger)
+		mux.Add(webhooks, events)
+	}
+
+	// 创建邮件通知（如果配置）
+	if cfg.EmailSMTPHost != "" {
+		events, err := notify.ParseEvents(cfg.EmailEvents)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("parse EMAIL_EVENTS: %w", err)
+		}
+		mailer = email.NewNotifier(email.Config{
+			Host:         cfg.EmailSMTPHost,
+			Port:         cfg.EmailSMTPPort,
+			Username:     cfg.EmailUsername,
+			Password:     cfg.EmailPassword,
+			From:         cfg.EmailFrom,
+			To:           cfg.EmailTo,
+			Mode:         cfg.EmailMode,
+			DigestTime:   cfg.EmailDigestTime,
+			PerRequester: cfg.EmailPerRequester,
+		}, st, logger)
+		mailer.Start(ctx)
+		mux.Add(mailer, events)
+	}
+
+	// 创建企业微信应用消息通知（如果配置）
+	if cfg.WeComCorpID != "" {
+		events, err := notify.ParseEvents(cfg.WeComEvents)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("parse WECOM_EVENTS: %w", err)
+		}
+		mux.Add(wecom.NewApp(wecom.Config{
+			BaseURL: cfg.WeComBaseURL,
+			CorpID:  cfg.WeComCorpID,
+			AgentID: cfg.WeComAgentID,
+			Secret:  cfg.WeComSecret,
+			ToUser:  cfg.WeComToUser,
+		}, logger), events)
+	}
+
+	// 创建 Bark 推送通知（如果配置）
+	if len(cfg.BarkDeviceKeys) > 0 {
+		events, err := notify.ParseEvents(cfg.BarkEvents)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("parse BARK_EVENTS: %w", err)
+		}
+		mux.Add(bark.NewClient(bark.Config{
+			ServerURL:  cfg.BarkServerURL,
+			DeviceKeys: cfg.BarkDeviceKeys,
+			Group:      cfg.BarkGroup,
+			Icon:       cfg.BarkIcon,
+		}, logger), events)
+	}
+
+	// 创建 Discord 通知（如果配置）
+	if cfg.DiscordWebhookURL != "" {
+		events, err := notify.ParseEvents(cfg.DiscordEvents)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("parse DISCORD_EVENTS: %w", err)
+		}
+		mux.Add(discord.NewWebhook(discord.Config{
+			WebhookURL:   cfg.DiscordWebhookURL,
+			MediaPageURL: cfg.JellyURL,
+			Username:     cfg.DiscordUsername,
+			AvatarURL:    cfg.DiscordAvatarURL,
+		}, st, logger), events)
+	}
+
+	if mux.Len() == 0 {
+		logger.Info("No notification channel enabled")
+	}
+	return mux, webhooks, mailer, nil
+}
+
+// newWebhookSender 按配置创建 Webhook 发送器
+func newWebhookSender(cfg *configs.Config, st store.Store, logger *zap.Logger) *webhook.Sender {
+	return webhook.NewSender(cfg.WebhookURLs, cfg.WebhookSecret, cfg.WebhookMaxRetries, st, logger)
+}
+
+// ReplayWebhooks 重新投递所有失败的 Webhook
+func (s *Syncer) ReplayWebhooks(ctx context.Context) error {
+	delivered, err := newWebhookSender(s.cfg, s.store, s.logger).ReplayFailed(ctx)
+	if err != nil {
+		return err
+	}
+	s.logger.Info("replayed failed webhook deliveries", zap.Int("delivered", delivered))
+	return nil
+}
@@ -2,17 +2,20 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/yourusername/jellyseerr-moviepilot-syncer/configs"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/email"
 	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/jelly"
 	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/notify"
 	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
-	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/telegram"
 	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/tmdb"
 	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/tracker"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/webhook"
 	"go.uber.org/zap"
 )
 
@@ -23,8 +26,11 @@ type Syncer struct {
 	mpClient    *mp.Client
 	tmdbClient  *tmdb.Client
 	store       store.Store
-	telegram    *telegram.Bot
-	tracker     *tracker.Tracker
+	notifier    notify.Notifier
+	webhooks    *webhook.Sender // 未启用 Webhook 时为 nil
+	mailer      *email.Notifier // 未启用邮件时为 nil
+	stopNotify  context.CancelFunc
+	tracker     *tracker.Tracker // 未启用时不启动后台任务，仍负责跟踪状态转换和通知
 	logger      *zap.Logger
 }
 
@@ -43,14 +49,15 @@ func NewSyncer(cfg *configs.Config, logger *zap.Logger, ctx context.Context) (*S
 
 	// 创建 MoviePilot 客户端
 	mpClient, err := mp.NewClient(mp.ClientConfig{
-		BaseURL:      cfg.MPURL,
-		Username:     cfg.MPUsername,
-		Password:     cfg.MPPassword,
-		AuthScheme:   cfg.MPAuthScheme,
-		RateLimitPS:  cfg.MPRateLimitPS,
-		MaxRetries:   cfg.MaxRetries,
-		DryRun:       cfg.MPDryRun,
-		TokenRefresh: cfg.MPTokenRefresh,
+		BaseURL:        cfg.MPURL,
+		Username:       cfg.MPUsername,
+		Password:       cfg.MPPassword,
+		AuthScheme:     cfg.MPAuthScheme,
+		RateLimitPS:    cfg.MPRateLimitPS,
+		MaxRetries:     cfg.MaxRetries,
+		DryRun:         cfg.MPDryRun,
+		TokenRefresh:   cfg.MPTokenRefresh,
+		ExistsKeywords: cfg.MPExistsKeywords,
 	}, ctx)
 	if err != nil {
 		return nil, fmt.Errorf("create mp client: %w", err)
@@ -67,29 +74,21 @@ func NewSyncer(cfg *configs.Config, logger *zap.Logger, ctx context.Context) (*S
 		return nil, fmt.Errorf("unsupported store type: %s", cfg.StoreType)
 	}
 
-	// 创建 Telegram Bot（如果启用）
-	var tgBot *telegram.Bot
-	if cfg.TelegramEnabled {
-		logger.Info("Telegram bot enabled, initializing...",
-			zap.Int("chat_count", len(cfg.TelegramChatIDs)),
-		)
-		tgBot, err = telegram.NewBot(cfg.TelegramToken, cfg.TelegramChatIDs, logger)
-		if err != nil {
-			logger.Error("Failed to create telegram bot", zap.Error(err))
-			// 即使失败也继续，只是没有通知功能
-		}
-	} else {
-		logger.Info("Telegram bot disabled in config")
+	// 创建通知渠道，后台任务在 Close 时停止
+	notifyCtx, stopNotify := context.WithCancel(ctx)
+	notifier, webhooks, mailer, err := newNotifier(notifyCtx, cfg, st, logger)
+	if err != nil {
+		stopNotify()
+		return nil, fmt.Errorf("create notifier: %w", err)
 	}
 
-	// 创建 Tracker（如果启用）
-	var trk *tracker.Tracker
+	// 创建 Tracker，未启用时只用于订阅后的状态转换和通知
 	if cfg.TrackerEnabled {
 		logger.Info("Tracker enabled, initializing...")
-		trk = tracker.NewTracker(cfg, mpClient, st, tgBot, logger)
 	} else {
 		logger.Info("Tracker disabled in config")
 	}
+	trk := tracker.NewTracker(cfg, mpClient, st, notifier, logger)
 
 	return &Syncer{
 		cfg:         cfg,
@@ -97,7 +96,10 @@ func NewSyncer(cfg *configs.Config, logger *zap.Logger, ctx context.Context) (*S
 		mpClient:    mpClient,
 		tmdbClient:  tmdbClient,
 		store:       st,
-		telegram:    tgBot,
+		notifier:    notifier,
+		webhooks:    webhooks,
+		mailer:      mailer,
+		stopNotify:  stopNotify,
 		tracker:     trk,
 		logger:      logger,
 	}, nil
@@ -126,12 +128,21 @@ func (s *Syncer) SyncOnce(ctx context.Context) error {
 		}
 	}
 
-	// 3. 处理待同步的请求
+	// 3. 接管 MP 中已有的订阅，避免重复订阅
+	if s.cfg.MPAdoptExisting {
+		if result, err := s.adoptExistingSubscriptions(ctx); err != nil {
+			s.logger.Warn("adopt existing subscriptions failed", zap.Error(err))
+		} else if result.Adopted > 0 {
+			s.logger.Info("adopted existing subscriptions", zap.Int("adopted", result.Adopted))
+		}
+	}
+
+	// 4. 处理待同步的请求
 	if err := s.processPendingRequests(ctx); err != nil {
 		return fmt.Errorf("process pending requests: %w", err)
 	}
 
-	// 4. 打印统计信息
+	// 5. 打印统计信息
 	stats, err := s.store.GetStats()
 	if err != nil {
 		s.logger.Warn("get stats failed", zap.Error(err))
@@ -218,6 +229,16 @@ func (s *Syncer) processRequest(ctx context.Context, jellyReq *jelly.MediaReques
 		PosterPath:      posterPath,
 		Status:          store.StatusPending,
 		RequestedAt:     jellyReq.CreatedAt,
+		RequestedBy:     jellyReq.RequestedBy.DisplayName,
+		RequesterEmail:  jellyReq.RequestedBy.Email,
+	}
+	if localReq.RequestedBy == "" {
+		localReq.RequestedBy = jellyReq.RequestedBy.Username
+	}
+	// 只同步已批准的请求，更新时间即批准时间
+	if !jellyReq.UpdatedAt.IsZero() {
+		approvedAt := jellyReq.UpdatedAt
+		localReq.ApprovedAt = &approvedAt
 	}
 
 	// 处理剧集季和集
@@ -349,6 +370,7 @@ func (s *Syncer) subscribeMovie(ctx context.Context, req *store.Request) error {
 		TMDBID: req.TMDBID,
 	}
 
+	sentAt := time.Now()
 	resp, err := s.mpClient.Subscribe(ctx, mpReq)
 	if err != nil {
 		return fmt.Errorf("subscribe movie: %w", err)
@@ -362,24 +384,15 @@ func (s *Syncer) subscribeMovie(ctx context.Context, req *store.Request) error {
 		zap.Int("code", resp.Code),
 	)
 
-	// 检查是否为"已存在"响应
-	alreadyExists := resp.IsAlreadyExists()
-	if alreadyExists {
-		s.logger.Info("movie already exists in library",
-			zap.String("title", req.Title),
-			zap.Int("tmdb_id", req.TMDBID),
-			zap.String("message", resp.Message),
-		)
-	}
+	// 根据 MP 结构化数据判断订阅结果
+	result := s.mpClient.ClassifySubscribe(ctx, resp, sentAt)
+	alreadyExists := result.Outcome == mp.OutcomeExists
+	s.logSubscribeOutcome(req, 0, resp, result)
 
 	// 保存链接
 	subscribeID := ""
-	if resp.Data != nil {
-		if resp.Data.ID > 0 {
-			subscribeID = strconv.Itoa(resp.Data.ID)
-		} else if resp.Data.SubscribeID > 0 {
-			subscribeID = strconv.Itoa(resp.Data.SubscribeID)
-		}
+	if result.SubscribeID > 0 {
+		subscribeID = strconv.Itoa(result.SubscribeID)
 	}
 
 	link := &store.MPLink{
@@ -400,52 +413,8 @@ func (s *Syncer) subscribeMovie(ctx context.Context, req *store.Request) error {
 		)
 	}
 
-	// 保存到跟踪表
-	now := time.Now()
-	var trackingStatus store.TrackingStatus
-	if alreadyExists {
-		// 已存在的影片直接标记为已入库
-		trackingStatus = store.TrackingTransferred
-	} else {
-		trackingStatus = store.TrackingSubscribed
-	}
-
-	tracking := &store.SubscriptionTracking{
-		SourceRequestID: req.SourceRequestID,
-		TMDBID:          req.TMDBID,
-		Title:           req.Title,
-		MediaType:       req.MediaType,
-		SubscribeStatus: trackingStatus,
-		SubscribeTime:   &now,
-	}
-
-	// 如果已存在，也设置 TransferTime
-	if alreadyExists {
-		tracking.TransferTime = &now
-	}
-
-	if err := s.store.SaveTracking(tracking); err != nil {
-		s.logger.Warn("Failed to save tracking", zap.Error(err))
-	}
-
-	// 发送 Telegram 通知
-	if s.telegram != nil && s.telegram.IsEnabled() {
-		if alreadyExists {
-			// 发送"已在媒体库"通知
-			s.logger.Debug("Sending 'already exists' notification", zap.String("title", req.Title))
-			s.telegram.NotifyAlreadyExists(req.Title, string(req.MediaType), req.TMDBID, req.PosterPath)
-		} else {
-			// 发送普通订阅通知
-			s.logger.Debug("Sending telegram notification for movie", zap.String("title", req.Title))
-			s.telegram.NotifySubscribed(req.Title, string(req.MediaType), req.TMDBID, req.PosterPath)
-		}
-	} else {
-		if s.telegram == nil {
-			s.logger.Debug("Telegram bot not initialized, skipping notification")
-		} else {
-			s.logger.Debug("Telegram bot disabled, skipping notification")
-		}
-	}
+	// 保存到跟踪表，转换成功后由 Tracker 发送通知
+	s.trackSubscription(req, alreadyExists)
 
 	return nil
 }
@@ -476,19 +445,17 @@ func (s *Syncer) subscribeTV(ctx context.Context, req *store.Request) error {
 				Season: season,
 			}
 
+			sentAt := time.Now()
 			resp, err := s.mpClient.Subscribe(ctx, mpReq)
 			if err != nil {
 				return fmt.Errorf("subscribe season %d: %w", season, err)
 			}
 
-			// 检查是否已存在（检查第一季）
-			if season == seasons[0] && resp.IsAlreadyExists() {
+			// 根据 MP 结构化数据判断订阅结果（以第一季为准）
+			result := s.mpClient.ClassifySubscribe(ctx, resp, sentAt)
+			s.logSubscribeOutcome(req, season, resp, result)
+			if season == seasons[0] && result.Outcome == mp.OutcomeExists {
 				alreadyExists = true
-				s.logger.Info("TV show already exists in library",
-					zap.String("title", req.Title),
-					zap.Int("tmdb_id", req.TMDBID),
-					zap.String("message", resp.Message),
-				)
 			}
 
 			if !alreadyExists {
@@ -499,15 +466,11 @@ func (s *Syncer) subscribeTV(ctx context.Context, req *store.Request) error {
 				)
 			}
 
-			// 保存链接（仅保存第一个）
+			// 保存链接（仅保存第一个，其余季的订阅由清理任务按季号查询）
 			if season == seasons[0] {
 				subscribeID := ""
-				if resp.Data != nil {
-					if resp.Data.ID > 0 {
-						subscribeID = strconv.Itoa(resp.Data.ID)
-					} else if resp.Data.SubscribeID > 0 {
-						subscribeID = strconv.Itoa(resp.Data.SubscribeID)
-					}
+				if result.SubscribeID > 0 {
+					subscribeID = strconv.Itoa(result.SubscribeID)
 				}
 
 				link := &store.MPLink{
@@ -533,19 +496,17 @@ func (s *Syncer) subscribeTV(ctx context.Context, req *store.Request) error {
 					Episodes: eps,
 				}
 
+				sentAt := time.Now()
 				resp, err := s.mpClient.Subscribe(ctx, mpReq)
 				if err != nil {
 					return fmt.Errorf("subscribe season %d episodes: %w", season, err)
 				}
 
-				// 检查是否已存在（检查第一季）
-				if season == seasons[0] && resp.IsAlreadyExists() {
+				// 根据 MP 结构化数据判断订阅结果（以第一季为准）
+				result := s.mpClient.ClassifySubscribe(ctx, resp, sentAt)
+				s.logSubscribeOutcome(req, season, resp, result)
+				if season == seasons[0] && result.Outcome == mp.OutcomeExists {
 					alreadyExists = true
-					s.logger.Info("TV show already exists in library",
-						zap.String("title", req.Title),
-						zap.Int("tmdb_id", req.TMDBID),
-						zap.String("message", resp.Message),
-					)
 				}
 
 				if !alreadyExists {
@@ -557,15 +518,11 @@ func (s *Syncer) subscribeTV(ctx context.Context, req *store.Request) error {
 					)
 				}
 
-				// 保存链接（仅保存第一个）
+				// 保存链接（仅保存第一个，其余季的订阅由清理任务按季号查询）
 				if season == seasons[0] {
 					subscribeID := ""
-					if resp.Data != nil {
-						if resp.Data.ID > 0 {
-							subscribeID = strconv.Itoa(resp.Data.ID)
-						} else if resp.Data.SubscribeID > 0 {
-							subscribeID = strconv.Itoa(resp.Data.SubscribeID)
-						}
+					if result.SubscribeID > 0 {
+						subscribeID = strconv.Itoa(result.SubscribeID)
 					}
 
 					link := &store.MPLink{
@@ -582,63 +539,52 @@ func (s *Syncer) subscribeTV(ctx context.Context, req *store.Request) error {
 		}
 	}
 
-	// 保存到跟踪表
-	now := time.Now()
-	var trackingStatus store.TrackingStatus
-	if alreadyExists {
-		// 已存在的剧集直接标记为已入库
-		trackingStatus = store.TrackingTransferred
-	} else {
-		trackingStatus = store.TrackingSubscribed
-	}
+	// 保存到跟踪表，转换成功后由 Tracker 发送通知
+	s.trackSubscription(req, alreadyExists)
 
-	tracking := &store.SubscriptionTracking{
-		SourceRequestID: req.SourceRequestID,
-		TMDBID:          req.TMDBID,
-		Title:           req.Title,
-		MediaType:       req.MediaType,
-		SubscribeStatus: trackingStatus,
-		SubscribeTime:   &now,
-	}
+	return nil
+}
 
-	// 如果已存在，也设置 TransferTime
-	if alreadyExists {
-		tracking.TransferTime = &now
+// logSubscribeOutcome 记录订阅结果分类
+func (s *Syncer) logSubscribeOutcome(req *store.Request, season int, resp *mp.SubscribeResponse, result *mp.SubscribeResult) {
+	fields := []zap.Field{
+		zap.String("title", req.Title),
+		zap.Int("tmdb_id", req.TMDBID),
+		zap.Int("subscribe_id", result.SubscribeID),
+		zap.String("message", resp.Message),
+		zap.String("reason", result.Reason),
 	}
-
-	if err := s.store.SaveTracking(tracking); err != nil {
-		s.logger.Warn("Failed to save tracking", zap.Error(err))
+	if season > 0 {
+		fields = append(fields, zap.Int("season", season))
 	}
 
-	// 发送 Telegram 通知
-	if s.telegram != nil && s.telegram.IsEnabled() {
-		if alreadyExists {
-			// 发送"已在媒体库"通知
-			s.logger.Debug("Sending 'already exists' notification for TV", zap.String("title", req.Title))
-			s.telegram.NotifyAlreadyExists(req.Title, string(req.MediaType), req.TMDBID, req.PosterPath)
-		} else {
-			// 发送普通订阅通知
-			s.logger.Debug("Sending telegram notification for TV", zap.String("title", req.Title))
-			s.telegram.NotifySubscribed(req.Title, string(req.MediaType), req.TMDBID, req.PosterPath)
-		}
-	} else {
-		if s.telegram == nil {
-			s.logger.Debug("Telegram bot not initialized, skipping notification")
-		} else {
-			s.logger.Debug("Telegram bot disabled, skipping notification")
-		}
+	switch result.Outcome {
+	case mp.OutcomeExists:
+		s.logger.Info("media already exists in library", fields...)
+	case mp.OutcomeDuplicate:
+		s.logger.Info("subscription already exists in MoviePilot, reusing it", fields...)
+	default:
+		s.logger.Debug("subscription created in MoviePilot", fields...)
 	}
-
-	return nil
 }
 
+// notifyDrainTimeout 退出前等待 Webhook 投递和邮件发送完成的最长时间
+const notifyDrainTimeout = 15 * time.Second
+
 // Close 关闭同步器
 func (s *Syncer) Close() error {
 	// 停止 tracker
-	if s.tracker != nil {
-		if err := s.tracker.Stop(); err != nil {
-			s.logger.Error("Failed to stop tracker", zap.Error(err))
-		}
+	if err := s.tracker.Stop(); err != nil {
+		s.logger.Error("Failed to stop tracker", zap.Error(err))
+	}
+	s.stopNotify()
+	// 等待正在进行的 Webhook 投递写回结果，超时的投递由 replay-webhooks 重放
+	if s.webhooks != nil && !s.webhooks.WaitTimeout(notifyDrainTimeout) {
+		s.logger.Warn("Timed out waiting for webhook deliveries", zap.Duration("timeout", notifyDrainTimeout))
+	}
+	// 等待摘要调度退出和正在发送的邮件，之后才能关闭存储
+	if s.mailer != nil && !s.mailer.WaitTimeout(notifyDrainTimeout) {
+		s.logger.Warn("Timed out waiting for email notifier", zap.Duration("timeout", notifyDrainTimeout))
 	}
 	return s.store.Close()
 }
@@ -650,10 +596,8 @@ func (s *Syncer) RunDaemon(ctx context.Context) error {
 	)
 
 	// 启动 tracker
-	if s.tracker != nil {
-		if err := s.tracker.Start(); err != nil {
-			s.logger.Error("Failed to start tracker", zap.Error(err))
-		}
+	if err := s.tracker.Start(); err != nil {
+		s.logger.Error("Failed to start tracker", zap.Error(err))
 	}
 
 	ticker := time.NewTicker(time.Duration(s.cfg.SyncInterval) * time.Minute)
@@ -673,6 +617,10 @@ func (s *Syncer) RunDaemon(ctx context.Context) error {
 		case <-ticker.C:
 			if err := s.SyncOnce(ctx); err != nil {
 				s.logger.Error("sync failed", zap.Error(err))
+				s.notifier.Notify(&notify.Notification{
+					Event:  notify.EventError,
+					Reason: "同步失败: " + sanitizeError(err),
+				})
 			}
 		}
 	}
@@ -690,3 +638,32 @@ func sanitizeError(err error) string {
 	}
 	return msg
 }
+
+// trackSubscription 将请求的跟踪记录转换为已订阅；媒体库中已存在时直接转换为已入库
+func (s *Syncer) trackSubscription(req *store.Request, alreadyExists bool) {
+	tracking := &store.SubscriptionTracking{
+		SourceRequestID: req.SourceRequestID,
+		TMDBID:          req.TMDBID,
+		Title:           req.Title,
+		MediaType:       req.MediaType,
+		SubscribeStatus: store.TrackingPending,
+	}
+
+	to := store.TrackingSubscribed
+	if alreadyExists {
+		to = store.TrackingTransferred
+	}
+	err := s.tracker.Apply(tracking, tracker.Change{To: to})
+	if errors.Is(err, tracker.ErrIllegalTransition) {
+		// 已入库或已可观看的记录不再回退
+		s.logger.Debug("Skipping tracking transition",
+			zap.String("source_request_id", req.SourceRequestID),
+			zap.String("from", string(tracking.SubscribeStatus)),
+			zap.String("to", string(to)),
+		)
+		return
+	}
+	if err != nil {
+		s.logger.Warn("Failed to save tracking", zap.Error(err))
+	}
+}
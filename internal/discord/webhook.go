@@ -0,0 +1,304 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/notify"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+// maxRateLimitRetries 被限流（429）后的最大重试次数
+const maxRateLimitRetries = 3
+
+// maxDescription Discord embed 描述的最大长度
+const maxDescription = 4096
+
+// 状态条颜色
+const (
+	colorSuccess = 0x2ECC71 // 绿色：订阅、下载完成、入库、可观看
+	colorInfo    = 0x3498DB // 蓝色：已在库中、找到资源、下载中
+	colorWarning = 0xE67E22 // 橙色：重试、状态异常、延迟、种子异常
+	colorError   = 0xE74C3C // 红色：失败、系统错误
+	colorReport  = 0x9B59B6 // 紫色：每日报告
+)
+
+// eventColors 事件对应的状态条颜色
+var eventColors = map[notify.Event]int{
+	notify.EventSubscribed:          colorSuccess,
+	notify.EventDownloadComplete:    colorSuccess,
+	notify.EventTransferred:         colorSuccess,
+	notify.EventAvailable:           colorSuccess,
+	notify.EventEpisodesTransferred: colorSuccess,
+	notify.EventAlreadyExists:       colorInfo,
+	notify.EventResourceFound:       colorInfo,
+	notify.EventDownloadStarted:     colorInfo,
+	notify.EventDownloadProgress:    colorInfo,
+	notify.EventRetrying:            colorWarning,
+	notify.EventDrift:               colorWarning,
+	notify.EventEpisodesLate:        colorWarning,
+	notify.EventTorrentUnhealthy:    colorWarning,
+	notify.EventFailed:              colorError,
+	notify.EventError:               colorError,
+	notify.EventReport:              colorReport,
+}
+
+// Config Discord Webhook 配置
+type Config struct {
+	WebhookURL   string
+	MediaPageURL string // Jellyseerr 地址，embed 链接到其中的媒体页面（Jellyseerr 没有单个请求的页面）
+	Username     string // 覆盖 Webhook 默认的名称
+	AvatarURL    string // 覆盖 Webhook 默认的头像
+}
+
+// Webhook 通过 Discord Webhook 发送 embed 消息
+// 发送串行进行：按 X-RateLimit-* 响应头在额度用完时等待重置，被限流（429）时按 Retry-After 等待后重试
+type Webhook struct {
+	cfg        Config
+	store      store.Store
+	httpClient *http.Client
+	logger     *zap.Logger
+
+	mu           sync.Mutex
+	blockedUntil time.Time // 当前额度用完，在此之前不发送
+}
+
+// NewWebhook 创建 Discord 通知，st 用于查询请求人和季信息，可以为空
+func NewWebhook(cfg Config, st store.Store, logger *zap.Logger) *Webhook {
+	cfg.MediaPageURL = strings.TrimRight(cfg.MediaPageURL, "/")
+	return &Webhook{
+		cfg:   cfg,
+		store: st,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		logger: logger,
+	}
+}
+
+// Name 实现 notify.Notifier
+func (w *Webhook) Name() string {
+	return "discord"
+}
+
+// Notify 实现 notify.Notifier，异步发送
+func (w *Webhook) Notify(n *notify.Notification) {
+	go func() {
+		if err := w.send(context.Background(), n); err != nil {
+			w.logger.Error("Failed to send discord message",
+				zap.String("event", string(n.Event)),
+				zap.String("title", n.Title),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// payload Webhook 请求体
+type payload struct {
+	Username  string  `json:"username,omitempty"`
+	AvatarURL string  `json:"avatar_url,omitempty"`
+	Embeds    []embed `json:"embeds"`
+}
+
+// embed Discord embed
+type embed struct {
+	Title       string       `json:"title"`
+	Description string       `json:"description,omitempty"`
+	URL         string       `json:"url,omitempty"`
+	Color       int          `json:"color"`
+	Timestamp   string       `json:"timestamp,omitempty"`
+	Thumbnail   *embedImage  `json:"thumbnail,omitempty"`
+	Fields      []embedField `json:"fields,omitempty"`
+}
+
+// embedImage embed 中的图片
+type embedImage struct {
+	URL string `json:"url"`
+}
+
+// embedField embed 中的字段
+type embedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// buildEmbed 按通知生成 embed：海报缩略图、状态条颜色、Jellyseerr 媒体页面链接，以及类型、TMDB ID、请求人和季字段
+func (w *Webhook) buildEmbed(n *notify.Notification) embed {
+	rendered := notify.Render(n)
+	description := strings.Join(rendered.Lines, "\n")
+	if len([]rune(description)) > maxDescription {
+		description = string([]rune(description)[:maxDescription-1]) + "…"
+	}
+
+	e := embed{
+		Title:       rendered.Title(),
+		Description: description,
+		Color:       eventColors[n.Event],
+	}
+	if !n.Time.IsZero() {
+		e.Timestamp = n.Time.Format(time.RFC3339)
+	}
+
+	req := w.lookupRequest(n.SourceRequestID)
+	posterPath := n.PosterPath
+	if posterPath == "" && req != nil {
+		posterPath = req.PosterPath
+	}
+	if posterPath != "" {
+		e.Thumbnail = &embedImage{URL: notify.PosterBaseURL + posterPath}
+	}
+
+	if n.TMDBID == 0 {
+		return e
+	}
+	if w.cfg.MediaPageURL != "" {
+		e.URL = fmt.Sprintf("%s/%s/%d", w.cfg.MediaPageURL, jellyMediaType(n.MediaType), n.TMDBID)
+	}
+	e.Fields = append(e.Fields,
+		embedField{Name: "类型", Value: notify.MediaTypeLabel(n.MediaType), Inline: true},
+		embedField{Name: "TMDB ID", Value: fmt.Sprintf("[%d](%s)", n.TMDBID, notify.TMDBURL(n)), Inline: true},
+	)
+	if req != nil && req.RequestedBy != "" {
+		e.Fields = append(e.Fields, embedField{Name: "请求人", Value: req.RequestedBy, Inline: true})
+	}
+	if n.MediaType == "tv" && req != nil {
+		e.Fields = append(e.Fields, embedField{Name: "季", Value: formatSeasons(req), Inline: true})
+	}
+	return e
+}
+
+// lookupRequest 查询通知对应的请求
+func (w *Webhook) lookupRequest(sourceRequestID string) *store.Request {
+	if sourceRequestID == "" || w.store == nil {
+		return nil
+	}
+	req, err := w.store.GetRequest(sourceRequestID)
+	if err != nil {
+		w.logger.Warn("Failed to get request for discord",
+			zap.String("source_request_id", sourceRequestID),
+			zap.Error(err),
+		)
+		return nil
+	}
+	return req
+}
+
+// send 发送一条通知，遵守 Discord 的限流
+func (w *Webhook) send(ctx context.Context, n *notify.Notification) error {
+	data, err := json.Marshal(payload{
+		Username:  w.cfg.Username,
+		AvatarURL: w.cfg.AvatarURL,
+		Embeds:    []embed{w.buildEmbed(n)},
+	})
+	if err != nil {
+		return fmt.Errorf("marshal payload: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for attempt := 0; ; attempt++ {
+		if wait := time.Until(w.blockedUntil); wait > 0 {
+			if !notify.SleepContext(ctx, wait) {
+				return ctx.Err()
+			}
+		}
+
+		retryAfter, err := w.post(ctx, data)
+		if err == nil {
+			return nil
+		}
+		if retryAfter == 0 || attempt >= maxRateLimitRetries {
+			return err
+		}
+		w.logger.Warn("Discord rate limited",
+			zap.Duration("retry_after", retryAfter),
+			zap.Int("attempt", attempt+1),
+		)
+		w.blockedUntil = time.Now().Add(retryAfter)
+	}
+}
+
+// post 发送一次请求，被限流时返回需要等待的时间
+func (w *Webhook) post(ctx context.Context, data []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", w.cfg.WebhookURL, bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	// 当前额度用完时，等待重置后再发送下一条
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset := parseSeconds(resp.Header.Get("X-RateLimit-Reset-After")); reset > 0 {
+			w.blockedUntil = time.Now().Add(reset)
+		}
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter := parseSeconds(resp.Header.Get("Retry-After"))
+		if retryAfter == 0 {
+			var limited struct {
+				RetryAfter float64 `json:"retry_after"`
+			}
+			if json.Unmarshal(body, &limited) == nil {
+				retryAfter = time.Duration(limited.RetryAfter * float64(time.Second))
+			}
+		}
+		if retryAfter == 0 {
+			retryAfter = time.Second
+		}
+		return retryAfter, fmt.Errorf("rate limited: %s", string(body))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+	}
+	return 0, nil
+}
+
+// parseSeconds 解析秒数（可以是小数），无法解析时返回 0
+func parseSeconds(value string) time.Duration {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// formatSeasons 请求的季，如 "S1, S2"；没有指定季时为全部
+func formatSeasons(req *store.Request) string {
+	seasons, err := req.GetSeasons()
+	if err != nil || len(seasons) == 0 {
+		return "全部"
+	}
+	labels := make([]string, len(seasons))
+	for i, season := range seasons {
+		labels[i] = fmt.Sprintf("S%d", season)
+	}
+	return strings.Join(labels, ", ")
+}
+
+// jellyMediaType Jellyseerr 条目页面路径中的媒体类型
+func jellyMediaType(mediaType string) string {
+	if mediaType == "tv" {
+		return "tv"
+	}
+	return "movie"
+}
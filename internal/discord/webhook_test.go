@@ -0,0 +1,115 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/notify"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+func TestSend(t *testing.T) {
+	st, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+	req := &store.Request{SourceRequestID: "7", MediaType: store.MediaTypeTV, TMDBID: 1399, Title: "权力的游戏",
+		PosterPath: "/got.jpg", RequestedBy: "Alice", Status: store.StatusSynced, RequestedAt: time.Now()}
+	req.SetSeasons([]int{1, 2})
+	if err := st.SaveRequest(req); err != nil {
+		t.Fatalf("save request: %v", err)
+	}
+
+	var payloads []payload
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// 第一次请求被限流
+		if requests == 1 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(map[string]any{"message": "You are being rate limited.", "retry_after": 0.05})
+			return
+		}
+		var p payload
+		json.NewDecoder(r.Body).Decode(&p)
+		payloads = append(payloads, p)
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset-After", "0.05")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	hook := NewWebhook(Config{WebhookURL: server.URL, MediaPageURL: "http://jelly.local/", Username: "Syncer"}, st, zap.NewNop())
+	at := time.Date(2024, 5, 1, 20, 30, 0, 0, time.UTC)
+	n := &notify.Notification{
+		Event:           notify.EventSubscribed,
+		Time:            at,
+		SourceRequestID: "7",
+		Title:           "权力的游戏",
+		MediaType:       "tv",
+		TMDBID:          1399,
+	}
+
+	start := time.Now()
+	if err := hook.send(context.Background(), n); err != nil {
+		t.Fatalf("send() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected to wait for retry_after, took %v", elapsed)
+	}
+	if requests != 2 || len(payloads) != 1 {
+		t.Fatalf("expected 2 requests and 1 payload, got %d / %d", requests, len(payloads))
+	}
+
+	p := payloads[0]
+	if p.Username != "Syncer" || len(p.Embeds) != 1 {
+		t.Fatalf("unexpected payload %+v", p)
+	}
+	e := p.Embeds[0]
+	if e.Title != "✅ 已自动订阅" || e.Color != colorSuccess || e.URL != "http://jelly.local/tv/1399" ||
+		e.Timestamp != "2024-05-01T20:30:00Z" || !strings.HasPrefix(e.Description, "📺 权力的游戏") {
+		t.Errorf("unexpected embed %+v", e)
+	}
+	if e.Thumbnail == nil || e.Thumbnail.URL != notify.PosterBaseURL+"/got.jpg" {
+		t.Errorf("unexpected thumbnail %+v", e.Thumbnail)
+	}
+	want := []embedField{
+		{Name: "类型", Value: "📺 剧集", Inline: true},
+		{Name: "TMDB ID", Value: "[1399](https://www.themoviedb.org/tv/1399)", Inline: true},
+		{Name: "请求人", Value: "Alice", Inline: true},
+		{Name: "季", Value: "S1, S2", Inline: true},
+	}
+	if len(e.Fields) != len(want) {
+		t.Fatalf("unexpected fields %+v", e.Fields)
+	}
+	for i := range want {
+		if e.Fields[i] != want[i] {
+			t.Errorf("field %d = %+v, want %+v", i, e.Fields[i], want[i])
+		}
+	}
+
+	// 额度用完后等待重置再发送下一条
+	if hook.blockedUntil.IsZero() {
+		t.Error("expected rate limit bucket to be exhausted")
+	}
+	start = time.Now()
+	if err := hook.send(context.Background(), &notify.Notification{Event: notify.EventError, Reason: "同步失败"}); err != nil {
+		t.Fatalf("send() error = %v", err)
+	}
+	if time.Since(start) < 40*time.Millisecond {
+		t.Error("expected to wait for rate limit reset")
+	}
+	e = payloads[1].Embeds[0]
+	if e.Color != colorError || e.Thumbnail != nil || len(e.Fields) != 0 || e.URL != "" {
+		t.Errorf("unexpected error embed %+v", e)
+	}
+}
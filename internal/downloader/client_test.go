@@ -0,0 +1,136 @@
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestQBittorrentGetTorrents(t *testing.T) {
+	logins := 0
+	var gotHashes string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/auth/login":
+			if r.FormValue("username") != "admin" || r.FormValue("password") != "secret" {
+				w.Write([]byte("Fails."))
+				return
+			}
+			logins++
+			http.SetCookie(w, &http.Cookie{Name: "SID", Value: "session"})
+			w.Write([]byte("Ok."))
+		case "/api/v2/torrents/info":
+			if cookie, err := r.Cookie("SID"); err != nil || cookie.Value != "session" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			gotHashes = r.URL.Query().Get("hashes")
+			json.NewEncoder(w).Encode([]qbTorrent{
+				{Hash: "AAA", Name: "Dune", State: "stalledDL", Progress: 0.42, NumSeeds: 0, NumComplete: 3},
+				{Hash: "bbb", Name: "Show", State: "missingFiles"},
+				{Hash: "ccc", Name: "Done", State: "stalledUP", Progress: 1},
+			})
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(ClientQBittorrent, server.URL+"/", "admin", "secret")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	torrents, err := client.GetTorrents(context.Background(), []string{"AAA", "bbb", "ccc"})
+	if err != nil {
+		t.Fatalf("GetTorrents() error = %v", err)
+	}
+	if logins != 1 || gotHashes != "aaa|bbb|ccc" {
+		t.Errorf("logins = %d, hashes = %q", logins, gotHashes)
+	}
+	if len(torrents) != 3 {
+		t.Fatalf("expected 3 torrents, got %d", len(torrents))
+	}
+	if torrents[0].Hash != "aaa" || torrents[0].State != StateStalled || torrents[0].Progress != 42 || torrents[0].SwarmSeeders != 3 {
+		t.Errorf("unexpected stalled torrent %+v", torrents[0])
+	}
+	if torrents[1].State != StateError || torrents[1].Error != "missingFiles" {
+		t.Errorf("unexpected errored torrent %+v", torrents[1])
+	}
+	if torrents[2].State != StateCompleted {
+		t.Errorf("unexpected completed torrent %+v", torrents[2])
+	}
+
+	// 已登录时不再重复登录
+	if _, err := client.GetTorrents(context.Background(), []string{"aaa"}); err != nil || logins != 1 {
+		t.Errorf("GetTorrents() error = %v, logins = %d", err, logins)
+	}
+
+	bad, _ := NewClient(ClientQBittorrent, server.URL, "admin", "wrong")
+	if _, err := bad.GetTorrents(context.Background(), []string{"aaa"}); err == nil {
+		t.Error("expected login error")
+	}
+}
+
+func TestTransmissionGetTorrents(t *testing.T) {
+	var request struct {
+		Method    string `json:"method"`
+		Arguments struct {
+			IDs []string `json:"ids"`
+		} `json:"arguments"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "admin" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/transmission/rpc" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// 首次请求没有会话 ID，返回 409 和新的会话 ID
+		if r.Header.Get(transmissionSessionHeader) != "token" {
+			w.Header().Set(transmissionSessionHeader, "token")
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Write([]byte(`{"result":"success","arguments":{"torrents":[
+			{"hashString":"aaa","name":"Dune","status":4,"percentDone":0.5,"peersSendingToUs":0,"rateDownload":0,
+			 "trackerStats":[{"seederCount":-1},{"seederCount":0}]},
+			{"hashString":"bbb","name":"Show","status":4,"percentDone":0.1,"peersSendingToUs":2,"rateDownload":1024,
+			 "error":3,"errorString":"No data found"},
+			{"hashString":"ccc","name":"Done","status":6,"percentDone":1}
+		]}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(ClientTransmission, server.URL, "admin", "secret")
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	torrents, err := client.GetTorrents(context.Background(), []string{"AAA", "bbb", "ccc"})
+	if err != nil {
+		t.Fatalf("GetTorrents() error = %v", err)
+	}
+	if request.Method != "torrent-get" || len(request.Arguments.IDs) != 3 || request.Arguments.IDs[0] != "aaa" {
+		t.Errorf("unexpected request %+v", request)
+	}
+	if len(torrents) != 3 {
+		t.Fatalf("expected 3 torrents, got %d", len(torrents))
+	}
+	if torrents[0].State != StateStalled || torrents[0].SwarmSeeders != 0 || torrents[0].Progress != 50 {
+		t.Errorf("unexpected stalled torrent %+v", torrents[0])
+	}
+	if torrents[1].State != StateError || torrents[1].Error != "No data found" {
+		t.Errorf("unexpected errored torrent %+v", torrents[1])
+	}
+	if torrents[2].State != StateCompleted {
+		t.Errorf("unexpected completed torrent %+v", torrents[2])
+	}
+}
+
+func TestNewClientUnsupported(t *testing.T) {
+	if _, err := NewClient("deluge", "http://localhost", "", ""); err == nil {
+		t.Error("expected error for unsupported downloader")
+	}
+}
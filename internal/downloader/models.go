@@ -0,0 +1,68 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ClientType 下载器类型
+type ClientType string
+
+const (
+	ClientQBittorrent  ClientType = "qbittorrent"
+	ClientTransmission ClientType = "transmission"
+)
+
+// State 统一后的种子状态
+type State string
+
+const (
+	StateDownloading State = "downloading" // 下载中
+	StateStalled     State = "stalled"     // 下载中但没有速度
+	StateQueued      State = "queued"      // 排队或校验中
+	StatePaused      State = "paused"      // 已暂停
+	StateCompleted   State = "completed"   // 下载完成（做种或已停止做种）
+	StateError       State = "error"       // 下载器报告错误
+)
+
+// Torrent 下载器中的种子
+type Torrent struct {
+	Hash          string    `json:"hash"` // 小写 info hash
+	Name          string    `json:"name"`
+	State         State     `json:"state"`
+	Progress      float64   `json:"progress"`       // 0-100
+	Seeders       int       `json:"seeders"`        // 已连接的做种者
+	SwarmSeeders  int       `json:"swarm_seeders"`  // Tracker 报告的做种者，未知时为 -1
+	DownloadSpeed int64     `json:"download_speed"` // 字节/秒
+	Error         string    `json:"error,omitempty"`
+	AddedAt       time.Time `json:"added_at"`
+	LastActivity  time.Time `json:"last_activity"` // 最近一次有数据传输的时间，未知时为零值
+}
+
+// Client 下载器客户端
+type Client interface {
+	// Type 返回下载器类型
+	Type() ClientType
+	// GetTorrents 按 info hash 获取种子，下载器中不存在的种子不返回
+	GetTorrents(ctx context.Context, hashes []string) ([]Torrent, error)
+}
+
+// NewClient 按类型创建下载器客户端
+func NewClient(clientType ClientType, baseURL, username, password string) (Client, error) {
+	baseURL = strings.TrimRight(baseURL, "/")
+	switch clientType {
+	case ClientQBittorrent:
+		return newQBittorrent(baseURL, username, password), nil
+	case ClientTransmission:
+		return newTransmission(baseURL, username, password), nil
+	default:
+		return nil, fmt.Errorf("unsupported downloader type: %s", clientType)
+	}
+}
+
+// normalizeHash info hash 统一为小写
+func normalizeHash(hash string) string {
+	return strings.ToLower(strings.TrimSpace(hash))
+}
@@ -0,0 +1,206 @@
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errUnauthorized 登录失效，需要重新登录
+var errUnauthorized = errors.New("unauthorized")
+
+// qbittorrent qBittorrent Web API 客户端
+// 使用 Cookie 会话认证，会话失效（403）时自动重新登录一次
+type qbittorrent struct {
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+
+	mu  sync.Mutex
+	sid string // 登录后的 SID Cookie
+}
+
+// newQBittorrent 创建 qBittorrent 客户端
+func newQBittorrent(baseURL, username, password string) *qbittorrent {
+	return &qbittorrent{
+		baseURL:  baseURL,
+		username: username,
+		password: password,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Type 实现 Client
+func (c *qbittorrent) Type() ClientType {
+	return ClientQBittorrent
+}
+
+// qbTorrent /api/v2/torrents/info 返回的种子
+type qbTorrent struct {
+	Hash         string  `json:"hash"`
+	Name         string  `json:"name"`
+	State        string  `json:"state"`
+	Progress     float64 `json:"progress"`     // 0-1
+	NumSeeds     int     `json:"num_seeds"`    // 已连接的做种者
+	NumComplete  int     `json:"num_complete"` // Tracker 报告的做种者
+	DLSpeed      int64   `json:"dlspeed"`
+	AddedOn      int64   `json:"added_on"`
+	LastActivity int64   `json:"last_activity"`
+}
+
+// GetTorrents 实现 Client
+func (c *qbittorrent) GetTorrents(ctx context.Context, hashes []string) ([]Torrent, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+
+	normalized := make([]string, len(hashes))
+	for i, hash := range hashes {
+		normalized[i] = normalizeHash(hash)
+	}
+	q := url.Values{}
+	q.Set("hashes", strings.Join(normalized, "|"))
+
+	body, err := c.get(ctx, "/api/v2/torrents/info?"+q.Encode())
+	if errors.Is(err, errUnauthorized) {
+		if err = c.login(ctx); err == nil {
+			body, err = c.get(ctx, "/api/v2/torrents/info?"+q.Encode())
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []qbTorrent
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+
+	torrents := make([]Torrent, 0, len(raw))
+	for _, r := range raw {
+		torrent := Torrent{
+			Hash:          normalizeHash(r.Hash),
+			Name:          r.Name,
+			State:         qbState(r.State),
+			Progress:      r.Progress * 100,
+			Seeders:       r.NumSeeds,
+			SwarmSeeders:  r.NumComplete,
+			DownloadSpeed: r.DLSpeed,
+			AddedAt:       unixTime(r.AddedOn),
+			LastActivity:  unixTime(r.LastActivity),
+		}
+		if torrent.State == StateError {
+			torrent.Error = r.State
+		}
+		torrents = append(torrents, torrent)
+	}
+	return torrents, nil
+}
+
+// qbState 将 qBittorrent 状态映射为统一状态
+func qbState(state string) State {
+	switch state {
+	case "error", "missingFiles":
+		return StateError
+	case "stalledDL", "metaDL", "forcedMetaDL":
+		return StateStalled
+	case "downloading", "forcedDL":
+		return StateDownloading
+	case "pausedDL", "stoppedDL":
+		return StatePaused
+	case "uploading", "stalledUP", "pausedUP", "stoppedUP", "queuedUP", "forcedUP", "checkingUP":
+		return StateCompleted
+	default:
+		// queuedDL、checkingDL、checkingResumeData、allocating、moving 等
+		return StateQueued
+	}
+}
+
+// login 登录并保存 SID Cookie
+func (c *qbittorrent) login(ctx context.Context) error {
+	form := url.Values{}
+	form.Set("username", c.username)
+	form.Set("password", c.password)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/v2/auth/login", strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// qBittorrent 校验 Referer/Origin 防止 CSRF
+	req.Header.Set("Referer", c.baseURL)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || strings.TrimSpace(string(body)) != "Ok." {
+		return fmt.Errorf("login failed: status %d: %s", resp.StatusCode, string(body))
+	}
+
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "SID" {
+			c.mu.Lock()
+			c.sid = cookie.Value
+			c.mu.Unlock()
+			return nil
+		}
+	}
+	return fmt.Errorf("login failed: no SID cookie")
+}
+
+// get 发送 GET 请求，会话失效时返回 errUnauthorized
+func (c *qbittorrent) get(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Referer", c.baseURL)
+
+	c.mu.Lock()
+	sid := c.sid
+	c.mu.Unlock()
+	if sid == "" {
+		return nil, errUnauthorized
+	}
+	req.AddCookie(&http.Cookie{Name: "SID", Value: sid})
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized {
+		return nil, errUnauthorized
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
+
+// unixTime 将 Unix 秒转换为时间，非正数返回零值
+func unixTime(seconds int64) time.Time {
+	if seconds <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
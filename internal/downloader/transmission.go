@@ -0,0 +1,223 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// transmissionSessionHeader Transmission 的 CSRF 会话头
+const transmissionSessionHeader = "X-Transmission-Session-Id"
+
+// transmission Transmission RPC 客户端
+// 使用 HTTP Basic 认证；会话 ID 过期时服务器返回 409 和新的会话 ID，重试一次
+type transmission struct {
+	rpcURL     string
+	username   string
+	password   string
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	sessionID string
+}
+
+// newTransmission 创建 Transmission 客户端，baseURL 不含 /transmission/rpc 时自动补全
+func newTransmission(baseURL, username, password string) *transmission {
+	rpcURL := baseURL
+	if !strings.HasSuffix(rpcURL, "/rpc") {
+		rpcURL += "/transmission/rpc"
+	}
+	return &transmission{
+		rpcURL:   rpcURL,
+		username: username,
+		password: password,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Type 实现 Client
+func (c *transmission) Type() ClientType {
+	return ClientTransmission
+}
+
+// trRequest RPC 请求
+type trRequest struct {
+	Method    string      `json:"method"`
+	Arguments interface{} `json:"arguments,omitempty"`
+}
+
+// trResponse RPC 响应
+type trResponse struct {
+	Result    string          `json:"result"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// trTorrent torrent-get 返回的种子
+type trTorrent struct {
+	HashString       string  `json:"hashString"`
+	Name             string  `json:"name"`
+	Status           int     `json:"status"`
+	PercentDone      float64 `json:"percentDone"` // 0-1
+	Error            int     `json:"error"`       // 0 无错误，1 Tracker 警告，2 Tracker 错误，3 本地错误
+	ErrorString      string  `json:"errorString"`
+	PeersSendingToUs int     `json:"peersSendingToUs"`
+	RateDownload     int64   `json:"rateDownload"`
+	AddedDate        int64   `json:"addedDate"`
+	ActivityDate     int64   `json:"activityDate"`
+	TrackerStats     []struct {
+		SeederCount int `json:"seederCount"` // 未知时为 -1
+	} `json:"trackerStats"`
+}
+
+// Transmission 种子状态
+const (
+	trStopped      = 0
+	trCheckWait    = 1
+	trCheck        = 2
+	trDownloadWait = 3
+	trDownload     = 4
+	trSeedWait     = 5
+	trSeed         = 6
+)
+
+// trTorrentFields torrent-get 请求的字段
+var trTorrentFields = []string{
+	"hashString", "name", "status", "percentDone", "error", "errorString",
+	"peersSendingToUs", "rateDownload", "addedDate", "activityDate", "trackerStats",
+}
+
+// GetTorrents 实现 Client
+func (c *transmission) GetTorrents(ctx context.Context, hashes []string) ([]Torrent, error) {
+	if len(hashes) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(hashes))
+	for i, hash := range hashes {
+		ids[i] = normalizeHash(hash)
+	}
+
+	var result struct {
+		Torrents []trTorrent `json:"torrents"`
+	}
+	err := c.call(ctx, trRequest{
+		Method:    "torrent-get",
+		Arguments: map[string]interface{}{"ids": ids, "fields": trTorrentFields},
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	torrents := make([]Torrent, 0, len(result.Torrents))
+	for _, r := range result.Torrents {
+		torrent := Torrent{
+			Hash:          normalizeHash(r.HashString),
+			Name:          r.Name,
+			State:         trState(&r),
+			Progress:      r.PercentDone * 100,
+			Seeders:       r.PeersSendingToUs,
+			SwarmSeeders:  -1,
+			DownloadSpeed: r.RateDownload,
+			AddedAt:       unixTime(r.AddedDate),
+			LastActivity:  unixTime(r.ActivityDate),
+		}
+		for _, stats := range r.TrackerStats {
+			if stats.SeederCount > torrent.SwarmSeeders {
+				torrent.SwarmSeeders = stats.SeederCount
+			}
+		}
+		// Tracker 警告不影响下载，只把 Tracker 错误和本地错误视为出错
+		if r.Error >= 2 {
+			torrent.State = StateError
+			torrent.Error = r.ErrorString
+		}
+		torrents = append(torrents, torrent)
+	}
+	return torrents, nil
+}
+
+// trState 将 Transmission 状态映射为统一状态
+func trState(r *trTorrent) State {
+	switch r.Status {
+	case trSeedWait, trSeed:
+		return StateCompleted
+	case trCheckWait, trCheck, trDownloadWait:
+		return StateQueued
+	case trDownload:
+		if r.RateDownload == 0 && r.PeersSendingToUs == 0 {
+			return StateStalled
+		}
+		return StateDownloading
+	default:
+		if r.PercentDone >= 1 {
+			return StateCompleted
+		}
+		return StatePaused
+	}
+}
+
+// call 发送 RPC 请求，会话 ID 过期时更新后重试一次
+func (c *transmission) call(ctx context.Context, request trRequest, out interface{}) error {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, "POST", c.rpcURL, bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if c.username != "" {
+			req.SetBasicAuth(c.username, c.password)
+		}
+		c.mu.Lock()
+		if c.sessionID != "" {
+			req.Header.Set(transmissionSessionHeader, c.sessionID)
+		}
+		c.mu.Unlock()
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("do request: %w", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("read response: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusConflict {
+			c.mu.Lock()
+			c.sessionID = resp.Header.Get(transmissionSessionHeader)
+			c.mu.Unlock()
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+		}
+
+		var response trResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			return fmt.Errorf("unmarshal response: %w", err)
+		}
+		if response.Result != "success" {
+			return fmt.Errorf("rpc %s failed: %s", request.Method, response.Result)
+		}
+		if err := json.Unmarshal(response.Arguments, out); err != nil {
+			return fmt.Errorf("unmarshal arguments: %w", err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("rpc %s failed: session id rejected", request.Method)
+}
@@ -0,0 +1,94 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"mime"
+	"mime/quotedprintable"
+	"strings"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/notify"
+)
+
+// renderEvent 渲染单个事件的邮件主题和正文
+func renderEvent(item entry) (string, string) {
+	n := item.notification
+	subject := notify.Render(n).Title()
+	if n.Title != "" {
+		subject += ": " + n.Title
+	}
+
+	var b strings.Builder
+	b.WriteString(`<html><body style="font-family:sans-serif">`)
+	writeEntry(&b, item)
+	b.WriteString(`</body></html>`)
+	return subject, b.String()
+}
+
+// renderDigest 渲染摘要邮件的主题和正文
+func renderDigest(items []entry, now time.Time) (string, string) {
+	subject := fmt.Sprintf("订阅动态摘要 %s（%d 条）", now.Format("2006-01-02"), len(items))
+
+	var b strings.Builder
+	b.WriteString(`<html><body style="font-family:sans-serif">`)
+	fmt.Fprintf(&b, "<h2>%s</h2>", html.EscapeString(subject))
+	for i, item := range items {
+		if i > 0 {
+			b.WriteString(`<hr>`)
+		}
+		writeEntry(&b, item)
+	}
+	b.WriteString(`</body></html>`)
+	return subject, b.String()
+}
+
+// writeEntry 写入一条通知：海报和 notify.Render 生成的标题、正文
+func writeEntry(b *strings.Builder, item entry) {
+	n := item.notification
+	rendered := notify.Render(n)
+	posterURL := notify.PosterURL(n)
+	if posterURL == "" && item.request != nil && item.request.PosterPath != "" {
+		posterURL = notify.PosterBaseURL + item.request.PosterPath
+	}
+
+	b.WriteString(`<table><tr>`)
+	if posterURL != "" {
+		fmt.Fprintf(b, `<td valign="top"><img src="%s" width="92" alt=""></td>`, html.EscapeString(posterURL))
+	}
+	b.WriteString(`<td valign="top">`)
+	fmt.Fprintf(b, "<h3>%s</h3>", html.EscapeString(rendered.Title()))
+	for _, line := range rendered.Lines {
+		// 报告正文是多行文本
+		fmt.Fprintf(b, "<p>%s</p>", strings.ReplaceAll(html.EscapeString(line), "\n", "<br>"))
+	}
+	if item.request != nil && item.request.RequestedBy != "" {
+		fmt.Fprintf(b, "<p>👤 请求人: %s</p>", html.EscapeString(item.request.RequestedBy))
+	}
+	if rendered.Footer != "" {
+		fmt.Fprintf(b, `<p style="color:#888">%s</p>`, html.EscapeString(rendered.Footer))
+	}
+	b.WriteString(`</td></tr></table>`)
+}
+
+// buildMessage 构造 UTF-8 HTML 邮件，正文使用 quoted-printable 编码
+func buildMessage(from, to, subject, body string) ([]byte, error) {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	w := quotedprintable.NewWriter(&msg)
+	if _, err := w.Write([]byte(body)); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return msg.Bytes(), nil
+}
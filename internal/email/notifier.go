@@ -0,0 +1,322 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/notify"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+// 发送模式
+const (
+	ModeImmediate = "immediate" // 每个事件发送一封邮件
+	ModeDigest    = "digest"    // 每天汇总发送一封邮件，仅守护进程模式
+)
+
+// Config SMTP 通知配置
+type Config struct {
+	Host         string
+	Port         int
+	Username     string // 为空表示不认证
+	Password     string
+	From         string
+	To           []string // 接收所有通知的地址
+	Mode         string   // immediate 或 digest
+	DigestTime   string   // 摘要发送时间，格式 HH:MM
+	PerRequester bool     // 同时发送给 Jellyseerr 请求人的邮箱（只包含其本人的请求）
+}
+
+// digestStateKey 待发送摘要在 tracker_state 中的键
+const digestStateKey = "email_digest_pending"
+
+// digestState 持久化的待发送摘要，请求在加载时重新查询
+type digestState struct {
+	Order   []string                          `json:"order"`
+	Pending map[string][]*notify.Notification `json:"pending"`
+}
+
+// entry 摘要中的一条通知
+type entry struct {
+	notification *notify.Notification
+	request      *store.Request // 可能为空
+}
+
+// Notifier 通过 SMTP 发送 HTML 邮件
+// 摘要模式下按收件人缓存当天的通知，到达 DigestTime 时每个收件人发送一封
+// 待发送的摘要同时保存到 tracker_state，重启后继续累积，由守护进程按时发送
+type Notifier struct {
+	cfg    Config
+	store  store.Store
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	pending map[string][]entry // 收件人 → 待发送的通知
+	order   []string           // 收件人加入顺序，保证发送顺序稳定
+
+	wg sync.WaitGroup // 摘要调度和正在发送的邮件
+}
+
+// NewNotifier 创建邮件通知渠道，摘要模式下加载上次退出时未发送的摘要
+func NewNotifier(cfg Config, st store.Store, logger *zap.Logger) *Notifier {
+	e := &Notifier{
+		cfg:     cfg,
+		store:   st,
+		logger:  logger,
+		pending: make(map[string][]entry),
+	}
+	if cfg.Mode == ModeDigest {
+		e.loadDigest()
+	}
+	return e
+}
+
+// Name 实现 notify.Notifier
+func (e *Notifier) Name() string {
+	return "email"
+}
+
+// Notify 实现 notify.Notifier
+func (e *Notifier) Notify(n *notify.Notification) {
+	req := e.lookupRequest(n.SourceRequestID)
+	recipients := e.recipients(req)
+	if len(recipients) == 0 {
+		return
+	}
+
+	item := entry{notification: n, request: req}
+	if e.cfg.Mode == ModeDigest {
+		e.mu.Lock()
+		for _, to := range recipients {
+			if _, ok := e.pending[to]; !ok {
+				e.order = append(e.order, to)
+			}
+			e.pending[to] = append(e.pending[to], item)
+		}
+		e.saveDigest()
+		e.mu.Unlock()
+		return
+	}
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		subject, body := renderEvent(item)
+		for _, to := range recipients {
+			if err := e.sendMail(to, subject, body); err != nil {
+				e.logger.Error("Failed to send email",
+					zap.String("to", to),
+					zap.String("event", string(n.Event)),
+					zap.Error(err),
+				)
+			}
+		}
+	}()
+}
+
+// Start 在后台运行摘要调度，退出时用 WaitTimeout 等待
+func (e *Notifier) Start(ctx context.Context) {
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.Run(ctx)
+	}()
+}
+
+// Run 摘要模式下每天按 DigestTime 发送摘要
+// 退出时不提前发送，未发送的摘要已持久化，下次启动后继续累积
+func (e *Notifier) Run(ctx context.Context) {
+	if e.cfg.Mode != ModeDigest {
+		return
+	}
+
+	e.logger.Info("Email digest scheduler started", zap.String("digest_time", e.cfg.DigestTime))
+
+	for {
+		next := nextDigestTime(time.Now(), e.cfg.DigestTime)
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			e.logger.Info("Email digest scheduler stopped")
+			return
+		case <-timer.C:
+			e.Flush()
+		}
+	}
+}
+
+// Flush 立即发送所有收件人的摘要，返回发送失败的收件人数
+func (e *Notifier) Flush() int {
+	e.mu.Lock()
+	pending, order := e.pending, e.order
+	e.pending = make(map[string][]entry)
+	e.order = nil
+	e.saveDigest()
+	e.mu.Unlock()
+
+	failed := 0
+	for _, to := range order {
+		subject, body := renderDigest(pending[to], time.Now())
+		if err := e.sendMail(to, subject, body); err != nil {
+			failed++
+			e.logger.Error("Failed to send email digest",
+				zap.String("to", to),
+				zap.Int("events", len(pending[to])),
+				zap.Error(err),
+			)
+		}
+	}
+	return failed
+}
+
+// WaitTimeout 最多等待 timeout，返回摘要调度和正在发送的邮件是否已全部结束
+func (e *Notifier) WaitTimeout(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		e.wg.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// saveDigest 持久化待发送的摘要，调用方需持有 e.mu
+func (e *Notifier) saveDigest() {
+	if e.store == nil {
+		return
+	}
+	state := digestState{Order: e.order, Pending: make(map[string][]*notify.Notification, len(e.pending))}
+	for to, entries := range e.pending {
+		for _, item := range entries {
+			state.Pending[to] = append(state.Pending[to], item.notification)
+		}
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		e.logger.Error("Failed to marshal email digest", zap.Error(err))
+		return
+	}
+	if err := e.store.SetTrackerState(digestStateKey, string(data)); err != nil {
+		e.logger.Error("Failed to save email digest", zap.Error(err))
+	}
+}
+
+// loadDigest 加载上次退出时未发送的摘要
+func (e *Notifier) loadDigest() {
+	if e.store == nil {
+		return
+	}
+	value, err := e.store.GetTrackerState(digestStateKey)
+	if err != nil {
+		e.logger.Error("Failed to load email digest", zap.Error(err))
+		return
+	}
+	if value == "" {
+		return
+	}
+	var state digestState
+	if err := json.Unmarshal([]byte(value), &state); err != nil {
+		e.logger.Warn("Discarding unreadable email digest", zap.Error(err))
+		return
+	}
+
+	requests := make(map[string]*store.Request)
+	count := 0
+	for _, to := range state.Order {
+		for _, n := range state.Pending[to] {
+			req, ok := requests[n.SourceRequestID]
+			if !ok {
+				req = e.lookupRequest(n.SourceRequestID)
+				requests[n.SourceRequestID] = req
+			}
+			e.pending[to] = append(e.pending[to], entry{notification: n, request: req})
+			count++
+		}
+		if len(e.pending[to]) > 0 {
+			e.order = append(e.order, to)
+		}
+	}
+	if count > 0 {
+		e.logger.Info("Restored pending email digest", zap.Int("recipients", len(e.order)), zap.Int("events", count))
+	}
+}
+
+// lookupRequest 查询通知对应的请求，用于获取海报和请求人邮箱
+func (e *Notifier) lookupRequest(sourceRequestID string) *store.Request {
+	if sourceRequestID == "" || e.store == nil {
+		return nil
+	}
+	req, err := e.store.GetRequest(sourceRequestID)
+	if err != nil {
+		e.logger.Warn("Failed to get request for email",
+			zap.String("source_request_id", sourceRequestID),
+			zap.Error(err),
+		)
+		return nil
+	}
+	return req
+}
+
+// recipients 通知的收件人：配置的地址，以及按配置加上请求人
+func (e *Notifier) recipients(req *store.Request) []string {
+	recipients := append([]string(nil), e.cfg.To...)
+	if e.cfg.PerRequester && req != nil && req.RequesterEmail != "" {
+		for _, to := range recipients {
+			if strings.EqualFold(to, req.RequesterEmail) {
+				return recipients
+			}
+		}
+		recipients = append(recipients, req.RequesterEmail)
+	}
+	return recipients
+}
+
+// sendMail 发送一封 HTML 邮件
+func (e *Notifier) sendMail(to, subject, body string) error {
+	msg, err := buildMessage(e.cfg.From, to, subject, body)
+	if err != nil {
+		return fmt.Errorf("build message: %w", err)
+	}
+
+	var auth smtp.Auth
+	if e.cfg.Username != "" {
+		auth = smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host)
+	}
+	addr := net.JoinHostPort(e.cfg.Host, strconv.Itoa(e.cfg.Port))
+	if err := smtp.SendMail(addr, auth, e.cfg.From, []string{to}, msg); err != nil {
+		return fmt.Errorf("send mail: %w", err)
+	}
+
+	e.logger.Debug("Email sent", zap.String("to", to), zap.String("subject", subject))
+	return nil
+}
+
+// nextDigestTime 计算下一次摘要发送时间，格式错误时使用 20:00
+func nextDigestTime(now time.Time, digestTime string) time.Time {
+	clock, err := time.Parse("15:04", digestTime)
+	if err != nil {
+		clock, _ = time.Parse("15:04", "20:00")
+	}
+
+	next := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
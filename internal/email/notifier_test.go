@@ -0,0 +1,289 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/notify"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+// received 本地 SMTP 服务收到的邮件
+type received struct {
+	to      string
+	subject string
+	body    string
+}
+
+// smtpStandIn 只支持明文 SMTP 基本命令的本地服务
+type smtpStandIn struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	messages []received
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &smtpStandIn{listener: listener}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(t, conn)
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func (s *smtpStandIn) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpStandIn) serve(t *testing.T, conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 localhost ESMTP")
+	var to string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			to = strings.Trim(strings.TrimSpace(line)[len("RCPT TO:"):], "<>")
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(dataLine, "."))
+			}
+			s.record(t, to, data.String())
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// record 解析并保存邮件
+func (s *smtpStandIn) record(t *testing.T, to, data string) {
+	msg, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		t.Errorf("parse message: %v", err)
+		return
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	body, _ := io.ReadAll(quotedprintable.NewReader(msg.Body))
+
+	s.mu.Lock()
+	s.messages = append(s.messages, received{to: to, subject: subject, body: string(body)})
+	s.mu.Unlock()
+}
+
+// wait 等待收到指定数量的邮件
+func (s *smtpStandIn) wait(t *testing.T, count int) []received {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		n := len(s.messages)
+		s.mu.Unlock()
+		if n >= count {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.messages) != count {
+		t.Fatalf("expected %d messages, got %d", count, len(s.messages))
+	}
+	return append([]received(nil), s.messages...)
+}
+
+func TestImmediate(t *testing.T) {
+	st, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+	req := &store.Request{SourceRequestID: "1", MediaType: store.MediaTypeMovie, TMDBID: 100, Title: "沙丘", PosterPath: "/dune.jpg",
+		RequestedBy: "Alice", RequesterEmail: "alice@example.com", Status: store.StatusSynced, RequestedAt: time.Now()}
+	if err := st.SaveRequest(req); err != nil {
+		t.Fatalf("save request: %v", err)
+	}
+
+	server := newSMTPStandIn(t)
+	mailer := NewNotifier(Config{
+		Host: "127.0.0.1",
+		Port: server.port(),
+		From: "syncer@example.com",
+		To:   []string{"admin@example.com"},
+		Mode: ModeImmediate,
+	}, st, zap.NewNop())
+
+	mailer.Notify(&notify.Notification{
+		Event:           notify.EventFailed,
+		Time:            time.Now(),
+		SourceRequestID: "1",
+		Title:           "沙丘",
+		Reason:          "入库失败: <磁盘已满>",
+	})
+
+	messages := server.wait(t, 1)
+	msg := messages[0]
+	if msg.to != "admin@example.com" || msg.subject != "❌ 订阅失败: 沙丘" {
+		t.Errorf("unexpected message %+v", msg)
+	}
+	if !strings.Contains(msg.body, notify.PosterBaseURL+"/dune.jpg") {
+		t.Errorf("expected poster from request in body: %s", msg.body)
+	}
+	if !strings.Contains(msg.body, "&lt;磁盘已满&gt;") || !strings.Contains(msg.body, "请求人: Alice") {
+		t.Errorf("unexpected body: %s", msg.body)
+	}
+}
+
+func TestDigest(t *testing.T) {
+	st, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+	for _, req := range []*store.Request{
+		{SourceRequestID: "1", MediaType: store.MediaTypeMovie, TMDBID: 100, Title: "沙丘",
+			RequestedBy: "Alice", RequesterEmail: "alice@example.com", Status: store.StatusSynced, RequestedAt: time.Now()},
+		{SourceRequestID: "2", MediaType: store.MediaTypeMovie, TMDBID: 200, Title: "奥本海默",
+			Status: store.StatusSynced, RequestedAt: time.Now()},
+	} {
+		if err := st.SaveRequest(req); err != nil {
+			t.Fatalf("save request: %v", err)
+		}
+	}
+
+	server := newSMTPStandIn(t)
+	mailer := NewNotifier(Config{
+		Host:         "127.0.0.1",
+		Port:         server.port(),
+		From:         "syncer@example.com",
+		To:           []string{"admin@example.com"},
+		Mode:         ModeDigest,
+		PerRequester: true,
+	}, st, zap.NewNop())
+
+	mailer.Notify(&notify.Notification{Event: notify.EventSubscribed, Time: time.Now(), SourceRequestID: "1", Title: "沙丘"})
+	mailer.Notify(&notify.Notification{Event: notify.EventSubscribed, Time: time.Now(), SourceRequestID: "2", Title: "奥本海默"})
+	mailer.Notify(&notify.Notification{Event: notify.EventTransferred, Time: time.Now(), SourceRequestID: "1", Title: "沙丘"})
+
+	// 摘要模式下不立即发送
+	time.Sleep(50 * time.Millisecond)
+	server.wait(t, 0)
+
+	if failed := mailer.Flush(); failed != 0 {
+		t.Fatalf("Flush() failed for %d recipients", failed)
+	}
+	messages := server.wait(t, 2)
+
+	byRecipient := make(map[string]received)
+	for _, msg := range messages {
+		byRecipient[msg.to] = msg
+	}
+	admin, alice := byRecipient["admin@example.com"], byRecipient["alice@example.com"]
+	if !strings.Contains(admin.subject, "3 条") || !strings.Contains(admin.body, "奥本海默") {
+		t.Errorf("unexpected admin digest %+v", admin)
+	}
+	if !strings.Contains(alice.subject, "2 条") || strings.Contains(alice.body, "奥本海默") || !strings.Contains(alice.body, "入库成功") {
+		t.Errorf("unexpected requester digest %+v", alice)
+	}
+
+	// 发送后清空
+	if mailer.Flush(); len(server.wait(t, 2)) != 2 {
+		t.Error("expected no further digest")
+	}
+}
+
+// TestDigestRestart 重启后恢复未发送的摘要，退出时不提前发送
+func TestDigestRestart(t *testing.T) {
+	st, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+	req := &store.Request{SourceRequestID: "1", MediaType: store.MediaTypeMovie, TMDBID: 100, Title: "沙丘",
+		RequestedBy: "Alice", RequesterEmail: "alice@example.com", Status: store.StatusSynced, RequestedAt: time.Now()}
+	if err := st.SaveRequest(req); err != nil {
+		t.Fatalf("save request: %v", err)
+	}
+
+	server := newSMTPStandIn(t)
+	cfg := Config{
+		Host:         "127.0.0.1",
+		Port:         server.port(),
+		From:         "syncer@example.com",
+		To:           []string{"admin@example.com"},
+		Mode:         ModeDigest,
+		DigestTime:   time.Now().Add(-time.Hour).Format("15:04"),
+		PerRequester: true,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mailer := NewNotifier(cfg, st, zap.NewNop())
+	mailer.Start(ctx)
+	mailer.Notify(&notify.Notification{Event: notify.EventSubscribed, Time: time.Now(), SourceRequestID: "1", Title: "沙丘"})
+	cancel()
+	if !mailer.WaitTimeout(time.Second) {
+		t.Fatal("digest scheduler did not stop")
+	}
+	server.wait(t, 0)
+
+	restarted := NewNotifier(cfg, st, zap.NewNop())
+	if failed := restarted.Flush(); failed != 0 {
+		t.Fatalf("Flush() failed for %d recipients", failed)
+	}
+	messages := server.wait(t, 2)
+	for _, msg := range messages {
+		if !strings.Contains(msg.body, "沙丘") {
+			t.Errorf("unexpected digest %+v", msg)
+		}
+	}
+
+	// 发送后不再恢复
+	if NewNotifier(cfg, st, zap.NewNop()).Flush(); len(server.wait(t, 2)) != 2 {
+		t.Error("expected no further digest")
+	}
+}
@@ -0,0 +1,207 @@
+package mediaserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Client Jellyfin/Emby API 客户端
+// 两者的 API 基本一致，认证统一使用 X-Emby-Token 头
+type Client struct {
+	serverType ServerType
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewClient 创建客户端
+func NewClient(serverType ServerType, baseURL, apiKey string) *Client {
+	return &Client{
+		serverType: serverType,
+		baseURL:    baseURL,
+		apiKey:     apiKey,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// tmdbScanPageSize Jellyfin 遍历带 TMDB ID 的条目时每页的数量
+const tmdbScanPageSize = 200
+
+// FindByTMDBID 按 TMDB ID 在媒体库中查找电影或剧集，未找到返回 nil
+// Emby 支持按外部 ID 直接过滤；Jellyfin 不支持，先按标题搜索后比对 ProviderIds，
+// 标题可能是与媒体库不一致的本地化名称，搜索不到时按入库时间倒序遍历带 TMDB ID 的条目
+func (c *Client) FindByTMDBID(ctx context.Context, tmdbID int, itemType ItemType, title string) (*Item, error) {
+	q := url.Values{}
+	q.Set("Recursive", "true")
+	q.Set("IncludeItemTypes", string(itemType))
+	q.Set("Fields", "ProviderIds,Path")
+
+	if c.serverType == ServerEmby {
+		q.Set("AnyProviderIdEquals", fmt.Sprintf("tmdb.%d", tmdbID))
+		var response ItemsResponse
+		if err := c.get(ctx, "/Items", q, &response); err != nil {
+			return nil, err
+		}
+		return matchTMDBID(response.Items, tmdbID), nil
+	}
+
+	q.Set("HasTmdbId", "true")
+	if title != "" {
+		search := url.Values{}
+		for key, values := range q {
+			search[key] = values
+		}
+		search.Set("SearchTerm", title)
+		var response ItemsResponse
+		if err := c.get(ctx, "/Items", search, &response); err != nil {
+			return nil, err
+		}
+		if item := matchTMDBID(response.Items, tmdbID); item != nil {
+			return item, nil
+		}
+	}
+
+	q.Set("SortBy", "DateCreated")
+	q.Set("SortOrder", "Descending")
+	q.Set("Limit", strconv.Itoa(tmdbScanPageSize))
+	for start := 0; ; {
+		q.Set("StartIndex", strconv.Itoa(start))
+		var response ItemsResponse
+		if err := c.get(ctx, "/Items", q, &response); err != nil {
+			return nil, err
+		}
+		if item := matchTMDBID(response.Items, tmdbID); item != nil {
+			return item, nil
+		}
+		start += len(response.Items)
+		if len(response.Items) == 0 || start >= response.TotalRecordCount {
+			return nil, nil
+		}
+	}
+}
+
+// matchTMDBID 返回 TMDB ID 匹配的条目，没有时返回 nil
+func matchTMDBID(items []Item, tmdbID int) *Item {
+	want := strconv.Itoa(tmdbID)
+	for i := range items {
+		if items[i].ProviderID("Tmdb") == want {
+			return &items[i]
+		}
+	}
+	return nil
+}
+
+// GetItem 按条目 ID 获取条目（含外部 ID），未找到返回 nil
+func (c *Client) GetItem(ctx context.Context, itemID string) (*Item, error) {
+	q := url.Values{}
+	q.Set("Ids", itemID)
+	q.Set("Fields", "ProviderIds,Path")
+
+	var response ItemsResponse
+	if err := c.get(ctx, "/Items", q, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Items) == 0 {
+		return nil, nil
+	}
+	return &response.Items[0], nil
+}
+
+// ListEpisodes 列出剧集的所有单集
+func (c *Client) ListEpisodes(ctx context.Context, seriesID string) ([]Item, error) {
+	q := url.Values{}
+	q.Set("Fields", "Path")
+
+	var response ItemsResponse
+	if err := c.get(ctx, "/Shows/"+url.PathEscape(seriesID)+"/Episodes", q, &response); err != nil {
+		return nil, err
+	}
+	return response.Items, nil
+}
+
+// NotifyPathUpdated 通知媒体服务器扫描指定路径，路径为空时扫描整个媒体库
+func (c *Client) NotifyPathUpdated(ctx context.Context, path string) error {
+	if path == "" {
+		return c.post(ctx, "/Library/Refresh", nil)
+	}
+	return c.post(ctx, "/Library/Media/Updated", mediaUpdatedRequest{
+		Updates: []mediaUpdate{{Path: path, UpdateType: "Created"}},
+	})
+}
+
+// get 发送 GET 请求并解析 JSON 响应
+func (c *Client) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	body, err := c.do(req)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("unmarshal response: %w", err)
+	}
+	return nil
+}
+
+// post 发送 POST 请求，payload 为 nil 时不带请求体
+func (c *Client) post(ctx context.Context, path string, payload interface{}) error {
+	var reader io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("marshal payload: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	_, err = c.do(req)
+	return err
+}
+
+// do 设置认证头、发送请求并检查状态码
+func (c *Client) do(req *http.Request) ([]byte, error) {
+	req.Header.Set("X-Emby-Token", c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
@@ -0,0 +1,138 @@
+package mediaserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestFindByTMDBID(t *testing.T) {
+	var query map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Emby-Token") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		query = map[string]string{}
+		for key := range r.URL.Query() {
+			query[key] = r.URL.Query().Get(key)
+		}
+		// 标题搜索会返回同名的其他条目
+		json.NewEncoder(w).Encode(ItemsResponse{Items: []Item{
+			{ID: "a", Name: "沙丘", Type: ItemMovie, ProviderIDs: map[string]string{"Tmdb": "438631"}},
+			{ID: "b", Name: "沙丘2", Type: ItemMovie, ProviderIDs: map[string]string{"tmdb": "693134"}},
+		}})
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	jellyfin := NewClient(ServerJellyfin, server.URL, "key")
+	item, err := jellyfin.FindByTMDBID(ctx, 693134, ItemMovie, "沙丘")
+	if err != nil {
+		t.Fatalf("FindByTMDBID() error = %v", err)
+	}
+	if item == nil || item.ID != "b" {
+		t.Fatalf("FindByTMDBID() = %+v, want item b", item)
+	}
+	if query["SearchTerm"] != "沙丘" || query["AnyProviderIdEquals"] != "" {
+		t.Errorf("unexpected jellyfin query %v", query)
+	}
+
+	emby := NewClient(ServerEmby, server.URL, "key")
+	if _, err := emby.FindByTMDBID(ctx, 693134, ItemMovie, "沙丘"); err != nil {
+		t.Fatalf("FindByTMDBID() error = %v", err)
+	}
+	if query["AnyProviderIdEquals"] != "tmdb.693134" {
+		t.Errorf("unexpected emby query %v", query)
+	}
+
+	item, err = jellyfin.FindByTMDBID(ctx, 1, ItemMovie, "沙丘")
+	if err != nil || item != nil {
+		t.Errorf("FindByTMDBID() = %+v, %v, want nil", item, err)
+	}
+
+	if _, err := NewClient(ServerJellyfin, server.URL, "wrong").FindByTMDBID(ctx, 1, ItemMovie, ""); err == nil {
+		t.Error("expected error for unauthorized request")
+	}
+}
+
+// TestFindByTMDBIDLocalizedTitle 请求标题与媒体库名称不一致时按 TMDB ID 遍历媒体库
+func TestFindByTMDBIDLocalizedTitle(t *testing.T) {
+	library := []Item{
+		{ID: "a", Name: "Oppenheimer", Type: ItemMovie, ProviderIDs: map[string]string{"Tmdb": "872585"}},
+		{ID: "b", Name: "Dune: Part Two", Type: ItemMovie, ProviderIDs: map[string]string{"Tmdb": "693134"}},
+		{ID: "c", Name: "Dune", Type: ItemMovie, ProviderIDs: map[string]string{"Tmdb": "438631"}},
+	}
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("SearchTerm") != "" {
+			// 媒体库使用英文名，中文标题搜索不到
+			json.NewEncoder(w).Encode(ItemsResponse{})
+			return
+		}
+		if q.Get("HasTmdbId") != "true" || q.Get("SortBy") != "DateCreated" {
+			t.Errorf("unexpected scan query %v", q)
+		}
+		// 每页只返回一条，验证按实际返回数量翻页
+		start, _ := strconv.Atoi(q.Get("StartIndex"))
+		pages = append(pages, q.Get("StartIndex"))
+		var items []Item
+		if start < len(library) {
+			items = library[start : start+1]
+		}
+		json.NewEncoder(w).Encode(ItemsResponse{Items: items, TotalRecordCount: len(library)})
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := NewClient(ServerJellyfin, server.URL, "key")
+
+	item, err := client.FindByTMDBID(ctx, 693134, ItemMovie, "沙丘2")
+	if err != nil {
+		t.Fatalf("FindByTMDBID() error = %v", err)
+	}
+	if item == nil || item.ID != "b" {
+		t.Fatalf("FindByTMDBID() = %+v, want item b", item)
+	}
+
+	pages = nil
+	item, err = client.FindByTMDBID(ctx, 1, ItemMovie, "不存在")
+	if err != nil || item != nil {
+		t.Errorf("FindByTMDBID() = %+v, %v, want nil", item, err)
+	}
+	if len(pages) != len(library) {
+		t.Errorf("scanned pages %v, want %d", pages, len(library))
+	}
+}
+
+func TestNotifyPathUpdated(t *testing.T) {
+	var gotPath string
+	var gotBody mediaUpdatedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotBody = mediaUpdatedRequest{}
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(ServerJellyfin, server.URL, "key")
+	if err := client.NotifyPathUpdated(context.Background(), "/media/movies/Dune (2024)"); err != nil {
+		t.Fatalf("NotifyPathUpdated() error = %v", err)
+	}
+	if gotPath != "/Library/Media/Updated" || len(gotBody.Updates) != 1 || gotBody.Updates[0].Path != "/media/movies/Dune (2024)" {
+		t.Errorf("unexpected request %s %+v", gotPath, gotBody)
+	}
+
+	if err := client.NotifyPathUpdated(context.Background(), ""); err != nil {
+		t.Fatalf("NotifyPathUpdated() error = %v", err)
+	}
+	if gotPath != "/Library/Refresh" {
+		t.Errorf("path = %s, want /Library/Refresh", gotPath)
+	}
+}
@@ -0,0 +1,59 @@
+package mediaserver
+
+import "strings"
+
+// ServerType 媒体服务器类型
+type ServerType string
+
+const (
+	ServerJellyfin ServerType = "jellyfin"
+	ServerEmby     ServerType = "emby"
+)
+
+// ItemType 媒体库条目类型
+type ItemType string
+
+const (
+	ItemMovie   ItemType = "Movie"
+	ItemSeries  ItemType = "Series"
+	ItemEpisode ItemType = "Episode"
+)
+
+// Item 媒体库条目
+type Item struct {
+	ID                string            `json:"Id"`
+	Name              string            `json:"Name"`
+	Type              ItemType          `json:"Type"`
+	ProductionYear    int               `json:"ProductionYear,omitempty"`
+	ProviderIDs       map[string]string `json:"ProviderIds,omitempty"`
+	Path              string            `json:"Path,omitempty"`
+	IndexNumber       int               `json:"IndexNumber,omitempty"`       // 集号
+	ParentIndexNumber int               `json:"ParentIndexNumber,omitempty"` // 季号
+}
+
+// ProviderID 按名称读取外部 ID（不区分大小写）
+func (i *Item) ProviderID(name string) string {
+	for key, value := range i.ProviderIDs {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+// ItemsResponse 条目列表响应
+type ItemsResponse struct {
+	Items            []Item `json:"Items"`
+	TotalRecordCount int    `json:"TotalRecordCount"`
+}
+
+// mediaUpdate 通知媒体服务器路径变化
+type mediaUpdate struct {
+	Path       string `json:"Path"`
+	UpdateType string `json:"UpdateType"`
+}
+
+// mediaUpdatedRequest /Library/Media/Updated 请求体
+type mediaUpdatedRequest struct {
+	Updates []mediaUpdate `json:"Updates"`
+}
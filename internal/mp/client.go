@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"golang.org/x/time/rate"
@@ -31,6 +34,8 @@ type Client struct {
 	limiter      *rate.Limiter
 	maxRetries   int
 	dryRun       bool
+	// existsKeywords 结构化信息不足时用于识别"已存在"的关键词
+	existsKeywords []string
 }
 
 // ClientConfig 客户端配置
@@ -43,6 +48,8 @@ type ClientConfig struct {
 	MaxRetries   int  // 最大重试次数
 	DryRun       bool // 干跑模式
 	TokenRefresh int  // Token 刷新间隔（小时）
+	// ExistsKeywords "已存在"兜底关键词，为空时使用 DefaultExistsKeywords
+	ExistsKeywords []string
 }
 
 // GetToken 获取当前 Token
@@ -64,6 +71,11 @@ func NewClient(cfg ClientConfig, ctx context.Context) (*Client, error) {
 		return nil, fmt.Errorf("initial token fetch: %w", err)
 	}
 
+	existsKeywords := cfg.ExistsKeywords
+	if len(existsKeywords) == 0 {
+		existsKeywords = DefaultExistsKeywords
+	}
+
 	client := &Client{
 		baseURL:      cfg.BaseURL,
 		tokenManager: tokenManager,
@@ -71,9 +83,10 @@ func NewClient(cfg ClientConfig, ctx context.Context) (*Client, error) {
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		limiter:    limiter,
-		maxRetries: cfg.MaxRetries,
-		dryRun:     cfg.DryRun,
+		limiter:        limiter,
+		maxRetries:     cfg.MaxRetries,
+		dryRun:         cfg.DryRun,
+		existsKeywords: existsKeywords,
 	}
 
 	return client, nil
@@ -211,6 +224,292 @@ func (c *Client) doSubscribe(ctx context.Context, req *SubscribeRequest) (*Subsc
 	return &response, nil
 }
 
+// GetSubscribe 获取订阅详情
+// 订阅不存在时返回 nil, nil
+func (c *Client) GetSubscribe(ctx context.Context, subscribeID int) (*Subscription, error) {
+	status, respBody, err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/subscribe/%d", subscribeID), nil)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSubscription(status, respBody)
+}
+
+// ListSubscribes 列出 MP 中的全部订阅
+func (c *Client) ListSubscribes(ctx context.Context) ([]Subscription, error) {
+	// 注意末尾的斜杠
+	status, respBody, err := c.do(ctx, "GET", "/api/v1/subscribe/", nil)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d: %s", status, string(respBody))
+	}
+
+	// MP API 直接返回数组
+	var subs []Subscription
+	if err := json.Unmarshal(respBody, &subs); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w (body: %s)", err, string(respBody))
+	}
+
+	return subs, nil
+}
+
+// GetSubscribeByMedia 按 TMDB ID 和季号查询订阅
+// 订阅不存在时返回 nil, nil
+func (c *Client) GetSubscribeByMedia(ctx context.Context, tmdbID, season int) (*Subscription, error) {
+	path := fmt.Sprintf("/api/v1/subscribe/media/tmdb:%d", tmdbID)
+	if season > 0 {
+		path += fmt.Sprintf("?season=%d", season)
+	}
+
+	status, respBody, err := c.do(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSubscription(status, respBody)
+}
+
+// decodeSubscription 解析订阅详情响应，订阅不存在时返回 nil, nil
+func decodeSubscription(status int, respBody []byte) (*Subscription, error) {
+	if status == http.StatusNotFound {
+		return nil, nil
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d: %s", status, string(respBody))
+	}
+
+	// 订阅不存在时 MP 返回 null 或没有 id 的空对象
+	trimmed := bytes.TrimSpace(respBody)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil, nil
+	}
+
+	var sub Subscription
+	if err := json.Unmarshal(respBody, &sub); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w (body: %s)", err, string(respBody))
+	}
+	if sub.ID == 0 {
+		return nil, nil
+	}
+
+	return &sub, nil
+}
+
+// SearchSubscribe 触发 MP 对单个订阅立即搜索资源
+func (c *Client) SearchSubscribe(ctx context.Context, subscribeID int) error {
+	if c.dryRun {
+		fmt.Printf("[DRY-RUN] Would search subscription %d\n", subscribeID)
+		return nil
+	}
+
+	status, respBody, err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/subscribe/search/%d", subscribeID), nil)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusNotFound {
+		return ErrSubscribeNotFound
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d: %s", status, string(respBody))
+	}
+
+	return checkResponse(respBody)
+}
+
+// RecognizeMedia 让 MP 识别标题对应的媒体
+// 无法识别时返回 nil, nil
+func (c *Client) RecognizeMedia(ctx context.Context, title string) (*RecognizedMedia, error) {
+	path := "/api/v1/media/recognize?title=" + url.QueryEscape(title)
+	status, respBody, err := c.do(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d: %s", status, string(respBody))
+	}
+
+	var response struct {
+		MediaInfo *RecognizedMedia `json:"media_info"`
+	}
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w (body: %s)", err, string(respBody))
+	}
+	if response.MediaInfo == nil || response.MediaInfo.TMDBID == 0 {
+		return nil, nil
+	}
+
+	return response.MediaInfo, nil
+}
+
+// GetDownloading 获取下载器中正在下载的任务
+// 下载完成（做种）的任务不会出现在列表中
+func (c *Client) GetDownloading(ctx context.Context) ([]DownloadingTorrent, error) {
+	status, respBody, err := c.do(ctx, "GET", "/api/v1/download/", nil)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d: %s", status, string(respBody))
+	}
+
+	var torrents []DownloadingTorrent
+	if err := json.Unmarshal(respBody, &torrents); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w (body: %s)", err, string(respBody))
+	}
+
+	return torrents, nil
+}
+
+// DeleteDownload 让 MP 从下载器中删除种子（同时删除已下载的文件）
+// 之后再触发订阅搜索，MP 会选择其他资源
+func (c *Client) DeleteDownload(ctx context.Context, hash string) error {
+	if c.dryRun {
+		fmt.Printf("[DRY-RUN] Would delete download %s\n", hash)
+		return nil
+	}
+
+	status, respBody, err := c.do(ctx, "DELETE", "/api/v1/download/"+url.PathEscape(hash), nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d: %s", status, string(respBody))
+	}
+
+	return checkResponse(respBody)
+}
+
+// ErrSubscribeNotFound MP 中不存在该订阅
+var ErrSubscribeNotFound = errors.New("subscribe not found")
+
+// DeleteSubscribe 删除订阅
+// 订阅已不存在时返回 ErrSubscribeNotFound
+func (c *Client) DeleteSubscribe(ctx context.Context, subscribeID int) error {
+	if c.dryRun {
+		fmt.Printf("[DRY-RUN] Would delete subscription %d\n", subscribeID)
+		return nil
+	}
+
+	status, respBody, err := c.do(ctx, "DELETE", fmt.Sprintf("/api/v1/subscribe/%d", subscribeID), nil)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusNotFound {
+		return ErrSubscribeNotFound
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d: %s", status, string(respBody))
+	}
+
+	return checkResponse(respBody)
+}
+
+// SetBestVersion 开启或关闭订阅的洗版模式
+// MP 的更新接口需要完整的订阅数据，因此先读取原始 JSON，仅修改 best_version 后回写
+func (c *Client) SetBestVersion(ctx context.Context, subscribeID int, enabled bool) error {
+	if c.dryRun {
+		fmt.Printf("[DRY-RUN] Would set best_version=%v for subscription %d\n", enabled, subscribeID)
+		return nil
+	}
+
+	status, respBody, err := c.do(ctx, "GET", fmt.Sprintf("/api/v1/subscribe/%d", subscribeID), nil)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusNotFound {
+		return ErrSubscribeNotFound
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d: %s", status, string(respBody))
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(respBody, &raw); err != nil {
+		return fmt.Errorf("unmarshal response: %w (body: %s)", err, string(respBody))
+	}
+	if len(raw) == 0 || raw["id"] == nil {
+		return ErrSubscribeNotFound
+	}
+
+	if enabled {
+		raw["best_version"] = 1
+	} else {
+		raw["best_version"] = 0
+	}
+
+	status, respBody, err = c.do(ctx, "PUT", "/api/v1/subscribe/", raw)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d: %s", status, string(respBody))
+	}
+
+	return checkResponse(respBody)
+}
+
+// do 发送带认证的 JSON 请求，返回状态码和响应体
+func (c *Client) do(ctx context.Context, method, path string, payload interface{}) (int, []byte, error) {
+	// 等待速率限制
+	if err := c.limiter.Wait(ctx); err != nil {
+		return 0, nil, fmt.Errorf("rate limiter: %w", err)
+	}
+
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return 0, nil, fmt.Errorf("marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("create request: %w", err)
+	}
+
+	// 设置认证
+	if err := c.setAuth(httpReq, ctx); err != nil {
+		return 0, nil, err
+	}
+
+	httpReq.Header.Set("Accept", "application/json")
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	// 发送请求
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return 0, nil, fmt.Errorf("do request: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	// 读取响应
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("read response: %w", err)
+	}
+
+	return httpResp.StatusCode, respBody, nil
+}
+
+// checkResponse 检查 MP 通用响应中的业务状态
+func checkResponse(respBody []byte) error {
+	var response ErrorResponse
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return fmt.Errorf("unmarshal response: %w (body: %s)", err, string(respBody))
+	}
+	if !response.Success {
+		return &NonRetryableError{
+			Err:     fmt.Errorf("request failed: %s", response.Message),
+			Message: response.Message,
+		}
+	}
+	return nil
+}
+
 // SearchMedia 搜索媒体
 func (c *Client) SearchMedia(ctx context.Context, req *MediaSearchRequest) (*MediaSearchResponse, error) {
 	// 等待速率限制
@@ -343,19 +642,77 @@ type DownloadHistoryItem struct {
 
 // TransferHistoryItem 入库历史项
 type TransferHistoryItem struct {
-	ID       int    `json:"id"`
-	Title    string `json:"title"`
-	Type     string `json:"type"`              // "电影" 或 "电视剧"
-	Year     string `json:"year"`              // 年份（字符串格式）
-	TMDBID   int    `json:"tmdbid"`            // 注意：MP API 使用小写 tmdbid
-	IMDBID   string `json:"imdbid,omitempty"`
-	Seasons  string `json:"seasons,omitempty"` // "S01" 等
-	Episodes string `json:"episodes,omitempty"` // "E01-E03" 等
-	Path     string `json:"path"`
-	Dest     string `json:"dest,omitempty"`
-	Mode      string `json:"mode,omitempty"`
-	Status    string `json:"status"`        // "success", "failed"
-	CreatedAt string `json:"date"`          // MP 使用 date 字段
+	ID        int            `json:"id"`
+	Title     string         `json:"title"`
+	Type      string         `json:"type"`   // "电影" 或 "电视剧"
+	Year      string         `json:"year"`   // 年份（字符串格式）
+	TMDBID    int            `json:"tmdbid"` // 注意：MP API 使用小写 tmdbid
+	IMDBID    string         `json:"imdbid,omitempty"`
+	Seasons   string         `json:"seasons,omitempty"`  // "S01" 等
+	Episodes  string         `json:"episodes,omitempty"` // "E01-E03" 等
+	Path      string         `json:"path"`
+	Dest      string         `json:"dest,omitempty"`
+	Mode      string         `json:"mode,omitempty"`
+	Status    TransferStatus `json:"status"`           // 入库是否成功
+	ErrMsg    string         `json:"errmsg,omitempty"` // 失败原因
+	CreatedAt string         `json:"date"`             // MP 使用 date 字段
+}
+
+// TransferStatus 入库状态
+// MP 新版本返回布尔值，旧版本返回 "success"/"failed" 字符串，两种都兼容
+type TransferStatus string
+
+const (
+	TransferSuccess TransferStatus = "success"
+	TransferFailed  TransferStatus = "failed"
+)
+
+// UnmarshalJSON 兼容布尔值和字符串
+func (s *TransferStatus) UnmarshalJSON(data []byte) error {
+	var ok bool
+	if err := json.Unmarshal(data, &ok); err == nil {
+		if ok {
+			*s = TransferSuccess
+		} else {
+			*s = TransferFailed
+		}
+		return nil
+	}
+
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return fmt.Errorf("unmarshal transfer status: %w", err)
+	}
+	switch strings.ToLower(str) {
+	case "false", "0", "failed", "fail", "error":
+		*s = TransferFailed
+	default:
+		*s = TransferSuccess
+	}
+	return nil
+}
+
+// Failed 入库是否失败
+func (i *TransferHistoryItem) Failed() bool {
+	return i.Status == TransferFailed
+}
+
+// RetryTransfer 请求 MP 按入库历史记录重新整理
+func (c *Client) RetryTransfer(ctx context.Context, historyID int) error {
+	if c.dryRun {
+		fmt.Printf("[DRY-RUN] Would retry transfer for history %d\n", historyID)
+		return nil
+	}
+
+	status, respBody, err := c.do(ctx, "POST", fmt.Sprintf("/api/v1/transfer/manual?logid=%d", historyID), nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d: %s", status, string(respBody))
+	}
+
+	return checkResponse(respBody)
 }
 
 // GetDownloadHistory 获取下载历史
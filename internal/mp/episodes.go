@@ -0,0 +1,86 @@
+package mp
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// seasonEpisodePattern 匹配 "S01"、"E01" 以及 "S01-S03"、"E01-E03" 这类范围
+var seasonEpisodePattern = regexp.MustCompile(`(?i)([SE])(\d+)(?:\s*-\s*[SE]?(\d+))?`)
+
+// maxRangeSize 单个范围最多展开的编号数，超出视为无效数据
+const maxRangeSize = 1000
+
+// ParseSeasons 解析 MP 历史记录中的季字符串，如 "S01"、"S01-S03"
+func ParseSeasons(s string) []int {
+	return parseNumbers(s, "S")
+}
+
+// ParseEpisodes 解析 MP 历史记录中的集字符串，如 "E01"、"E01-E03"、"E01 E05"
+func ParseEpisodes(s string) []int {
+	return parseNumbers(s, "E")
+}
+
+// parseNumbers 解析指定前缀的编号及范围，返回去重后的升序列表
+func parseNumbers(s, prefix string) []int {
+	seen := make(map[int]bool)
+	for _, m := range seasonEpisodePattern.FindAllStringSubmatch(s, -1) {
+		if !strings.EqualFold(m[1], prefix) {
+			continue
+		}
+		start, err := strconv.Atoi(m[2])
+		if err != nil {
+			continue
+		}
+		end := start
+		if m[3] != "" {
+			// 结束小于开始或范围过大时忽略整个范围
+			n, err := strconv.Atoi(m[3])
+			if err != nil || n < start || n-start >= maxRangeSize {
+				continue
+			}
+			end = n
+		}
+		for n := start; n <= end; n++ {
+			seen[n] = true
+		}
+	}
+
+	numbers := make([]int, 0, len(seen))
+	for n := range seen {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	return numbers
+}
+
+// parseSeasonEpisodes 将季和集字符串组合为 季 -> 集 映射
+// 集为空表示整季（如季包），无法确定具体集数
+func parseSeasonEpisodes(seasons, episodes string) map[int][]int {
+	result := make(map[int][]int)
+	seasonList := ParseSeasons(seasons)
+	episodeList := ParseEpisodes(episodes)
+
+	// 多季时 MP 不会给出集号，按整季处理
+	if len(seasonList) != 1 {
+		for _, season := range seasonList {
+			result[season] = nil
+		}
+		return result
+	}
+
+	result[seasonList[0]] = episodeList
+	return result
+}
+
+// SeasonEpisodes 返回下载记录涉及的 季 -> 集 映射
+func (i *DownloadHistoryItem) SeasonEpisodes() map[int][]int {
+	return parseSeasonEpisodes(i.Seasons, i.Episodes)
+}
+
+// SeasonEpisodes 返回入库记录涉及的 季 -> 集 映射
+func (i *TransferHistoryItem) SeasonEpisodes() map[int][]int {
+	return parseSeasonEpisodes(i.Seasons, i.Episodes)
+}
@@ -0,0 +1,65 @@
+package mp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSeasons(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []int
+	}{
+		{"", []int{}},
+		{"S01", []int{1}},
+		{"S01-S03", []int{1, 2, 3}},
+		{"S1-3", []int{1, 2, 3}},
+		{"S02 S04", []int{2, 4}},
+		{"E01", []int{}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseSeasons(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseSeasons(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseEpisodes(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []int
+	}{
+		{"", []int{}},
+		{"E01", []int{1}},
+		{"E01-E03", []int{1, 2, 3}},
+		{"E09-E10", []int{9, 10}},
+		{"E01 E05", []int{1, 5}},
+		{"E03-E03", []int{3}},
+		{"e07", []int{7}},
+		{"E05-E03", []int{}},
+		{"E01-E99999999", []int{}},
+		{"E01-E99999999 E12", []int{12}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseEpisodes(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseEpisodes(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSeasonEpisodes(t *testing.T) {
+	item := &TransferHistoryItem{Seasons: "S02", Episodes: "E01-E03"}
+	want := map[int][]int{2: {1, 2, 3}}
+	if got := item.SeasonEpisodes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SeasonEpisodes() = %v, want %v", got, want)
+	}
+
+	// 多季季包没有集号
+	pack := &DownloadHistoryItem{Seasons: "S01-S02"}
+	want = map[int][]int{1: nil, 2: nil}
+	if got := pack.SeasonEpisodes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SeasonEpisodes() = %v, want %v", got, want)
+	}
+}
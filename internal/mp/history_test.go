@@ -0,0 +1,28 @@
+package mp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTransferStatusUnmarshal(t *testing.T) {
+	tests := []struct {
+		body   string
+		failed bool
+	}{
+		{`{"id": 1, "status": true}`, false},
+		{`{"id": 2, "status": false, "errmsg": "未识别到媒体信息"}`, true},
+		{`{"id": 3, "status": "success"}`, false},
+		{`{"id": 4, "status": "failed"}`, true},
+	}
+
+	for _, tt := range tests {
+		var item TransferHistoryItem
+		if err := json.Unmarshal([]byte(tt.body), &item); err != nil {
+			t.Fatalf("unmarshal %s: %v", tt.body, err)
+		}
+		if item.Failed() != tt.failed {
+			t.Errorf("%s: Failed() = %v, want %v", tt.body, item.Failed(), tt.failed)
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package mp
 
+import (
+	"strings"
+	"time"
+)
+
 // SubscribeRequest 订阅请求
 type SubscribeRequest struct {
 	Name        string `json:"name,omitempty"`         // 媒体名称
@@ -21,35 +26,22 @@ type SubscribeResponse struct {
 	Code    int            `json:"code,omitempty"`
 }
 
-// IsAlreadyExists 判断是否为"已存在"响应
-func (r *SubscribeResponse) IsAlreadyExists() bool {
+// DefaultExistsKeywords 默认的"已存在"关键词，仅在结构化信息不足时兜底使用
+var DefaultExistsKeywords = []string{
+	"已完成订阅",
+	"已存在",
+	"已在媒体库",
+	"already exists",
+	"already in library",
+}
+
+// MatchesKeywords 判断响应消息是否包含任一关键词
+func (r *SubscribeResponse) MatchesKeywords(keywords []string) bool {
 	if !r.Success || r.Message == "" {
 		return false
 	}
-	// 检查消息中是否包含"已存在"、"已完成"、"已在媒体库"等关键词
-	keywords := []string{
-		"已完成订阅",
-		"已存在",
-		"已在媒体库",
-		"already exists",
-		"already in library",
-	}
 	for _, keyword := range keywords {
-		if contains(r.Message, keyword) {
-			return true
-		}
-	}
-	return false
-}
-
-// 辅助函数
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || findSubstring(s, substr))
-}
-
-func findSubstring(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
+		if keyword != "" && strings.Contains(r.Message, keyword) {
 			return true
 		}
 	}
@@ -95,3 +87,81 @@ type ErrorResponse struct {
 	Message string `json:"message"`
 	Code    int    `json:"code,omitempty"`
 }
+
+// Subscription MoviePilot 订阅详情（GET /api/v1/subscribe/{id}）
+type Subscription struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Year         string `json:"year"`
+	Type         string `json:"type"` // "电影" 或 "电视剧"
+	TMDBID       int    `json:"tmdbid"`
+	Season       int    `json:"season,omitempty"`
+	Poster       string `json:"poster,omitempty"`
+	State        string `json:"state"`                   // N: 新建, R: 订阅中, P: 待定, S: 暂停
+	TotalEpisode int    `json:"total_episode,omitempty"` // 总集数
+	StartEpisode int    `json:"start_episode,omitempty"` // 开始集数
+	LackEpisode  int    `json:"lack_episode,omitempty"`  // 缺失集数
+	BestVersion  int    `json:"best_version,omitempty"`  // 洗版：0 或 1
+	Username     string `json:"username,omitempty"`
+	Date         string `json:"date,omitempty"`        // 创建时间 "2025-01-21 01:36:41"
+	LastUpdate   string `json:"last_update,omitempty"` // 最后更新时间
+}
+
+// 订阅状态
+const (
+	SubscribeStateNew     = "N" // 新建
+	SubscribeStateRunning = "R" // 订阅中
+	SubscribeStatePending = "P" // 待定
+	SubscribeStatePaused  = "S" // 暂停
+)
+
+// IsTV 是否为剧集订阅
+func (s *Subscription) IsTV() bool {
+	return s.Type == "电视剧"
+}
+
+// CreatedAt 解析创建时间（MP 使用本地时间格式）
+func (s *Subscription) CreatedAt() (time.Time, bool) {
+	if s.Date == "" {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", s.Date, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// DownloadingTorrent MP 下载器中正在下载的任务（GET /api/v1/download/）
+type DownloadingTorrent struct {
+	Downloader    string  `json:"downloader,omitempty"`
+	Hash          string  `json:"hash"`
+	Title         string  `json:"title"`
+	Name          string  `json:"name"`
+	Year          string  `json:"year,omitempty"`
+	SeasonEpisode string  `json:"season_episode,omitempty"` // "S01 E01-E03" 等
+	Size          float64 `json:"size"`                     // 字节
+	Progress      float64 `json:"progress"`                 // 0-100
+	State         string  `json:"state"`                    // downloading, paused
+	DLSpeed       string  `json:"dlspeed,omitempty"`        // 已格式化，如 "2.5M"
+	UPSpeed       string  `json:"upspeed,omitempty"`
+	LeftTime      string  `json:"left_time,omitempty"` // 已格式化的剩余时间
+	Media         struct {
+		TMDBID int    `json:"tmdbid"`
+		Type   string `json:"type"` // "电影" 或 "电视剧"
+		Title  string `json:"title"`
+	} `json:"media"`
+}
+
+// RecognizedMedia MP 识别出的媒体信息（GET /api/v1/media/recognize 的 media_info）
+type RecognizedMedia struct {
+	TMDBID int    `json:"tmdb_id"`
+	Type   string `json:"type"` // "电影" 或 "电视剧"
+	Title  string `json:"title"`
+	Year   string `json:"year"`
+}
+
+// IsTV 是否为剧集
+func (m *RecognizedMedia) IsTV() bool {
+	return m.Type == "电视剧"
+}
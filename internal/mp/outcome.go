@@ -0,0 +1,119 @@
+package mp
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// SubscribeOutcome 订阅结果分类
+type SubscribeOutcome string
+
+const (
+	OutcomeCreated   SubscribeOutcome = "created"   // 新建了订阅
+	OutcomeDuplicate SubscribeOutcome = "duplicate" // MP 中已有相同订阅，沿用该订阅
+	OutcomeExists    SubscribeOutcome = "exists"    // 媒体已在库中或订阅已完成
+)
+
+// createdSkew 判断订阅是否为本次新建时允许的时钟误差
+const createdSkew = 2 * time.Minute
+
+// SubscribeResult 订阅结果
+type SubscribeResult struct {
+	Outcome      SubscribeOutcome
+	SubscribeID  int
+	Subscription *Subscription // 跟进查询到的订阅详情，可能为 nil
+	Reason       string        // 分类依据，便于排查
+}
+
+// ClassifySubscribe 根据 MP 的结构化数据判断订阅结果
+// 依次使用响应码、返回的订阅 ID、订阅详情（状态、缺失集数、创建时间），
+// 只有结构化信息不足时才回退到关键词匹配
+func (c *Client) ClassifySubscribe(ctx context.Context, resp *SubscribeResponse, sentAt time.Time) *SubscribeResult {
+	result := &SubscribeResult{Outcome: OutcomeCreated}
+	if resp == nil {
+		result.Reason = "empty response"
+		return result
+	}
+	if resp.Data != nil {
+		result.SubscribeID = resp.Data.ID
+		if result.SubscribeID == 0 {
+			result.SubscribeID = resp.Data.SubscribeID
+		}
+	}
+
+	// 干跑模式不查询 MP
+	if c.dryRun {
+		result.Reason = "dry-run"
+		return result
+	}
+
+	if resp.Code == http.StatusConflict {
+		result.Outcome = OutcomeDuplicate
+		result.Reason = "response code 409"
+		return result
+	}
+
+	// 没有订阅 ID：MP 未创建订阅，只能依赖消息判断
+	if result.SubscribeID == 0 {
+		return c.classifyByKeywords(resp, result, "no subscribe id")
+	}
+
+	sub, err := c.GetSubscribe(ctx, result.SubscribeID)
+	if err != nil {
+		return c.classifyByKeywords(resp, result, "get subscribe failed: "+err.Error())
+	}
+
+	return classifySubscription(resp, result, sub, sentAt, c.existsKeywords)
+}
+
+// classifySubscription 根据跟进查询的订阅详情分类
+func classifySubscription(resp *SubscribeResponse, result *SubscribeResult, sub *Subscription, sentAt time.Time, keywords []string) *SubscribeResult {
+	result.Subscription = sub
+
+	// 返回了 ID 但订阅已不存在：MP 在创建后立即完成并删除了订阅
+	if sub == nil {
+		result.Outcome = OutcomeExists
+		result.Reason = "subscription completed and removed"
+		return result
+	}
+
+	// 剧集没有缺失集：媒体库中已完整
+	if sub.IsTV() && sub.TotalEpisode > 0 && sub.LackEpisode == 0 {
+		result.Outcome = OutcomeExists
+		result.Reason = "no lacking episodes"
+		return result
+	}
+
+	// 订阅创建时间早于本次请求：沿用已有订阅
+	if createdAt, ok := sub.CreatedAt(); ok {
+		if createdAt.Before(sentAt.Add(-createdSkew)) {
+			result.Outcome = OutcomeDuplicate
+			result.Reason = "subscription created at " + sub.Date
+			return result
+		}
+		result.Outcome = OutcomeCreated
+		result.Reason = "subscription created at " + sub.Date
+		return result
+	}
+
+	// 订阅详情缺少创建时间，回退到关键词
+	if resp.MatchesKeywords(keywords) {
+		result.Outcome = OutcomeDuplicate
+		result.Reason = "keyword match with active subscription"
+		return result
+	}
+	result.Reason = "active subscription"
+	return result
+}
+
+// classifyByKeywords 关键词兜底分类
+func (c *Client) classifyByKeywords(resp *SubscribeResponse, result *SubscribeResult, reason string) *SubscribeResult {
+	if resp.MatchesKeywords(c.existsKeywords) {
+		result.Outcome = OutcomeExists
+		result.Reason = reason + ", keyword match"
+		return result
+	}
+	result.Reason = reason
+	return result
+}
@@ -0,0 +1,166 @@
+package mp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// loadFixture 读取 testdata 中的响应样本
+func loadFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture %s: %v", name, err)
+	}
+	// 样本中的 __NOW__ 替换为当前时间，模拟刚刚创建的订阅
+	now := time.Now().Format("2006-01-02 15:04:05")
+	return []byte(strings.ReplaceAll(string(data), "__NOW__", now))
+}
+
+// newTestClient 创建连接到测试服务器的客户端
+// details 为订阅 ID 路径到响应体的映射，未命中的返回 404
+func newTestClient(t *testing.T, details map[string][]byte, keywords []string) *Client {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/login/access-token", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"access_token": "test-token", "token_type": "bearer"}`))
+	})
+	mux.HandleFunc("/api/v1/subscribe/", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, ok := details[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(body)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(ClientConfig{
+		BaseURL:        server.URL,
+		Username:       "admin",
+		Password:       "password",
+		AuthScheme:     "bearer",
+		RateLimitPS:    100,
+		ExistsKeywords: keywords,
+	}, context.Background())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return client
+}
+
+func TestClassifySubscribe(t *testing.T) {
+	tests := []struct {
+		name        string
+		response    string
+		details     map[string]string
+		wantOutcome SubscribeOutcome
+		wantID      int
+	}{
+		{
+			name:        "new subscription",
+			response:    "subscribe_created.json",
+			details:     map[string]string{"/api/v1/subscribe/128": "subscribe_detail_128.json"},
+			wantOutcome: OutcomeCreated,
+			wantID:      128,
+		},
+		{
+			name:        "existing active subscription",
+			response:    "subscribe_duplicate.json",
+			details:     map[string]string{"/api/v1/subscribe/57": "subscribe_detail_57.json"},
+			wantOutcome: OutcomeDuplicate,
+			wantID:      57,
+		},
+		{
+			name:        "subscription completed and removed",
+			response:    "subscribe_completed.json",
+			details:     map[string]string{},
+			wantOutcome: OutcomeExists,
+			wantID:      131,
+		},
+		{
+			name:        "tv subscription without lacking episodes",
+			response:    "subscribe_created.json",
+			details:     map[string]string{"/api/v1/subscribe/128": "subscribe_detail_complete_tv.json"},
+			wantOutcome: OutcomeExists,
+			wantID:      128,
+		},
+		{
+			name:        "no id falls back to keywords",
+			response:    "subscribe_in_library_no_id.json",
+			details:     map[string]string{},
+			wantOutcome: OutcomeExists,
+		},
+		{
+			name:        "reworded message without structured data",
+			response:    "subscribe_reworded_no_id.json",
+			details:     map[string]string{},
+			wantOutcome: OutcomeCreated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details := make(map[string][]byte)
+			for path, fixture := range tt.details {
+				details[path] = loadFixture(t, fixture)
+			}
+			client := newTestClient(t, details, nil)
+
+			var resp SubscribeResponse
+			if err := json.Unmarshal(loadFixture(t, tt.response), &resp); err != nil {
+				t.Fatalf("unmarshal response: %v", err)
+			}
+
+			result := client.ClassifySubscribe(context.Background(), &resp, time.Now())
+			if result.Outcome != tt.wantOutcome {
+				t.Errorf("Outcome = %v, want %v (reason: %s)", result.Outcome, tt.wantOutcome, result.Reason)
+			}
+			if result.SubscribeID != tt.wantID {
+				t.Errorf("SubscribeID = %d, want %d", result.SubscribeID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestClassifySubscribeCustomKeywords(t *testing.T) {
+	client := newTestClient(t, map[string][]byte{}, []string{"媒体库中已有"})
+
+	var resp SubscribeResponse
+	if err := json.Unmarshal(loadFixture(t, "subscribe_reworded_no_id.json"), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+
+	result := client.ClassifySubscribe(context.Background(), &resp, time.Now())
+	if result.Outcome != OutcomeExists {
+		t.Errorf("Outcome = %v, want %v", result.Outcome, OutcomeExists)
+	}
+}
+
+func TestClassifySubscribeNullDetail(t *testing.T) {
+	client := newTestClient(t, map[string][]byte{
+		"/api/v1/subscribe/128": []byte("null"),
+	}, nil)
+
+	var resp SubscribeResponse
+	if err := json.Unmarshal(loadFixture(t, "subscribe_created.json"), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+
+	result := client.ClassifySubscribe(context.Background(), &resp, time.Now())
+	if result.Outcome != OutcomeExists {
+		t.Errorf("Outcome = %v, want %v", result.Outcome, OutcomeExists)
+	}
+}
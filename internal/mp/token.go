@@ -127,3 +127,86 @@ func (tm *TokenManager) SetToken(token string) {
 	defer tm.mu.Unlock()
 	tm.token = token
 }
+
+// ResourceTokenCookie MP 资源令牌 Cookie 名称（即 PROJECT_NAME）
+// SSE 消息、图片等资源接口只接受该 Cookie，不接受 Bearer Token
+const ResourceTokenCookie = "MoviePilot"
+
+// defaultResourceTokenTTL Cookie 未声明有效期时假定的有效期
+const defaultResourceTokenTTL = 30 * time.Minute
+
+// ResourceToken MP 资源令牌
+type ResourceToken struct {
+	Value     string
+	ExpiresAt time.Time
+}
+
+// Expired 是否已过期（预留 1 分钟余量）
+func (t *ResourceToken) Expired() bool {
+	return t == nil || time.Now().Add(time.Minute).After(t.ExpiresAt)
+}
+
+// GetResourceToken 获取资源令牌
+// 与 Web 端一致：带 Bearer Token 访问任意认证接口时，MP 会通过 Set-Cookie 下发资源令牌
+func (c *Client) GetResourceToken(ctx context.Context) (*ResourceToken, error) {
+	resp, err := c.fetchResourceToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		// 访问令牌过期，重新登录后再试一次
+		if _, err := c.tokenManager.RefreshToken(ctx); err != nil {
+			return nil, fmt.Errorf("refresh token: %w", err)
+		}
+		if resp, err = c.fetchResourceToken(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name != ResourceTokenCookie || cookie.Value == "" {
+			continue
+		}
+		token := &ResourceToken{Value: cookie.Value}
+		switch {
+		case cookie.MaxAge > 0:
+			token.ExpiresAt = time.Now().Add(time.Duration(cookie.MaxAge) * time.Second)
+		case !cookie.Expires.IsZero():
+			token.ExpiresAt = cookie.Expires
+		default:
+			token.ExpiresAt = time.Now().Add(defaultResourceTokenTTL)
+		}
+		return token, nil
+	}
+
+	return nil, fmt.Errorf("response did not set %s cookie", ResourceTokenCookie)
+}
+
+// fetchResourceToken 请求当前用户信息以获取 Set-Cookie
+func (c *Client) fetchResourceToken(ctx context.Context) (*http.Response, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limiter: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/api/v1/user/current", nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	if err := c.setAuth(req, ctx); err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	// 只需要响应头
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	return resp, nil
+}
@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// channel 通知渠道及其接收的事件
+type channel struct {
+	notifier Notifier
+	events   map[Event]bool // 为空表示接收所有事件
+}
+
+// accepts 检查渠道是否接收该事件
+func (c *channel) accepts(event Event) bool {
+	return len(c.events) == 0 || c.events[event]
+}
+
+// Mux 将通知分发到多个渠道，每个渠道可以只接收部分事件
+type Mux struct {
+	logger *zap.Logger
+
+	mu       sync.RWMutex
+	channels []channel
+}
+
+// NewMux 创建通知分发器
+func NewMux(logger *zap.Logger) *Mux {
+	return &Mux{logger: logger}
+}
+
+// Add 添加通知渠道，events 为空表示接收所有事件
+func (m *Mux) Add(notifier Notifier, events []Event) {
+	ch := channel{notifier: notifier}
+	if len(events) > 0 {
+		ch.events = make(map[Event]bool, len(events))
+		for _, event := range events {
+			ch.events[event] = true
+		}
+	}
+
+	m.mu.Lock()
+	m.channels = append(m.channels, ch)
+	m.mu.Unlock()
+
+	m.logger.Info("Notification channel enabled",
+		zap.String("channel", notifier.Name()),
+		zap.Int("event_filter", len(events)),
+	)
+}
+
+// Len 返回已添加的渠道数
+func (m *Mux) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.channels)
+}
+
+// Name 实现 Notifier
+func (m *Mux) Name() string {
+	return "mux"
+}
+
+// Notify 实现 Notifier，发送给所有接收该事件的渠道
+func (m *Mux) Notify(n *Notification) {
+	if n.Time.IsZero() {
+		n.Time = time.Now()
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for i := range m.channels {
+		ch := &m.channels[i]
+		if !ch.accepts(n.Event) {
+			continue
+		}
+		m.logger.Debug("Sending notification",
+			zap.String("channel", ch.notifier.Name()),
+			zap.String("event", string(n.Event)),
+			zap.String("title", n.Title),
+		)
+		ch.notifier.Notify(n)
+	}
+}
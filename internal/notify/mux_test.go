@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// recorder 记录收到的通知
+type recorder struct {
+	name   string
+	events []Event
+}
+
+func (r *recorder) Name() string { return r.name }
+
+func (r *recorder) Notify(n *Notification) { r.events = append(r.events, n.Event) }
+
+func TestMux(t *testing.T) {
+	all := &recorder{name: "all"}
+	failures := &recorder{name: "failures"}
+
+	mux := NewMux(zap.NewNop())
+	mux.Add(all, nil)
+	mux.Add(failures, []Event{EventFailed, EventError})
+	if mux.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", mux.Len())
+	}
+
+	n := &Notification{Event: EventSubscribed, Title: "测试"}
+	mux.Notify(n)
+	mux.Notify(&Notification{Event: EventFailed, Title: "测试"})
+
+	if n.Time.IsZero() {
+		t.Error("expected notification time to be set")
+	}
+	if len(all.events) != 2 {
+		t.Errorf("unfiltered channel got %v", all.events)
+	}
+	if len(failures.events) != 1 || failures.events[0] != EventFailed {
+		t.Errorf("filtered channel got %v", failures.events)
+	}
+}
+
+func TestParseEvents(t *testing.T) {
+	events, err := ParseEvents([]string{" failed", "", "report "})
+	if err != nil {
+		t.Fatalf("ParseEvents() error = %v", err)
+	}
+	if len(events) != 2 || events[0] != EventFailed || events[1] != EventReport {
+		t.Errorf("ParseEvents() = %v", events)
+	}
+
+	if _, err := ParseEvents([]string{"downloaded"}); err == nil {
+		t.Error("expected error for unknown event")
+	}
+}
@@ -0,0 +1,128 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Event 通知事件类型
+type Event string
+
+const (
+	EventSubscribed          Event = "subscribed"           // 订阅成功
+	EventAlreadyExists       Event = "already_exists"       // 订阅时已在媒体库中
+	EventResourceFound       Event = "resource_found"       // MP 已找到资源
+	EventDownloadStarted     Event = "download_started"     // 开始下载
+	EventDownloadProgress    Event = "download_progress"    // 下载进度
+	EventDownloadComplete    Event = "download_complete"    // 下载完成
+	EventTransferred         Event = "transferred"          // 入库成功
+	EventAvailable           Event = "available"            // 媒体服务器中可观看
+	EventEpisodesTransferred Event = "episodes_transferred" // 剧集新集入库
+	EventEpisodesLate        Event = "episodes_late"        // 已播出的集超时未下载
+	EventTorrentUnhealthy    Event = "torrent_unhealthy"    // 种子停滞、无做种者或出错
+	EventFailed              Event = "failed"               // 失败
+	EventRetrying            Event = "retrying"             // 智能重试
+	EventDrift               Event = "drift"                // MP 订阅与本地状态不一致
+	EventReport              Event = "report"               // 每日报告
+	EventError               Event = "error"                // 系统错误
+)
+
+// Events 所有通知事件类型
+var Events = []Event{
+	EventSubscribed, EventAlreadyExists, EventResourceFound,
+	EventDownloadStarted, EventDownloadProgress, EventDownloadComplete,
+	EventTransferred, EventAvailable, EventEpisodesTransferred, EventEpisodesLate,
+	EventTorrentUnhealthy, EventFailed, EventRetrying, EventDrift,
+	EventReport, EventError,
+}
+
+// Notification 一条通知，各字段按事件类型填写
+type Notification struct {
+	Event           Event     `json:"event"`
+	Time            time.Time `json:"time"`
+	SourceRequestID string    `json:"source_request_id,omitempty"`
+	Title           string    `json:"title,omitempty"`
+	MediaType       string    `json:"media_type,omitempty"`
+	TMDBID          int       `json:"tmdb_id,omitempty"`
+	PosterPath      string    `json:"poster_path,omitempty"`
+	Status          string    `json:"status,omitempty"`     // 跟踪状态
+	Timestamps      *Times    `json:"timestamps,omitempty"` // 请求生命周期各阶段的时间
+
+	Reason string `json:"reason,omitempty"` // 失败原因、异常原因或错误信息
+	Action string `json:"action,omitempty"` // 订阅状态异常的处理方式
+
+	Progress float64 `json:"progress,omitempty"` // 下载进度（0-100）
+	Speed    string  `json:"speed,omitempty"`
+	ETA      string  `json:"eta,omitempty"`
+
+	Episodes        string `json:"episodes,omitempty"`         // 本次入库或延迟的集，如 "S03E05"
+	EpisodeProgress string `json:"episode_progress,omitempty"` // 剧集入库进度，如 "5/10"
+	Missing         string `json:"missing,omitempty"`          // 已播出但尚未入库的集
+	LateHours       int    `json:"late_hours,omitempty"`
+
+	Torrent  string `json:"torrent,omitempty"`
+	Replaced bool   `json:"replaced,omitempty"` // 已让 MP 删除种子并重新搜索
+
+	Attempt     int `json:"attempt,omitempty"`
+	MaxAttempts int `json:"max_attempts,omitempty"`
+
+	Username string `json:"username,omitempty"` // MP 通知中的用户
+	Report   string `json:"report,omitempty"`   // 每日报告正文（Telegram HTML 格式）
+}
+
+// Times 请求生命周期各阶段的时间，未到达的阶段为空
+type Times struct {
+	Subscribed       *time.Time `json:"subscribed_at,omitempty"`
+	DownloadStarted  *time.Time `json:"download_started_at,omitempty"`
+	DownloadFinished *time.Time `json:"download_finished_at,omitempty"`
+	Transferred      *time.Time `json:"transferred_at,omitempty"`
+	Available        *time.Time `json:"available_at,omitempty"`
+}
+
+// Notifier 通知渠道
+// Notify 不应阻塞调用方，耗时的发送需要在实现中异步进行
+type Notifier interface {
+	Name() string
+	Notify(n *Notification)
+}
+
+// ParseEvents 解析配置中的事件列表，空列表表示接收所有事件
+func ParseEvents(values []string) ([]Event, error) {
+	var events []Event
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		event := Event(value)
+		if !isKnown(event) {
+			return nil, fmt.Errorf("unknown notification event: %s", value)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// isKnown 检查事件类型是否存在
+func isKnown(event Event) bool {
+	for _, known := range Events {
+		if known == event {
+			return true
+		}
+	}
+	return false
+}
+
+// SleepContext 等待指定时间，上下文取消时返回 false
+func SleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,149 @@
+package notify
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// PosterBaseURL TMDB 海报地址前缀
+const PosterBaseURL = "https://image.tmdb.org/t/p/w500"
+
+// Message 纯文本通知内容，与 Telegram 消息的文案一致，供不支持 HTML 的渠道使用
+type Message struct {
+	Icon    string   // 标题前的 emoji
+	Heading string   // 标题，如 "已自动订阅"
+	Lines   []string // 正文各行
+	Footer  string   // 通知时间，如 "⏰ 2024-05-01 20:30:00"，有时间戳字段的渠道可以不用
+}
+
+// Title 带 emoji 的标题
+func (m *Message) Title() string {
+	return m.Icon + " " + m.Heading
+}
+
+// Body 正文和通知时间，各行以换行分隔
+func (m *Message) Body() string {
+	lines := m.Lines
+	if m.Footer != "" {
+		lines = append(lines[:len(lines):len(lines)], m.Footer)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// htmlTag 匹配 HTML 标签，用于去掉报告正文中的 Telegram 格式
+var htmlTag = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+
+// Render 按事件类型生成纯文本通知内容
+func Render(n *Notification) *Message {
+	m := &Message{}
+	title := "📺 " + n.Title
+	switch n.Event {
+	case EventSubscribed:
+		m.Icon, m.Heading = "✅", "已自动订阅"
+		m.Lines = []string{title, "🏷️ 类型: " + MediaTypeLabel(n.MediaType), fmt.Sprintf("🆔 TMDB ID: %d", n.TMDBID)}
+	case EventAlreadyExists:
+		m.Icon, m.Heading = "ℹ️", "媒体已在库中"
+		m.Lines = []string{title, "🏷️ 类型: " + MediaTypeLabel(n.MediaType), fmt.Sprintf("🆔 TMDB ID: %d", n.TMDBID),
+			"💡 该影片已存在于媒体库，无需重复下载"}
+	case EventResourceFound:
+		m.Icon, m.Heading = "🎯", "已找到资源"
+		m.Lines = []string{title, "👤 用户: " + n.Username}
+	case EventDownloadStarted:
+		m.Icon, m.Heading = "⬇️", "开始下载"
+		m.Lines = []string{title}
+	case EventDownloadProgress:
+		m.Icon, m.Heading = "⏬", "下载进度"
+		m.Lines = []string{title, fmt.Sprintf("📊 进度: %.1f%%", n.Progress)}
+		if n.Speed != "" {
+			m.Lines = append(m.Lines, "🚀 速度: "+n.Speed)
+		}
+		if n.ETA != "" {
+			m.Lines = append(m.Lines, "⏳ 剩余: "+n.ETA)
+		}
+	case EventDownloadComplete:
+		m.Icon, m.Heading = "✅", "下载完成"
+		m.Lines = []string{title}
+	case EventTransferred:
+		m.Icon, m.Heading = "📦", "入库成功"
+		m.Lines = []string{title}
+	case EventAvailable:
+		m.Icon, m.Heading = "🍿", "可以观看了"
+		m.Lines = []string{title}
+	case EventEpisodesTransferred:
+		m.Icon, m.Heading = "🆕", n.Episodes+" 已入库"
+		m.Lines = []string{title, "📊 进度: " + n.EpisodeProgress}
+		if n.Missing != "" {
+			m.Lines = append(m.Lines, "⚠️ 缺失: "+n.Missing)
+		}
+	case EventEpisodesLate:
+		m.Icon, m.Heading = "⏰", "剧集更新延迟"
+		m.Lines = []string{title, fmt.Sprintf("🕒 %s 已播出超过 %d 小时仍未下载", n.Episodes, n.LateHours)}
+	case EventTorrentUnhealthy:
+		action := "请检查下载器或在 MP 中更换资源"
+		if n.Replaced {
+			action = "已让 MP 删除该种子并重新搜索"
+		}
+		m.Icon, m.Heading = "🩺", "种子异常"
+		m.Lines = []string{title, "🧲 " + n.Torrent, "💬 " + n.Reason, "🔧 " + action}
+	case EventFailed:
+		m.Icon, m.Heading = "❌", "订阅失败"
+		m.Lines = []string{title, "💬 原因: " + n.Reason}
+	case EventRetrying:
+		m.Icon, m.Heading = "🔄", "智能重试"
+		m.Lines = []string{title, fmt.Sprintf("🔢 尝试: %d/%d", n.Attempt, n.MaxAttempts)}
+	case EventDrift:
+		m.Icon, m.Heading = "🧭", "订阅状态异常"
+		m.Lines = []string{title, "💬 " + n.Reason, "🔧 处理: " + n.Action}
+	case EventReport:
+		m.Icon, m.Heading = "📊", "每日订阅报告"
+		m.Lines = []string{html.UnescapeString(htmlTag.ReplaceAllString(n.Report, ""))}
+		return m
+	case EventError:
+		m.Icon, m.Heading = "⚠️", "系统错误"
+		m.Lines = []string{"💬 " + n.Reason}
+	default:
+		m.Icon, m.Heading = "🔔", string(n.Event)
+		if n.Title != "" {
+			m.Lines = []string{title}
+		}
+	}
+
+	if !n.Time.IsZero() {
+		m.Footer = "⏰ " + n.Time.Format("2006-01-02 15:04:05")
+	}
+	return m
+}
+
+// PosterURL 海报图片地址，没有海报时返回空
+func PosterURL(n *Notification) string {
+	if n.PosterPath == "" {
+		return ""
+	}
+	return PosterBaseURL + n.PosterPath
+}
+
+// TMDBURL TMDB 条目页面地址，没有 TMDB ID 时返回空
+func TMDBURL(n *Notification) string {
+	if n.TMDBID == 0 {
+		return ""
+	}
+	mediaType := "movie"
+	if n.MediaType == "tv" {
+		mediaType = "tv"
+	}
+	return fmt.Sprintf("https://www.themoviedb.org/%s/%d", mediaType, n.TMDBID)
+}
+
+// MediaTypeLabel 媒体类型的中文描述
+func MediaTypeLabel(mediaType string) string {
+	switch mediaType {
+	case "movie":
+		return "🎬 电影"
+	case "tv":
+		return "📺 剧集"
+	default:
+		return mediaType
+	}
+}
@@ -0,0 +1,29 @@
+package notify
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	at := time.Date(2024, 5, 1, 20, 30, 0, 0, time.Local)
+
+	m := Render(&Notification{Event: EventTorrentUnhealthy, Time: at, Title: "沙丘", Torrent: "Dune.2160p", Reason: "下载停滞", Replaced: true})
+	want := "📺 沙丘\n🧲 Dune.2160p\n💬 下载停滞\n🔧 已让 MP 删除该种子并重新搜索\n⏰ 2024-05-01 20:30:00"
+	if m.Title() != "🩺 种子异常" || m.Body() != want {
+		t.Errorf("Render() = %q / %q", m.Title(), m.Body())
+	}
+
+	// 报告正文去掉 Telegram HTML 格式
+	m = Render(&Notification{Event: EventReport, Time: at, Report: "📅 2024-05-01\n\n⚠️ <b>需要人工处理 (1)</b>\n• Tom &amp; Jerry"})
+	if m.Body() != "📅 2024-05-01\n\n⚠️ 需要人工处理 (1)\n• Tom & Jerry" {
+		t.Errorf("Render() report = %q", m.Body())
+	}
+
+	if got := TMDBURL(&Notification{TMDBID: 1399, MediaType: "tv"}); got != "https://www.themoviedb.org/tv/1399" {
+		t.Errorf("TMDBURL() = %q", got)
+	}
+	if got := PosterURL(&Notification{}); got != "" {
+		t.Errorf("PosterURL() = %q", got)
+	}
+}
@@ -0,0 +1,87 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SaveCleanup 保存订阅清理记录
+func (s *SQLiteStore) SaveCleanup(cleanup *SubscriptionCleanup) error {
+	cleanup.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO subscription_cleanups (source_request_id, mp_subscribe_id, action, detail, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	result, err := s.db.Exec(query,
+		cleanup.SourceRequestID, cleanup.MPSubscribeID, cleanup.Action, cleanup.Detail, cleanup.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if id, err := result.LastInsertId(); err == nil {
+		cleanup.ID = id
+	}
+	return nil
+}
+
+// GetLatestCleanup 获取请求下某个订阅最近一次的清理记录
+// 剧集按季订阅，一个请求可能对应多个订阅
+func (s *SQLiteStore) GetLatestCleanup(sourceRequestID, subscribeID string) (*SubscriptionCleanup, error) {
+	query := `
+		SELECT id, source_request_id, mp_subscribe_id, action, detail, created_at
+		FROM subscription_cleanups
+		WHERE source_request_id = ? AND mp_subscribe_id = ?
+		ORDER BY id DESC
+		LIMIT 1
+	`
+
+	cleanup := &SubscriptionCleanup{}
+	var mpSubscribeID, detail sql.NullString
+	err := s.db.QueryRow(query, sourceRequestID, subscribeID).Scan(
+		&cleanup.ID, &cleanup.SourceRequestID, &mpSubscribeID, &cleanup.Action, &detail, &cleanup.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cleanup.MPSubscribeID = mpSubscribeID.String
+	cleanup.Detail = detail.String
+	return cleanup, nil
+}
+
+// ListCleanups 列出最近的清理记录（limit <= 0 表示不限制）
+func (s *SQLiteStore) ListCleanups(limit int) ([]*SubscriptionCleanup, error) {
+	query := `
+		SELECT id, source_request_id, mp_subscribe_id, action, detail, created_at
+		FROM subscription_cleanups
+		ORDER BY id DESC
+		LIMIT ?
+	`
+
+	rows, err := s.db.Query(query, sqlLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cleanups []*SubscriptionCleanup
+	for rows.Next() {
+		cleanup := &SubscriptionCleanup{}
+		var mpSubscribeID, detail sql.NullString
+		if err := rows.Scan(
+			&cleanup.ID, &cleanup.SourceRequestID, &mpSubscribeID, &cleanup.Action, &detail, &cleanup.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		cleanup.MPSubscribeID = mpSubscribeID.String
+		cleanup.Detail = detail.String
+		cleanups = append(cleanups, cleanup)
+	}
+
+	return cleanups, rows.Err()
+}
@@ -0,0 +1,64 @@
+package store
+
+import "time"
+
+// SaveEpisode 保存单集跟踪记录（按 请求+季+集 去重）
+func (s *SQLiteStore) SaveEpisode(episode *EpisodeTracking) error {
+	now := time.Now()
+	if episode.CreatedAt.IsZero() {
+		episode.CreatedAt = now
+	}
+	episode.UpdatedAt = now
+
+	query := `
+		INSERT INTO episode_tracking (
+			source_request_id, season, episode, status, download_time, transfer_time, air_date, finale, late_time, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(source_request_id, season, episode) DO UPDATE SET
+			status = excluded.status,
+			download_time = excluded.download_time,
+			transfer_time = excluded.transfer_time,
+			air_date = excluded.air_date,
+			finale = excluded.finale,
+			late_time = excluded.late_time,
+			updated_at = excluded.updated_at
+	`
+
+	_, err := s.db.Exec(query,
+		episode.SourceRequestID, episode.Season, episode.Episode, episode.Status,
+		episode.DownloadTime, episode.TransferTime, episode.AirDate, episode.Finale, episode.LateTime,
+		episode.CreatedAt, episode.UpdatedAt,
+	)
+	return err
+}
+
+// ListEpisodes 列出请求的全部单集记录，按季、集排序
+func (s *SQLiteStore) ListEpisodes(sourceRequestID string) ([]*EpisodeTracking, error) {
+	query := `
+		SELECT id, source_request_id, season, episode, status, download_time, transfer_time, air_date, finale, late_time, created_at, updated_at
+		FROM episode_tracking
+		WHERE source_request_id = ?
+		ORDER BY season ASC, episode ASC
+	`
+
+	rows, err := s.db.Query(query, sourceRequestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var episodes []*EpisodeTracking
+	for rows.Next() {
+		episode := &EpisodeTracking{}
+		if err := rows.Scan(
+			&episode.ID, &episode.SourceRequestID, &episode.Season, &episode.Episode, &episode.Status,
+			&episode.DownloadTime, &episode.TransferTime, &episode.AirDate, &episode.Finale, &episode.LateTime,
+			&episode.CreatedAt, &episode.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		episodes = append(episodes, episode)
+	}
+
+	return episodes, rows.Err()
+}
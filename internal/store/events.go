@@ -0,0 +1,294 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EventPayload 事件数据，每种 EventType 对应一个结构
+type EventPayload interface {
+	EventType() EventType
+	Base() *EventBase
+}
+
+// EventBase 所有事件共有的字段
+type EventBase struct {
+	TMDBID int            `json:"tmdb_id"`
+	Title  string         `json:"title"`
+	From   TrackingStatus `json:"from,omitempty"` // 状态转换前的状态
+	To     TrackingStatus `json:"to,omitempty"`   // 状态转换后的状态
+}
+
+// Base 返回共有字段
+func (b *EventBase) Base() *EventBase { return b }
+
+// SubscribedPayload 订阅成功
+type SubscribedPayload struct {
+	EventBase
+}
+
+// AlreadyExistsPayload 订阅时已在媒体库中
+type AlreadyExistsPayload struct {
+	EventBase
+}
+
+// DownloadStartedPayload 开始下载
+type DownloadStartedPayload struct {
+	EventBase
+}
+
+// DownloadCompletePayload 下载完成
+type DownloadCompletePayload struct {
+	EventBase
+}
+
+// TransferCompletePayload 入库完成
+type TransferCompletePayload struct {
+	EventBase
+}
+
+// FailedPayload 入库失败
+type FailedPayload struct {
+	EventBase
+	HistoryID int    `json:"history_id,omitempty"` // MP 整理记录 ID
+	Seasons   string `json:"seasons,omitempty"`
+	Episodes  string `json:"episodes,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	Retried   bool   `json:"retried"` // 是否已请求 MP 重新整理
+}
+
+// ManualSearchPayload 停滞订阅触发搜索
+type ManualSearchPayload struct {
+	EventBase
+	SubscribeID int `json:"subscribe_id"`
+	Attempt     int `json:"attempt"`
+}
+
+// AdoptedPayload 接管 MP 已有订阅
+type AdoptedPayload struct {
+	EventBase
+	SubscribeIDs  []int  `json:"subscribe_ids"`
+	SubscribeDate string `json:"subscribe_date,omitempty"`
+}
+
+// DriftDetectedPayload MP 订阅与本地状态不一致
+type DriftDetectedPayload struct {
+	EventBase
+	SubscribeID int    `json:"subscribe_id"`
+	Kind        string `json:"kind"`
+	Reason      string `json:"reason"`
+	Action      string `json:"action"`
+}
+
+// EpisodesArrivedPayload 部分剧集已入库
+type EpisodesArrivedPayload struct {
+	EventBase
+	Arrived  int              `json:"arrived"`
+	Progress []SeasonProgress `json:"progress"`
+}
+
+// PartiallyTransferredPayload 部分集已入库，仍有已播出的集缺失
+type PartiallyTransferredPayload struct {
+	EventBase
+	Missing  string           `json:"missing"`            // 已播出但未入库的集，如 "S01E03-E05"
+	Upcoming int              `json:"upcoming"`           // 尚未播出的集数
+	Progress []SeasonProgress `json:"progress,omitempty"` // 各季入库进度
+}
+
+// AiringPayload 已播出的集均已入库，等待后续集播出
+type AiringPayload struct {
+	EventBase
+	Upcoming    int              `json:"upcoming"`                // 尚未播出的集数
+	NextEpisode string           `json:"next_episode,omitempty"`  // 下一集，如 "S03E06"
+	NextAirDate *time.Time       `json:"next_air_date,omitempty"` // 下一集播出日期
+	Progress    []SeasonProgress `json:"progress,omitempty"`      // 各季入库进度
+}
+
+// EpisodesLatePayload 已播出的集超时仍未下载
+type EpisodesLatePayload struct {
+	EventBase
+	Episodes string `json:"episodes"`   // 超时的集，如 "S03E05"
+	Hours    int    `json:"late_hours"` // 超时阈值（小时）
+}
+
+// TorrentUnhealthyPayload 下载器中的种子停滞、无做种者或出错
+type TorrentUnhealthyPayload struct {
+	EventBase
+	Hash     string       `json:"hash"`
+	Name     string       `json:"name"`
+	Issue    TorrentIssue `json:"issue"`
+	Detail   string       `json:"detail,omitempty"`
+	Replaced bool         `json:"replaced,omitempty"` // 是否已请求 MP 更换资源
+}
+
+// AvailablePayload 媒体服务器中可观看
+type AvailablePayload struct {
+	EventBase
+	Waited string `json:"waited,omitempty"` // 入库到可观看的等待时间
+}
+
+// EventType 实现 EventPayload
+func (*SubscribedPayload) EventType() EventType           { return EventSubscribed }
+func (*AlreadyExistsPayload) EventType() EventType        { return EventAlreadyExists }
+func (*DownloadStartedPayload) EventType() EventType      { return EventDownloadStarted }
+func (*DownloadCompletePayload) EventType() EventType     { return EventDownloadComplete }
+func (*TransferCompletePayload) EventType() EventType     { return EventTransferComplete }
+func (*FailedPayload) EventType() EventType               { return EventFailed }
+func (*ManualSearchPayload) EventType() EventType         { return EventManualSearch }
+func (*AdoptedPayload) EventType() EventType              { return EventAdopted }
+func (*DriftDetectedPayload) EventType() EventType        { return EventDriftDetected }
+func (*EpisodesArrivedPayload) EventType() EventType      { return EventEpisodesArrived }
+func (*AvailablePayload) EventType() EventType            { return EventAvailable }
+func (*PartiallyTransferredPayload) EventType() EventType { return EventPartiallyTransferred }
+func (*AiringPayload) EventType() EventType               { return EventAiring }
+func (*EpisodesLatePayload) EventType() EventType         { return EventEpisodesLate }
+func (*TorrentUnhealthyPayload) EventType() EventType     { return EventTorrentUnhealthy }
+
+// payloadTypes 事件类型 -> 事件数据构造函数
+var payloadTypes = map[EventType]func() EventPayload{
+	EventSubscribed:           func() EventPayload { return &SubscribedPayload{} },
+	EventAlreadyExists:        func() EventPayload { return &AlreadyExistsPayload{} },
+	EventDownloadStarted:      func() EventPayload { return &DownloadStartedPayload{} },
+	EventDownloadComplete:     func() EventPayload { return &DownloadCompletePayload{} },
+	EventTransferComplete:     func() EventPayload { return &TransferCompletePayload{} },
+	EventFailed:               func() EventPayload { return &FailedPayload{} },
+	EventManualSearch:         func() EventPayload { return &ManualSearchPayload{} },
+	EventAdopted:              func() EventPayload { return &AdoptedPayload{} },
+	EventDriftDetected:        func() EventPayload { return &DriftDetectedPayload{} },
+	EventEpisodesArrived:      func() EventPayload { return &EpisodesArrivedPayload{} },
+	EventAvailable:            func() EventPayload { return &AvailablePayload{} },
+	EventPartiallyTransferred: func() EventPayload { return &PartiallyTransferredPayload{} },
+	EventAiring:               func() EventPayload { return &AiringPayload{} },
+	EventEpisodesLate:         func() EventPayload { return &EpisodesLatePayload{} },
+	EventTorrentUnhealthy:     func() EventPayload { return &TorrentUnhealthyPayload{} },
+}
+
+// NewEventPayload 创建事件类型对应的空事件数据，未知类型返回 nil
+func NewEventPayload(eventType EventType) EventPayload {
+	newPayload, ok := payloadTypes[eventType]
+	if !ok {
+		return nil
+	}
+	return newPayload()
+}
+
+// NewEvent 编码事件数据并创建事件
+func NewEvent(sourceRequestID string, payload EventPayload) (*DownloadEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s payload: %w", payload.EventType(), err)
+	}
+	return &DownloadEvent{
+		SourceRequestID: sourceRequestID,
+		EventType:       payload.EventType(),
+		EventData:       string(data),
+	}, nil
+}
+
+// Payload 按事件类型解码事件数据
+func (e *DownloadEvent) Payload() (EventPayload, error) {
+	payload := NewEventPayload(e.EventType)
+	if payload == nil {
+		return nil, fmt.Errorf("unknown event type: %s", e.EventType)
+	}
+	if e.EventData == "" {
+		return payload, nil
+	}
+	if err := json.Unmarshal([]byte(e.EventData), payload); err != nil {
+		return nil, fmt.Errorf("decode %s payload: %w", e.EventType, err)
+	}
+	return payload, nil
+}
+
+// EventQuery 事件查询条件，零值字段不参与过滤
+type EventQuery struct {
+	SourceRequestID string
+	Types           []EventType
+	Since           time.Time // 包含
+	Until           time.Time // 不包含
+	Limit           int       // <= 0 表示不限制
+	Offset          int
+	Ascending       bool // 按时间正序返回（构建时间线），默认倒序
+}
+
+// where 生成 WHERE 子句和参数
+func (q EventQuery) where() (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if q.SourceRequestID != "" {
+		conditions = append(conditions, "source_request_id = ?")
+		args = append(args, q.SourceRequestID)
+	}
+	if len(q.Types) > 0 {
+		placeholders := make([]string, len(q.Types))
+		for i, eventType := range q.Types {
+			placeholders[i] = "?"
+			args = append(args, eventType)
+		}
+		conditions = append(conditions, "event_type IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if !q.Since.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, q.Since)
+	}
+	if !q.Until.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, q.Until)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// QueryEvents 按请求、类型和时间范围分页查询事件
+func (s *SQLiteStore) QueryEvents(q EventQuery) ([]*DownloadEvent, error) {
+	where, args := q.where()
+	order := "DESC"
+	if q.Ascending {
+		order = "ASC"
+	}
+
+	query := `
+		SELECT id, source_request_id, event_type, event_data, created_at
+		FROM download_events
+		` + where + `
+		ORDER BY created_at ` + order + `, id ` + order + `
+		LIMIT ? OFFSET ?
+	`
+	args = append(args, sqlLimit(q.Limit), q.Offset)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*DownloadEvent
+	for rows.Next() {
+		event := &DownloadEvent{}
+		err := rows.Scan(&event.ID, &event.SourceRequestID, &event.EventType, &event.EventData, &event.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// CountEvents 统计符合条件的事件总数（忽略分页参数）
+func (s *SQLiteStore) CountEvents(q EventQuery) (int, error) {
+	where, args := q.where()
+
+	var count int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM download_events "+where, args...).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
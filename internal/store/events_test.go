@@ -0,0 +1,145 @@
+package store
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestEventPayloadRoundTrip(t *testing.T) {
+	// 标题中的引号和反斜杠必须被正确转义
+	title := `He said "hi" \ 你好`
+	event, err := NewEvent("test-1", &FailedPayload{
+		EventBase: EventBase{TMDBID: 42, Title: title, From: TrackingDownloaded, To: TrackingFailed},
+		Reason:    `path "/media" not found`,
+		Retried:   true,
+	})
+	if err != nil {
+		t.Fatalf("NewEvent() error = %v", err)
+	}
+	if event.EventType != EventFailed {
+		t.Errorf("Expected event type 'failed', got '%s'", event.EventType)
+	}
+	if !json.Valid([]byte(event.EventData)) {
+		t.Fatalf("Invalid JSON: %s", event.EventData)
+	}
+
+	payload, err := event.Payload()
+	if err != nil {
+		t.Fatalf("Payload() error = %v", err)
+	}
+	failed, ok := payload.(*FailedPayload)
+	if !ok {
+		t.Fatalf("Expected *FailedPayload, got %T", payload)
+	}
+	if failed.Title != title || failed.TMDBID != 42 || !failed.Retried || failed.From != TrackingDownloaded {
+		t.Errorf("Unexpected payload %+v", failed)
+	}
+
+	// 每种事件类型都有对应的结构
+	for eventType := range payloadTypes {
+		if payload := NewEventPayload(eventType); payload == nil || payload.EventType() != eventType {
+			t.Errorf("NewEventPayload(%s) = %v", eventType, payload)
+		}
+	}
+
+	if _, err := (&DownloadEvent{EventType: "unknown"}).Payload(); err == nil {
+		t.Error("Expected error for unknown event type")
+	}
+}
+
+func TestQueryEvents(t *testing.T) {
+	dbPath := "/tmp/test_query_events.db"
+	defer os.Remove(dbPath)
+
+	store, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.Local)
+	seed := []struct {
+		requestID string
+		eventType EventType
+		hours     int
+	}{
+		{"movie-1", EventSubscribed, 0},
+		{"movie-1", EventDownloadStarted, 2},
+		{"movie-1", EventTransferComplete, 5},
+		{"tv-1", EventSubscribed, 1},
+		{"tv-1", EventEpisodesArrived, 30},
+	}
+	for _, s := range seed {
+		event := &DownloadEvent{SourceRequestID: s.requestID, EventType: s.eventType, EventData: "{}"}
+		if err := store.SaveEvent(event); err != nil {
+			t.Fatalf("Failed to save event: %v", err)
+		}
+		// SaveEvent 总是使用当前时间，这里改写为固定时间
+		_, err := store.db.Exec(`UPDATE download_events SET created_at = ? WHERE id = (SELECT MAX(id) FROM download_events)`,
+			base.Add(time.Duration(s.hours)*time.Hour))
+		if err != nil {
+			t.Fatalf("Failed to set created_at: %v", err)
+		}
+	}
+
+	// 单个请求的时间线（正序）
+	events, err := store.QueryEvents(EventQuery{SourceRequestID: "movie-1", Ascending: true})
+	if err != nil {
+		t.Fatalf("QueryEvents() error = %v", err)
+	}
+	if len(events) != 3 || events[0].EventType != EventSubscribed || events[2].EventType != EventTransferComplete {
+		t.Errorf("Unexpected timeline %+v", events)
+	}
+
+	// 按类型
+	events, err = store.QueryEvents(EventQuery{Types: []EventType{EventSubscribed}})
+	if err != nil {
+		t.Fatalf("QueryEvents() error = %v", err)
+	}
+	if len(events) != 2 || events[0].SourceRequestID != "tv-1" {
+		t.Errorf("Unexpected events by type %+v", events)
+	}
+
+	// 按时间范围（Since 包含，Until 不包含）
+	window := EventQuery{Since: base.Add(time.Hour), Until: base.Add(5 * time.Hour)}
+	events, err = store.QueryEvents(window)
+	if err != nil {
+		t.Fatalf("QueryEvents() error = %v", err)
+	}
+	if len(events) != 2 {
+		t.Errorf("Expected 2 events in range, got %d", len(events))
+	}
+
+	// 全局分页
+	var pages [][]*DownloadEvent
+	for offset := 0; ; offset += 2 {
+		page, err := store.QueryEvents(EventQuery{Limit: 2, Offset: offset})
+		if err != nil {
+			t.Fatalf("QueryEvents() error = %v", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		pages = append(pages, page)
+	}
+	if len(pages) != 3 || pages[0][0].EventType != EventEpisodesArrived || len(pages[2]) != 1 {
+		t.Errorf("Unexpected pages %+v", pages)
+	}
+
+	total, err := store.CountEvents(EventQuery{Limit: 2})
+	if err != nil {
+		t.Fatalf("CountEvents() error = %v", err)
+	}
+	if total != 5 {
+		t.Errorf("Expected 5 events, got %d", total)
+	}
+	count, err := store.CountEvents(EventQuery{SourceRequestID: "tv-1", Types: []EventType{EventSubscribed, EventEpisodesArrived}})
+	if err != nil {
+		t.Fatalf("CountEvents() error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 tv events, got %d", count)
+	}
+}
@@ -0,0 +1,162 @@
+package store
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// latencyColumn 阶段对应的列名
+func latencyColumn(stage LatencyStage) string {
+	return string(stage) + "_seconds"
+}
+
+// latencyColumns 所有阶段的列名，按 LatencyStages 顺序
+func latencyColumns() []string {
+	columns := make([]string, len(LatencyStages))
+	for i, stage := range LatencyStages {
+		columns[i] = latencyColumn(stage)
+	}
+	return columns
+}
+
+// SaveRequestLatency 保存请求各阶段耗时，已存在时覆盖
+func (s *SQLiteStore) SaveRequestLatency(latency *RequestLatency) error {
+	latency.UpdatedAt = time.Now()
+
+	columns := latencyColumns()
+	updates := make([]string, len(columns))
+	for i, column := range columns {
+		updates[i] = column + " = excluded." + column
+	}
+
+	query := `
+		INSERT INTO request_latency (
+			source_request_id, title, media_type, completed_at, week, ` + strings.Join(columns, ", ") + `, updated_at
+		) VALUES (?, ?, ?, ?, ?` + strings.Repeat(", ?", len(columns)) + `, ?)
+		ON CONFLICT(source_request_id) DO UPDATE SET
+			title = excluded.title,
+			media_type = excluded.media_type,
+			completed_at = excluded.completed_at,
+			week = excluded.week,
+			` + strings.Join(updates, ",\n\t\t\t") + `,
+			updated_at = excluded.updated_at
+	`
+
+	args := []interface{}{latency.SourceRequestID, latency.Title, latency.MediaType, latency.CompletedAt, latency.Week}
+	for _, stage := range LatencyStages {
+		if d, ok := latency.Stages[stage]; ok {
+			args = append(args, int64(d/time.Second))
+		} else {
+			args = append(args, nil)
+		}
+	}
+	args = append(args, latency.UpdatedAt)
+
+	_, err := s.db.Exec(query, args...)
+	return err
+}
+
+// ListRequestLatency 列出指定时间之后完成的请求耗时，按完成时间排序
+func (s *SQLiteStore) ListRequestLatency(since time.Time) ([]*RequestLatency, error) {
+	return s.queryRequestLatency(`WHERE completed_at >= ? ORDER BY completed_at ASC LIMIT -1`, since)
+}
+
+// ListSlowestRequests 列出指定时间之后完成的、总耗时最长的请求
+func (s *SQLiteStore) ListSlowestRequests(since time.Time, limit int) ([]*RequestLatency, error) {
+	return s.queryRequestLatency(`
+		WHERE completed_at >= ? AND total_seconds IS NOT NULL
+		ORDER BY total_seconds DESC
+		LIMIT ?`, since, sqlLimit(limit))
+}
+
+// queryRequestLatency 按条件查询请求耗时
+func (s *SQLiteStore) queryRequestLatency(condition string, args ...interface{}) ([]*RequestLatency, error) {
+	query := `
+		SELECT source_request_id, title, media_type, completed_at, week, ` + strings.Join(latencyColumns(), ", ") + `, updated_at
+		FROM request_latency
+		` + condition
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var latencies []*RequestLatency
+	for rows.Next() {
+		latency := &RequestLatency{Stages: make(map[LatencyStage]time.Duration)}
+		seconds := make([]sql.NullInt64, len(LatencyStages))
+		dest := []interface{}{&latency.SourceRequestID, &latency.Title, &latency.MediaType, &latency.CompletedAt, &latency.Week}
+		for i := range seconds {
+			dest = append(dest, &seconds[i])
+		}
+		dest = append(dest, &latency.UpdatedAt)
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		for i, stage := range LatencyStages {
+			if seconds[i].Valid {
+				latency.Stages[stage] = time.Duration(seconds[i].Int64) * time.Second
+			}
+		}
+		latencies = append(latencies, latency)
+	}
+
+	return latencies, rows.Err()
+}
+
+// SaveLatencyStat 保存每周耗时统计，已存在时覆盖
+func (s *SQLiteStore) SaveLatencyStat(stat *LatencyStat) error {
+	stat.UpdatedAt = time.Now()
+
+	query := `
+		INSERT INTO latency_stats (week, media_type, stage, samples, p50_seconds, p90_seconds, max_seconds, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(week, media_type, stage) DO UPDATE SET
+			samples = excluded.samples,
+			p50_seconds = excluded.p50_seconds,
+			p90_seconds = excluded.p90_seconds,
+			max_seconds = excluded.max_seconds,
+			updated_at = excluded.updated_at
+	`
+
+	_, err := s.db.Exec(query,
+		stat.Week, stat.MediaType, stat.Stage, stat.Samples,
+		int64(stat.P50/time.Second), int64(stat.P90/time.Second), int64(stat.Max/time.Second),
+		stat.UpdatedAt,
+	)
+	return err
+}
+
+// ListLatencyStats 列出指定周（含）之后的耗时统计，按周倒序
+func (s *SQLiteStore) ListLatencyStats(sinceWeek string) ([]*LatencyStat, error) {
+	query := `
+		SELECT week, media_type, stage, samples, p50_seconds, p90_seconds, max_seconds, updated_at
+		FROM latency_stats
+		WHERE week >= ?
+		ORDER BY week DESC, media_type ASC, stage ASC
+	`
+
+	rows, err := s.db.Query(query, sinceWeek)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []*LatencyStat
+	for rows.Next() {
+		stat := &LatencyStat{}
+		var p50, p90, longest int64
+		if err := rows.Scan(&stat.Week, &stat.MediaType, &stat.Stage, &stat.Samples, &p50, &p90, &longest, &stat.UpdatedAt); err != nil {
+			return nil, err
+		}
+		stat.P50 = time.Duration(p50) * time.Second
+		stat.P90 = time.Duration(p90) * time.Second
+		stat.Max = time.Duration(longest) * time.Second
+		stats = append(stats, stat)
+	}
+
+	return stats, rows.Err()
+}
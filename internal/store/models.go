@@ -2,6 +2,9 @@ package store
 
 import (
 	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -22,6 +25,7 @@ const (
 	StatusSynced     SyncStatus = "synced"     // 已同步
 	StatusFailed     SyncStatus = "failed"     // 同步失败
 	StatusRetrying   SyncStatus = "retrying"   // 重试中
+	StatusOrphaned   SyncStatus = "orphaned"   // MP 中的订阅已丢失
 )
 
 // Request 存储在本地的请求记录
@@ -31,11 +35,14 @@ type Request struct {
 	MediaType       MediaType  `json:"media_type"`
 	TMDBID          int        `json:"tmdb_id"`
 	Title           string     `json:"title"`
-	PosterPath      string     `json:"poster_path"` // TMDB 海报路径
+	PosterPath      string     `json:"poster_path"`   // TMDB 海报路径
 	SeasonsJSON     string     `json:"seasons_json"`  // JSON 数组，如 [1,2,3]
 	EpisodesJSON    string     `json:"episodes_json"` // JSON 对象，如 {"1":[1,2,3]}
 	Status          SyncStatus `json:"status"`
 	RequestedAt     time.Time  `json:"requested_at"`
+	ApprovedAt      *time.Time `json:"approved_at,omitempty"`     // Jellyseerr 批准时间（首次同步时记录）
+	RequestedBy     string     `json:"requested_by,omitempty"`    // Jellyseerr 请求人显示名称
+	RequesterEmail  string     `json:"requester_email,omitempty"` // Jellyseerr 请求人邮箱
 	CreatedAt       time.Time  `json:"created_at"`
 	UpdatedAt       time.Time  `json:"updated_at"`
 }
@@ -100,15 +107,34 @@ type MPLink struct {
 type TrackingStatus string
 
 const (
-	TrackingPending      TrackingStatus = "pending"      // 待订阅
-	TrackingSubscribed   TrackingStatus = "subscribed"   // 已订阅
-	TrackingDownloading  TrackingStatus = "downloading"  // 下载中
-	TrackingDownloaded   TrackingStatus = "downloaded"   // 下载完成
-	TrackingTransferred  TrackingStatus = "transferred"  // 已入库
-	TrackingFailed       TrackingStatus = "failed"       // 失败
+	TrackingPending      TrackingStatus = "pending"       // 待订阅
+	TrackingSubscribed   TrackingStatus = "subscribed"    // 已订阅
+	TrackingDownloading  TrackingStatus = "downloading"   // 下载中
+	TrackingDownloaded   TrackingStatus = "downloaded"    // 下载完成
+	TrackingTransferred  TrackingStatus = "transferred"   // 已入库
+	TrackingFailed       TrackingStatus = "failed"        // 失败
 	TrackingManualSearch TrackingStatus = "manual_search" // 手动搜索
+	TrackingAvailable    TrackingStatus = "available"     // 媒体服务器中可观看
+
+	TrackingPartiallyTransferred TrackingStatus = "partially_transferred" // 部分集已入库，仍有已播出的集缺失
+	TrackingAiring               TrackingStatus = "airing"                // 连载中，已播出的集均已入库，等待后续集播出
 )
 
+// IsOpen 是否仍在等待 MP 完成（订阅后、入库前）
+func (s TrackingStatus) IsOpen() bool {
+	switch s {
+	case TrackingSubscribed, TrackingDownloading, TrackingDownloaded, TrackingManualSearch, TrackingPartiallyTransferred, TrackingAiring:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsCompleted 是否已入库（包括已在媒体服务器中确认）
+func (s TrackingStatus) IsCompleted() bool {
+	return s == TrackingTransferred || s == TrackingAvailable
+}
+
 // SubscriptionTracking 订阅跟踪记录
 type SubscriptionTracking struct {
 	ID                 int64          `json:"id"`
@@ -121,13 +147,113 @@ type SubscriptionTracking struct {
 	DownloadStartTime  *time.Time     `json:"download_start_time,omitempty"`
 	DownloadFinishTime *time.Time     `json:"download_finish_time,omitempty"`
 	TransferTime       *time.Time     `json:"transfer_time,omitempty"`
-	RetryCount         int            `json:"retry_count"`
+	RetryCount         int            `json:"retry_count"` // 看门狗触发的搜索次数
 	LastRetryTime      *time.Time     `json:"last_retry_time,omitempty"`
+	TransferRetryCount int            `json:"transfer_retry_count"` // 请求 MP 重新整理的次数
 	ErrorMessage       string         `json:"error_message,omitempty"`
+	DownloadProgress   float64        `json:"download_progress"`          // 下载进度（0-100）
+	DownloadSpeed      string         `json:"download_speed,omitempty"`   // 下载速度，如 "2.5M/s"
+	DownloadETA        string         `json:"download_eta,omitempty"`     // 剩余时间
+	TransferPath       string         `json:"transfer_path,omitempty"`    // MP 整理后的目标路径
+	AvailableTime      *time.Time     `json:"available_time,omitempty"`   // 媒体服务器确认可观看的时间
+	MissingEpisodes    string         `json:"missing_episodes,omitempty"` // 已播出但尚未入库的集，如 "S01E03-E05"
 	CreatedAt          time.Time      `json:"created_at"`
 	UpdatedAt          time.Time      `json:"updated_at"`
 }
 
+// EpisodeTracking 剧集单集跟踪记录
+type EpisodeTracking struct {
+	ID              int64          `json:"id"`
+	SourceRequestID string         `json:"source_request_id"`
+	Season          int            `json:"season"`
+	Episode         int            `json:"episode"`
+	Status          TrackingStatus `json:"status"` // pending, downloading, transferred
+	DownloadTime    *time.Time     `json:"download_time,omitempty"`
+	TransferTime    *time.Time     `json:"transfer_time,omitempty"`
+	AirDate         *time.Time     `json:"air_date,omitempty"`  // TMDB 播出日期，未知时为空
+	Finale          bool           `json:"finale,omitempty"`    // 是否为请求范围内的季终集
+	LateTime        *time.Time     `json:"late_time,omitempty"` // 播出后迟迟未下载、已发出提醒的时间
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+// Aired 是否已播出，播出日期未知时视为已播出
+func (e *EpisodeTracking) Aired(now time.Time) bool {
+	return e.AirDate == nil || !e.AirDate.After(now)
+}
+
+// SeasonProgress 单季入库进度
+type SeasonProgress struct {
+	Season      int `json:"season"`
+	Transferred int `json:"transferred"`
+	Total       int `json:"total"`
+}
+
+// String 格式化为 "S02: 7/10"
+func (p SeasonProgress) String() string {
+	return fmt.Sprintf("S%02d: %d/%d", p.Season, p.Transferred, p.Total)
+}
+
+// SummarizeEpisodes 按季汇总入库进度
+func SummarizeEpisodes(episodes []*EpisodeTracking) []SeasonProgress {
+	bySeason := make(map[int]*SeasonProgress)
+	var seasons []int
+	for _, ep := range episodes {
+		p, ok := bySeason[ep.Season]
+		if !ok {
+			p = &SeasonProgress{Season: ep.Season}
+			bySeason[ep.Season] = p
+			seasons = append(seasons, ep.Season)
+		}
+		p.Total++
+		if ep.Status == TrackingTransferred {
+			p.Transferred++
+		}
+	}
+
+	sort.Ints(seasons)
+	progress := make([]SeasonProgress, 0, len(seasons))
+	for _, season := range seasons {
+		progress = append(progress, *bySeason[season])
+	}
+	return progress
+}
+
+// FormatProgress 将各季进度格式化为一行，如 "S01: 10/10, S02: 7/10"
+func FormatProgress(progress []SeasonProgress) string {
+	parts := make([]string, 0, len(progress))
+	for _, p := range progress {
+		parts = append(parts, p.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// FormatEpisodes 将单集列表格式化为一行，连续的集合并，如 "S01E03-E05, S02E01"
+func FormatEpisodes(episodes []*EpisodeTracking) string {
+	sorted := append([]*EpisodeTracking(nil), episodes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Season != sorted[j].Season {
+			return sorted[i].Season < sorted[j].Season
+		}
+		return sorted[i].Episode < sorted[j].Episode
+	})
+
+	var parts []string
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && sorted[j+1].Season == sorted[i].Season && sorted[j+1].Episode == sorted[j].Episode+1 {
+			j++
+		}
+		part := fmt.Sprintf("S%02dE%02d", sorted[i].Season, sorted[i].Episode)
+		if j > i {
+			part += fmt.Sprintf("-E%02d", sorted[j].Episode)
+		}
+		parts = append(parts, part)
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
+
 // EventType 事件类型
 type EventType string
 
@@ -138,6 +264,16 @@ const (
 	EventTransferComplete EventType = "transfer_complete" // 入库完成
 	EventFailed           EventType = "failed"            // 失败
 	EventManualSearch     EventType = "manual_search"     // 手动搜索
+	EventAdopted          EventType = "adopted"           // 接管 MP 已有订阅
+	EventDriftDetected    EventType = "drift_detected"    // MP 订阅与本地状态不一致
+	EventEpisodesArrived  EventType = "episodes_arrived"  // 部分剧集已入库
+	EventAvailable        EventType = "available"         // 媒体服务器中可观看
+	EventAlreadyExists    EventType = "already_exists"    // 订阅时已在媒体库中
+
+	EventPartiallyTransferred EventType = "partially_transferred" // 部分集已入库，仍有已播出的集缺失
+	EventAiring               EventType = "airing"                // 已播出的集均已入库，等待后续集播出
+	EventEpisodesLate         EventType = "episodes_late"         // 已播出的集超时仍未下载
+	EventTorrentUnhealthy     EventType = "torrent_unhealthy"     // 下载器中的种子停滞、无做种者或出错
 )
 
 // DownloadEvent 下载事件记录
@@ -160,3 +296,126 @@ type DailyReport struct {
 	ReportContent    string    `json:"report_content"` // JSON 格式的详细报告
 	CreatedAt        time.Time `json:"created_at"`
 }
+
+// CleanupAction 已完成订阅的清理动作
+type CleanupAction string
+
+const (
+	CleanupDeleted     CleanupAction = "deleted"      // 已删除订阅
+	CleanupBestVersion CleanupAction = "best_version" // 已切换为洗版
+	CleanupGone        CleanupAction = "gone"         // 订阅已不存在（MP 已自动删除）
+)
+
+// SubscriptionCleanup 订阅清理记录
+type SubscriptionCleanup struct {
+	ID              int64         `json:"id"`
+	SourceRequestID string        `json:"source_request_id"`
+	MPSubscribeID   string        `json:"mp_subscribe_id"`
+	Action          CleanupAction `json:"action"`
+	Detail          string        `json:"detail,omitempty"`
+	CreatedAt       time.Time     `json:"created_at"`
+}
+
+// UnmatchedMediaEvent 无法匹配到跟踪记录的媒体服务器事件，保存用于排查
+type UnmatchedMediaEvent struct {
+	ID        int64     `json:"id"`
+	Source    string    `json:"source"`     // 来源，如 jellyfin
+	EventType string    `json:"event_type"` // 如 ItemAdded
+	ItemType  string    `json:"item_type"`  // Movie, Episode 等
+	ItemName  string    `json:"item_name"`
+	TMDBID    int       `json:"tmdb_id"`
+	Season    int       `json:"season"`
+	Episode   int       `json:"episode"`
+	Reason    string    `json:"reason"`  // 未匹配的原因
+	Payload   string    `json:"payload"` // 原始请求体
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LatencyStage 请求生命周期阶段
+type LatencyStage string
+
+const (
+	StageApproval  LatencyStage = "approval"  // 批准 -> 订阅
+	StageSearch    LatencyStage = "search"    // 订阅 -> 开始下载
+	StageDownload  LatencyStage = "download"  // 开始下载 -> 下载完成
+	StageTransfer  LatencyStage = "transfer"  // 下载完成 -> 入库
+	StageAvailable LatencyStage = "available" // 入库 -> 媒体服务器可观看
+	StageTotal     LatencyStage = "total"     // 批准 -> 完成（可观看，未接入媒体服务器时为入库）
+)
+
+// LatencyStages 按生命周期顺序排列的阶段
+var LatencyStages = []LatencyStage{StageApproval, StageSearch, StageDownload, StageTransfer, StageAvailable, StageTotal}
+
+// RequestLatency 已完成请求的各阶段耗时
+type RequestLatency struct {
+	SourceRequestID string                         `json:"source_request_id"`
+	Title           string                         `json:"title"`
+	MediaType       MediaType                      `json:"media_type"`
+	CompletedAt     time.Time                      `json:"completed_at"`
+	Week            string                         `json:"week"`   // 完成时间所在周的周一，YYYY-MM-DD
+	Stages          map[LatencyStage]time.Duration `json:"stages"` // 缺少时间戳的阶段不在映射中
+	UpdatedAt       time.Time                      `json:"updated_at"`
+}
+
+// LatencyStat 每周按媒体类型汇总的阶段耗时百分位
+type LatencyStat struct {
+	Week      string        `json:"week"` // 周一，YYYY-MM-DD
+	MediaType MediaType     `json:"media_type"`
+	Stage     LatencyStage  `json:"stage"`
+	Samples   int           `json:"samples"`
+	P50       time.Duration `json:"p50"`
+	P90       time.Duration `json:"p90"`
+	Max       time.Duration `json:"max"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// TorrentIssue 种子健康问题
+type TorrentIssue string
+
+const (
+	TorrentHealthy   TorrentIssue = ""           // 正常
+	TorrentStalled   TorrentIssue = "stalled"    // 下载停滞
+	TorrentNoSeeders TorrentIssue = "no_seeders" // 没有做种者
+	TorrentErrored   TorrentIssue = "error"      // 下载器报告错误
+)
+
+// TrackedTorrent 跟踪请求对应的下载器种子
+type TrackedTorrent struct {
+	Hash            string       `json:"hash"` // 小写 info hash
+	SourceRequestID string       `json:"source_request_id"`
+	Name            string       `json:"name"`
+	State           string       `json:"state"` // 最近一次检查时的下载器状态
+	Issue           TorrentIssue `json:"issue,omitempty"`
+	IssueDetail     string       `json:"issue_detail,omitempty"` // 下载器的错误信息等
+	IssueSince      *time.Time   `json:"issue_since,omitempty"`  // 问题首次出现的时间
+	AlertedAt       *time.Time   `json:"alerted_at,omitempty"`   // 已发送告警的时间
+	ReplacedAt      *time.Time   `json:"replaced_at,omitempty"`  // 已请求 MP 更换资源的时间
+	ResolvedAt      *time.Time   `json:"resolved_at,omitempty"`  // 下载完成或已从下载器移除的时间
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+// DeliveryStatus Webhook 投递状态
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"   // 投递中
+	DeliveryDelivered DeliveryStatus = "delivered" // 已投递
+	DeliveryFailed    DeliveryStatus = "failed"    // 重试耗尽仍失败
+)
+
+// WebhookDelivery 外发 Webhook 的一次投递
+type WebhookDelivery struct {
+	ID              int64          `json:"id"`
+	URL             string         `json:"url"`
+	Event           string         `json:"event"`
+	SourceRequestID string         `json:"source_request_id,omitempty"`
+	Payload         string         `json:"payload"` // 请求体，重放时原样发送
+	Status          DeliveryStatus `json:"status"`
+	Attempts        int            `json:"attempts"`
+	StatusCode      int            `json:"status_code,omitempty"` // 最近一次响应的状态码
+	LastError       string         `json:"last_error,omitempty"`
+	DeliveredAt     *time.Time     `json:"delivered_at,omitempty"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
@@ -16,6 +16,7 @@ type Store interface {
 	SaveRequest(req *Request) error
 	GetRequest(sourceRequestID string) (*Request, error)
 	ListPendingRequests(limit int) ([]*Request, error)
+	ListUntrackedRequests(limit int) ([]*Request, error)
 	UpdateRequestStatus(sourceRequestID string, status SyncStatus) error
 
 	// MPLink 相关
@@ -23,22 +24,63 @@ type Store interface {
 	GetMPLink(sourceRequestID string) (*MPLink, error)
 	UpdateMPLink(link *MPLink) error
 	ListFailedLinks(limit int) ([]*MPLink, error)
+	ListLinksByState(state SyncStatus, limit int) ([]*MPLink, error)
 
 	// SubscriptionTracking 相关
 	SaveTracking(tracking *SubscriptionTracking) error
 	GetTracking(sourceRequestID string) (*SubscriptionTracking, error)
 	UpdateTracking(tracking *SubscriptionTracking) error
 	ListTrackingByStatus(status TrackingStatus, limit int) ([]*SubscriptionTracking, error)
+	ListTrackingByTMDBID(tmdbID int, mediaType MediaType) ([]*SubscriptionTracking, error)
+
+	// EpisodeTracking 相关
+	SaveEpisode(episode *EpisodeTracking) error
+	ListEpisodes(sourceRequestID string) ([]*EpisodeTracking, error)
 
 	// DownloadEvent 相关
 	SaveEvent(event *DownloadEvent) error
 	ListEvents(sourceRequestID string, limit int) ([]*DownloadEvent, error)
+	QueryEvents(q EventQuery) ([]*DownloadEvent, error)
+	CountEvents(q EventQuery) (int, error)
+	CountEventsSince(since time.Time) (map[EventType]int, error)
 
 	// DailyReport 相关
 	SaveReport(report *DailyReport) error
 	GetReport(reportDate string) (*DailyReport, error)
 	ListRecentReports(days int) ([]*DailyReport, error)
 
+	// SubscriptionCleanup 相关
+	SaveCleanup(cleanup *SubscriptionCleanup) error
+	GetLatestCleanup(sourceRequestID, subscribeID string) (*SubscriptionCleanup, error)
+	ListCleanups(limit int) ([]*SubscriptionCleanup, error)
+
+	// 未匹配的媒体服务器事件
+	SaveUnmatchedEvent(event *UnmatchedMediaEvent) error
+	ListUnmatchedEvents(limit int) ([]*UnmatchedMediaEvent, error)
+
+	// 下载器种子健康
+	TrackTorrent(torrent *TrackedTorrent) error
+	UpdateTorrent(torrent *TrackedTorrent) error
+	ListActiveTorrents() ([]*TrackedTorrent, error)
+	ListTorrents(sourceRequestID string) ([]*TrackedTorrent, error)
+
+	// 外发 Webhook 投递记录
+	SaveWebhookDelivery(delivery *WebhookDelivery) error
+	UpdateWebhookDelivery(delivery *WebhookDelivery) error
+	GetWebhookDelivery(id int64) (*WebhookDelivery, error)
+	ListWebhookDeliveries(status DeliveryStatus, limit int) ([]*WebhookDelivery, error)
+
+	// 请求生命周期耗时
+	SaveRequestLatency(latency *RequestLatency) error
+	ListRequestLatency(since time.Time) ([]*RequestLatency, error)
+	ListSlowestRequests(since time.Time, limit int) ([]*RequestLatency, error)
+	SaveLatencyStat(stat *LatencyStat) error
+	ListLatencyStats(sinceWeek string) ([]*LatencyStat, error)
+
+	// 跟踪器状态（如历史记录游标）
+	GetTrackerState(key string) (string, error)
+	SetTrackerState(key, value string) error
+
 	// 统计
 	GetStats() (*Stats, error)
 
@@ -100,6 +142,9 @@ func (s *SQLiteStore) migrate() error {
 		episodes_json TEXT,
 		status TEXT NOT NULL DEFAULT 'pending',
 		requested_at DATETIME NOT NULL,
+		approved_at DATETIME,
+		requested_by TEXT NOT NULL DEFAULT '',
+		requester_email TEXT NOT NULL DEFAULT '',
 		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 	);
@@ -136,7 +181,13 @@ func (s *SQLiteStore) migrate() error {
 		transfer_time DATETIME,
 		retry_count INTEGER NOT NULL DEFAULT 0,
 		last_retry_time DATETIME,
+		transfer_retry_count INTEGER NOT NULL DEFAULT 0,
 		error_message TEXT,
+		download_progress REAL NOT NULL DEFAULT 0,
+		download_speed TEXT NOT NULL DEFAULT '',
+		download_eta TEXT NOT NULL DEFAULT '',
+		transfer_path TEXT NOT NULL DEFAULT '',
+		available_time DATETIME,
 		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 	);
@@ -145,6 +196,25 @@ func (s *SQLiteStore) migrate() error {
 	CREATE INDEX IF NOT EXISTS idx_tracking_source_id ON subscription_tracking(source_request_id);
 	CREATE INDEX IF NOT EXISTS idx_tracking_created_at ON subscription_tracking(created_at);
 
+	-- 剧集单集跟踪表
+	CREATE TABLE IF NOT EXISTS episode_tracking (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		source_request_id TEXT NOT NULL,
+		season INTEGER NOT NULL,
+		episode INTEGER NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		download_time DATETIME,
+		transfer_time DATETIME,
+		air_date DATETIME,
+		finale INTEGER NOT NULL DEFAULT 0,
+		late_time DATETIME,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (source_request_id, season, episode)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_episode_tracking_source_id ON episode_tracking(source_request_id);
+
 	-- 下载事件表
 	CREATE TABLE IF NOT EXISTS download_events (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -157,6 +227,7 @@ func (s *SQLiteStore) migrate() error {
 	CREATE INDEX IF NOT EXISTS idx_events_source_id ON download_events(source_request_id);
 	CREATE INDEX IF NOT EXISTS idx_events_type ON download_events(event_type);
 	CREATE INDEX IF NOT EXISTS idx_events_created_at ON download_events(created_at);
+	CREATE INDEX IF NOT EXISTS idx_events_type_created_at ON download_events(event_type, created_at);
 
 	-- 每日报告表
 	CREATE TABLE IF NOT EXISTS daily_reports (
@@ -171,6 +242,109 @@ func (s *SQLiteStore) migrate() error {
 	);
 
 	CREATE INDEX IF NOT EXISTS idx_reports_date ON daily_reports(report_date);
+
+	-- 跟踪器状态表（键值对）
+	CREATE TABLE IF NOT EXISTS tracker_state (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- 订阅清理记录表
+	CREATE TABLE IF NOT EXISTS subscription_cleanups (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		source_request_id TEXT NOT NULL,
+		mp_subscribe_id TEXT,
+		action TEXT NOT NULL,
+		detail TEXT,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_cleanups_source_id ON subscription_cleanups(source_request_id);
+
+	-- 未匹配的媒体服务器事件表
+	CREATE TABLE IF NOT EXISTS unmatched_media_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		source TEXT NOT NULL,
+		event_type TEXT NOT NULL,
+		item_type TEXT NOT NULL DEFAULT '',
+		item_name TEXT NOT NULL DEFAULT '',
+		tmdb_id INTEGER NOT NULL DEFAULT 0,
+		season INTEGER NOT NULL DEFAULT 0,
+		episode INTEGER NOT NULL DEFAULT 0,
+		reason TEXT NOT NULL DEFAULT '',
+		payload TEXT,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_unmatched_created_at ON unmatched_media_events(created_at);
+
+	-- 请求各阶段耗时表（秒，NULL 表示缺少时间戳）
+	CREATE TABLE IF NOT EXISTS request_latency (
+		source_request_id TEXT PRIMARY KEY,
+		title TEXT NOT NULL,
+		media_type TEXT NOT NULL,
+		completed_at DATETIME NOT NULL,
+		week TEXT NOT NULL,
+		approval_seconds INTEGER,
+		search_seconds INTEGER,
+		download_seconds INTEGER,
+		transfer_seconds INTEGER,
+		available_seconds INTEGER,
+		total_seconds INTEGER,
+		updated_at DATETIME NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_request_latency_completed_at ON request_latency(completed_at);
+
+	-- 每周耗时百分位表
+	CREATE TABLE IF NOT EXISTS latency_stats (
+		week TEXT NOT NULL,
+		media_type TEXT NOT NULL,
+		stage TEXT NOT NULL,
+		samples INTEGER NOT NULL,
+		p50_seconds INTEGER NOT NULL,
+		p90_seconds INTEGER NOT NULL,
+		max_seconds INTEGER NOT NULL,
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (week, media_type, stage)
+	);
+
+	-- 跟踪请求对应的下载器种子（按 MP 下载历史中的 download_hash 关联）
+	CREATE TABLE IF NOT EXISTS tracked_torrents (
+		hash TEXT PRIMARY KEY,
+		source_request_id TEXT NOT NULL,
+		name TEXT NOT NULL DEFAULT '',
+		state TEXT NOT NULL DEFAULT '',
+		issue TEXT NOT NULL DEFAULT '',
+		issue_detail TEXT NOT NULL DEFAULT '',
+		issue_since DATETIME,
+		alerted_at DATETIME,
+		replaced_at DATETIME,
+		resolved_at DATETIME,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_tracked_torrents_source_id ON tracked_torrents(source_request_id);
+
+	-- 外发 Webhook 投递记录，每个地址一条，失败的投递可以重放
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		url TEXT NOT NULL,
+		event TEXT NOT NULL,
+		source_request_id TEXT NOT NULL DEFAULT '',
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		status_code INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '',
+		delivered_at DATETIME,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status ON webhook_deliveries(status);
 	`
 
 	if _, err := s.db.Exec(schema); err != nil {
@@ -199,6 +373,7 @@ func (s *SQLiteStore) migrate() error {
 			break
 		}
 	}
+	rows.Close()
 
 	// 如果不存在 poster_path 列，添加它
 	if !hasPosterPath {
@@ -207,6 +382,77 @@ func (s *SQLiteStore) migrate() error {
 		}
 	}
 
+	// 迁移：为已存在的 requests 表添加批准时间和请求人列
+	requestColumns := []struct{ name, definition string }{
+		{"approved_at", "DATETIME"},
+		{"requested_by", "TEXT NOT NULL DEFAULT ''"},
+		{"requester_email", "TEXT NOT NULL DEFAULT ''"},
+	}
+	for _, col := range requestColumns {
+		if err := s.ensureColumn("requests", col.name, col.definition); err != nil {
+			return err
+		}
+	}
+
+	// 迁移：为已存在的 subscription_tracking 表添加下载进度、媒体服务器确认和重新整理次数列
+	trackingColumns := []struct{ name, definition string }{
+		{"transfer_retry_count", "INTEGER NOT NULL DEFAULT 0"},
+		{"download_progress", "REAL NOT NULL DEFAULT 0"},
+		{"download_speed", "TEXT NOT NULL DEFAULT ''"},
+		{"download_eta", "TEXT NOT NULL DEFAULT ''"},
+		{"transfer_path", "TEXT NOT NULL DEFAULT ''"},
+		{"available_time", "DATETIME"},
+		{"missing_episodes", "TEXT NOT NULL DEFAULT ''"},
+	}
+	for _, column := range trackingColumns {
+		if err := s.ensureColumn("subscription_tracking", column.name, column.definition); err != nil {
+			return err
+		}
+	}
+
+	// 迁移：为已存在的 episode_tracking 表添加播出日期、季终集和超时提醒列
+	episodeColumns := []struct{ name, definition string }{
+		{"air_date", "DATETIME"},
+		{"finale", "INTEGER NOT NULL DEFAULT 0"},
+		{"late_time", "DATETIME"},
+	}
+	for _, column := range episodeColumns {
+		if err := s.ensureColumn("episode_tracking", column.name, column.definition); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ensureColumn 列不存在时添加列
+func (s *SQLiteStore) ensureColumn(table, column, definition string) error {
+	rows, err := s.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("check table schema: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid int
+		var name, typ string
+		var notNull, pk int
+		var dfltValue sql.NullString
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dfltValue, &pk); err != nil {
+			return fmt.Errorf("scan table info: %w", err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("scan table info: %w", err)
+	}
+	rows.Close()
+
+	if _, err := s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("add %s column: %w", column, err)
+	}
 	return nil
 }
 
@@ -217,8 +463,8 @@ func (s *SQLiteStore) SaveRequest(req *Request) error {
 	req.UpdatedAt = now
 
 	query := `
-		INSERT INTO requests (source_request_id, media_type, tmdb_id, title, poster_path, seasons_json, episodes_json, status, requested_at, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO requests (source_request_id, media_type, tmdb_id, title, poster_path, seasons_json, episodes_json, status, requested_at, approved_at, requested_by, requester_email, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(source_request_id) DO UPDATE SET
 			media_type = excluded.media_type,
 			tmdb_id = excluded.tmdb_id,
@@ -228,13 +474,16 @@ func (s *SQLiteStore) SaveRequest(req *Request) error {
 			episodes_json = excluded.episodes_json,
 			status = excluded.status,
 			requested_at = excluded.requested_at,
+			approved_at = COALESCE(requests.approved_at, excluded.approved_at),
+			requested_by = excluded.requested_by,
+			requester_email = excluded.requester_email,
 			updated_at = excluded.updated_at
 	`
 
 	result, err := s.db.Exec(query,
 		req.SourceRequestID, req.MediaType, req.TMDBID, req.Title, req.PosterPath,
-		req.SeasonsJSON, req.EpisodesJSON, req.Status, req.RequestedAt,
-		req.CreatedAt, req.UpdatedAt,
+		req.SeasonsJSON, req.EpisodesJSON, req.Status, req.RequestedAt, req.ApprovedAt,
+		req.RequestedBy, req.RequesterEmail, req.CreatedAt, req.UpdatedAt,
 	)
 	if err != nil {
 		return err
@@ -253,7 +502,7 @@ func (s *SQLiteStore) SaveRequest(req *Request) error {
 // GetRequest 获取请求
 func (s *SQLiteStore) GetRequest(sourceRequestID string) (*Request, error) {
 	query := `
-		SELECT id, source_request_id, media_type, tmdb_id, title, poster_path, seasons_json, episodes_json, status, requested_at, created_at, updated_at
+		SELECT id, source_request_id, media_type, tmdb_id, title, poster_path, seasons_json, episodes_json, status, requested_at, approved_at, requested_by, requester_email, created_at, updated_at
 		FROM requests
 		WHERE source_request_id = ?
 	`
@@ -262,8 +511,8 @@ func (s *SQLiteStore) GetRequest(sourceRequestID string) (*Request, error) {
 	var posterPath sql.NullString
 	err := s.db.QueryRow(query, sourceRequestID).Scan(
 		&req.ID, &req.SourceRequestID, &req.MediaType, &req.TMDBID, &req.Title, &posterPath,
-		&req.SeasonsJSON, &req.EpisodesJSON, &req.Status, &req.RequestedAt,
-		&req.CreatedAt, &req.UpdatedAt,
+		&req.SeasonsJSON, &req.EpisodesJSON, &req.Status, &req.RequestedAt, &req.ApprovedAt,
+		&req.RequestedBy, &req.RequesterEmail, &req.CreatedAt, &req.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -280,17 +529,32 @@ func (s *SQLiteStore) GetRequest(sourceRequestID string) (*Request, error) {
 	return req, nil
 }
 
-// ListPendingRequests 列出待处理请求
+// ListPendingRequests 列出待处理请求（limit <= 0 表示不限制）
 func (s *SQLiteStore) ListPendingRequests(limit int) ([]*Request, error) {
-	query := `
-		SELECT id, source_request_id, media_type, tmdb_id, title, poster_path, seasons_json, episodes_json, status, requested_at, created_at, updated_at
+	return s.queryRequests(`
+		SELECT id, source_request_id, media_type, tmdb_id, title, poster_path, seasons_json, episodes_json, status, requested_at, approved_at, requested_by, requester_email, created_at, updated_at
 		FROM requests
 		WHERE status = 'pending' OR status = 'retrying'
 		ORDER BY requested_at ASC
 		LIMIT ?
-	`
+	`, sqlLimit(limit))
+}
 
-	rows, err := s.db.Query(query, limit)
+// ListUntrackedRequests 列出已同步但没有跟踪记录的请求（limit <= 0 表示不限制）
+func (s *SQLiteStore) ListUntrackedRequests(limit int) ([]*Request, error) {
+	return s.queryRequests(`
+		SELECT id, source_request_id, media_type, tmdb_id, title, poster_path, seasons_json, episodes_json, status, requested_at, approved_at, requested_by, requester_email, created_at, updated_at
+		FROM requests r
+		WHERE status = 'synced'
+			AND NOT EXISTS (SELECT 1 FROM subscription_tracking t WHERE t.source_request_id = r.source_request_id)
+		ORDER BY requested_at ASC
+		LIMIT ?
+	`, sqlLimit(limit))
+}
+
+// queryRequests 执行查询并扫描请求列表
+func (s *SQLiteStore) queryRequests(query string, args ...any) ([]*Request, error) {
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -302,8 +566,8 @@ func (s *SQLiteStore) ListPendingRequests(limit int) ([]*Request, error) {
 		var posterPath sql.NullString
 		if err := rows.Scan(
 			&req.ID, &req.SourceRequestID, &req.MediaType, &req.TMDBID, &req.Title, &posterPath,
-			&req.SeasonsJSON, &req.EpisodesJSON, &req.Status, &req.RequestedAt,
-			&req.CreatedAt, &req.UpdatedAt,
+			&req.SeasonsJSON, &req.EpisodesJSON, &req.Status, &req.RequestedAt, &req.ApprovedAt,
+			&req.RequestedBy, &req.RequesterEmail, &req.CreatedAt, &req.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -435,6 +699,40 @@ func (s *SQLiteStore) ListFailedLinks(limit int) ([]*MPLink, error) {
 	return links, rows.Err()
 }
 
+// ListLinksByState 根据状态列出链接（limit <= 0 表示不限制）
+func (s *SQLiteStore) ListLinksByState(state SyncStatus, limit int) ([]*MPLink, error) {
+	query := `
+		SELECT id, source_request_id, mp_subscribe_id, state, last_error, retry_count, created_at, updated_at
+		FROM mp_links
+		WHERE state = ?
+		ORDER BY updated_at ASC
+		LIMIT ?
+	`
+
+	rows, err := s.db.Query(query, state, sqlLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []*MPLink
+	for rows.Next() {
+		link := &MPLink{}
+		var mpSubscribeID, lastError sql.NullString
+		if err := rows.Scan(
+			&link.ID, &link.SourceRequestID, &mpSubscribeID, &link.State,
+			&lastError, &link.RetryCount, &link.CreatedAt, &link.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		link.MPSubscribeID = mpSubscribeID.String
+		link.LastError = lastError.String
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
+
 // GetStats 获取统计信息
 func (s *SQLiteStore) GetStats() (*Stats, error) {
 	query := `
@@ -458,6 +756,14 @@ func (s *SQLiteStore) GetStats() (*Stats, error) {
 	return stats, nil
 }
 
+// sqlLimit 将 limit <= 0 转换为 SQLite 的不限制（-1）
+func sqlLimit(limit int) int {
+	if limit <= 0 {
+		return -1
+	}
+	return limit
+}
+
 // Close 关闭数据库连接
 func (s *SQLiteStore) Close() error {
 	return s.db.Close()
@@ -139,6 +139,54 @@ func TestListPendingRequests(t *testing.T) {
 	if len(requests) != 5 {
 		t.Errorf("ListPendingRequests() returned %d requests, want 5", len(requests))
 	}
+
+	// limit <= 0 表示不限制
+	all, err := store.ListPendingRequests(0)
+	if err != nil {
+		t.Fatalf("ListPendingRequests(0) error = %v", err)
+	}
+	if len(all) != 5 {
+		t.Errorf("ListPendingRequests(0) returned %d requests, want 5", len(all))
+	}
+}
+
+func TestListUntrackedRequests(t *testing.T) {
+	dbPath := "./test_untracked.db"
+	defer os.Remove(dbPath)
+
+	store, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore() error = %v", err)
+	}
+	defer store.Close()
+
+	for _, req := range []*Request{
+		{SourceRequestID: "synced-untracked", Status: StatusSynced},
+		{SourceRequestID: "synced-tracked", Status: StatusSynced},
+		{SourceRequestID: "pending", Status: StatusPending},
+	} {
+		req.MediaType = MediaTypeMovie
+		req.Title = "Test Movie"
+		req.RequestedAt = time.Now()
+		if err := store.SaveRequest(req); err != nil {
+			t.Fatalf("SaveRequest() error = %v", err)
+		}
+	}
+	if err := store.SaveTracking(&SubscriptionTracking{
+		SourceRequestID: "synced-tracked",
+		MediaType:       MediaTypeMovie,
+		SubscribeStatus: TrackingSubscribed,
+	}); err != nil {
+		t.Fatalf("SaveTracking() error = %v", err)
+	}
+
+	requests, err := store.ListUntrackedRequests(0)
+	if err != nil {
+		t.Fatalf("ListUntrackedRequests() error = %v", err)
+	}
+	if len(requests) != 1 || requests[0].SourceRequestID != "synced-untracked" {
+		t.Errorf("ListUntrackedRequests() = %+v, want only synced-untracked", requests)
+	}
 }
 
 func TestGetStats(t *testing.T) {
@@ -189,3 +237,42 @@ func TestGetStats(t *testing.T) {
 		t.Errorf("FailedRequests = %d, want 1", stats.FailedRequests)
 	}
 }
+
+func TestListLinksByState(t *testing.T) {
+	dbPath := "./test_links.db"
+	defer os.Remove(dbPath)
+
+	store, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore() error = %v", err)
+	}
+	defer store.Close()
+
+	states := []SyncStatus{StatusSynced, StatusSynced, StatusOrphaned}
+	for i, state := range states {
+		link := &MPLink{
+			SourceRequestID: string(rune('a' + i)),
+			MPSubscribeID:   string(rune('1' + i)),
+			State:           state,
+		}
+		if err := store.SaveMPLink(link); err != nil {
+			t.Fatalf("SaveMPLink() error = %v", err)
+		}
+	}
+
+	synced, err := store.ListLinksByState(StatusSynced, 0)
+	if err != nil {
+		t.Fatalf("ListLinksByState() error = %v", err)
+	}
+	if len(synced) != 2 {
+		t.Errorf("ListLinksByState(synced) returned %d links, want 2", len(synced))
+	}
+
+	orphaned, err := store.ListLinksByState(StatusOrphaned, 10)
+	if err != nil {
+		t.Fatalf("ListLinksByState() error = %v", err)
+	}
+	if len(orphaned) != 1 {
+		t.Errorf("ListLinksByState(orphaned) returned %d links, want 1", len(orphaned))
+	}
+}
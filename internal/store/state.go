@@ -0,0 +1,29 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// GetTrackerState 读取跟踪器状态，不存在时返回空字符串
+func (s *SQLiteStore) GetTrackerState(key string) (string, error) {
+	var value string
+	err := s.db.QueryRow(`SELECT value FROM tracker_state WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// SetTrackerState 写入跟踪器状态
+func (s *SQLiteStore) SetTrackerState(key, value string) error {
+	query := `
+		INSERT INTO tracker_state (key, value, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+	`
+	_, err := s.db.Exec(query, key, value, time.Now())
+	return err
+}
@@ -0,0 +1,82 @@
+package store
+
+import (
+	"strings"
+	"time"
+)
+
+// torrentColumns tracked_torrents 查询列，顺序与 scanTorrent 一致
+const torrentColumns = `hash, source_request_id, name, state, issue, issue_detail, issue_since,
+	alerted_at, replaced_at, resolved_at, created_at, updated_at`
+
+// TrackTorrent 关联种子和请求，已存在时只补充名称，不覆盖健康状态
+func (s *SQLiteStore) TrackTorrent(torrent *TrackedTorrent) error {
+	now := time.Now()
+	torrent.Hash = strings.ToLower(torrent.Hash)
+	torrent.CreatedAt = now
+	torrent.UpdatedAt = now
+
+	query := `
+		INSERT INTO tracked_torrents (hash, source_request_id, name, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(hash) DO UPDATE SET
+			name = CASE WHEN tracked_torrents.name = '' THEN excluded.name ELSE tracked_torrents.name END
+	`
+
+	_, err := s.db.Exec(query, torrent.Hash, torrent.SourceRequestID, torrent.Name, torrent.CreatedAt, torrent.UpdatedAt)
+	return err
+}
+
+// UpdateTorrent 更新种子的健康状态
+func (s *SQLiteStore) UpdateTorrent(torrent *TrackedTorrent) error {
+	torrent.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE tracked_torrents SET
+			name = ?, state = ?, issue = ?, issue_detail = ?, issue_since = ?,
+			alerted_at = ?, replaced_at = ?, resolved_at = ?, updated_at = ?
+		WHERE hash = ?
+	`
+
+	_, err := s.db.Exec(query,
+		torrent.Name, torrent.State, torrent.Issue, torrent.IssueDetail, torrent.IssueSince,
+		torrent.AlertedAt, torrent.ReplacedAt, torrent.ResolvedAt, torrent.UpdatedAt,
+		torrent.Hash,
+	)
+	return err
+}
+
+// ListActiveTorrents 列出尚未完成或移除的种子
+func (s *SQLiteStore) ListActiveTorrents() ([]*TrackedTorrent, error) {
+	return s.queryTorrents(`WHERE resolved_at IS NULL ORDER BY created_at ASC`)
+}
+
+// ListTorrents 列出请求关联的全部种子
+func (s *SQLiteStore) ListTorrents(sourceRequestID string) ([]*TrackedTorrent, error) {
+	return s.queryTorrents(`WHERE source_request_id = ? ORDER BY created_at ASC`, sourceRequestID)
+}
+
+// queryTorrents 按条件查询种子
+func (s *SQLiteStore) queryTorrents(condition string, args ...interface{}) ([]*TrackedTorrent, error) {
+	rows, err := s.db.Query(`SELECT `+torrentColumns+` FROM tracked_torrents `+condition, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var torrents []*TrackedTorrent
+	for rows.Next() {
+		torrent := &TrackedTorrent{}
+		err := rows.Scan(
+			&torrent.Hash, &torrent.SourceRequestID, &torrent.Name, &torrent.State, &torrent.Issue,
+			&torrent.IssueDetail, &torrent.IssueSince, &torrent.AlertedAt, &torrent.ReplacedAt,
+			&torrent.ResolvedAt, &torrent.CreatedAt, &torrent.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		torrents = append(torrents, torrent)
+	}
+
+	return torrents, rows.Err()
+}
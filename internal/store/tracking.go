@@ -5,6 +5,13 @@ import (
 	"time"
 )
 
+// trackingColumns 查询跟踪记录的列，顺序与 scanTracking 一致
+const trackingColumns = `id, source_request_id, tmdb_id, title, media_type, subscribe_status,
+			subscribe_time, download_start_time, download_finish_time, transfer_time,
+			retry_count, last_retry_time, transfer_retry_count, error_message,
+			download_progress, download_speed, download_eta, transfer_path, available_time,
+			missing_episodes, created_at, updated_at`
+
 // SaveTracking 保存订阅跟踪记录
 func (s *SQLiteStore) SaveTracking(tracking *SubscriptionTracking) error {
 	now := time.Now()
@@ -15,8 +22,10 @@ func (s *SQLiteStore) SaveTracking(tracking *SubscriptionTracking) error {
 		INSERT INTO subscription_tracking (
 			source_request_id, tmdb_id, title, media_type, subscribe_status,
 			subscribe_time, download_start_time, download_finish_time, transfer_time,
-			retry_count, last_retry_time, error_message, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			retry_count, last_retry_time, transfer_retry_count, error_message,
+			download_progress, download_speed, download_eta, transfer_path, available_time,
+			missing_episodes, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(source_request_id) DO UPDATE SET
 			subscribe_status = excluded.subscribe_status,
 			subscribe_time = excluded.subscribe_time,
@@ -25,7 +34,14 @@ func (s *SQLiteStore) SaveTracking(tracking *SubscriptionTracking) error {
 			transfer_time = excluded.transfer_time,
 			retry_count = excluded.retry_count,
 			last_retry_time = excluded.last_retry_time,
+			transfer_retry_count = excluded.transfer_retry_count,
 			error_message = excluded.error_message,
+			download_progress = excluded.download_progress,
+			download_speed = excluded.download_speed,
+			download_eta = excluded.download_eta,
+			transfer_path = excluded.transfer_path,
+			available_time = excluded.available_time,
+			missing_episodes = excluded.missing_episodes,
 			updated_at = excluded.updated_at
 	`
 
@@ -33,7 +49,9 @@ func (s *SQLiteStore) SaveTracking(tracking *SubscriptionTracking) error {
 		tracking.SourceRequestID, tracking.TMDBID, tracking.Title, tracking.MediaType,
 		tracking.SubscribeStatus, tracking.SubscribeTime, tracking.DownloadStartTime,
 		tracking.DownloadFinishTime, tracking.TransferTime, tracking.RetryCount,
-		tracking.LastRetryTime, tracking.ErrorMessage, tracking.CreatedAt, tracking.UpdatedAt,
+		tracking.LastRetryTime, tracking.TransferRetryCount, tracking.ErrorMessage, tracking.DownloadProgress,
+		tracking.DownloadSpeed, tracking.DownloadETA, tracking.TransferPath, tracking.AvailableTime,
+		tracking.MissingEpisodes, tracking.CreatedAt, tracking.UpdatedAt,
 	)
 	return err
 }
@@ -41,21 +59,12 @@ func (s *SQLiteStore) SaveTracking(tracking *SubscriptionTracking) error {
 // GetTracking 获取订阅跟踪记录
 func (s *SQLiteStore) GetTracking(sourceRequestID string) (*SubscriptionTracking, error) {
 	query := `
-		SELECT id, source_request_id, tmdb_id, title, media_type, subscribe_status,
-			subscribe_time, download_start_time, download_finish_time, transfer_time,
-			retry_count, last_retry_time, error_message, created_at, updated_at
+		SELECT ` + trackingColumns + `
 		FROM subscription_tracking
 		WHERE source_request_id = ?
 	`
 
-	tracking := &SubscriptionTracking{}
-	err := s.db.QueryRow(query, sourceRequestID).Scan(
-		&tracking.ID, &tracking.SourceRequestID, &tracking.TMDBID, &tracking.Title,
-		&tracking.MediaType, &tracking.SubscribeStatus, &tracking.SubscribeTime,
-		&tracking.DownloadStartTime, &tracking.DownloadFinishTime, &tracking.TransferTime,
-		&tracking.RetryCount, &tracking.LastRetryTime, &tracking.ErrorMessage,
-		&tracking.CreatedAt, &tracking.UpdatedAt,
-	)
+	tracking, err := scanTracking(s.db.QueryRow(query, sourceRequestID))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -73,47 +82,64 @@ func (s *SQLiteStore) UpdateTracking(tracking *SubscriptionTracking) error {
 		UPDATE subscription_tracking SET
 			subscribe_status = ?, subscribe_time = ?, download_start_time = ?,
 			download_finish_time = ?, transfer_time = ?, retry_count = ?,
-			last_retry_time = ?, error_message = ?, updated_at = ?
+			last_retry_time = ?, transfer_retry_count = ?, error_message = ?, download_progress = ?,
+			download_speed = ?, download_eta = ?, transfer_path = ?,
+			available_time = ?, missing_episodes = ?, updated_at = ?
 		WHERE source_request_id = ?
 	`
 
 	_, err := s.db.Exec(query,
 		tracking.SubscribeStatus, tracking.SubscribeTime, tracking.DownloadStartTime,
 		tracking.DownloadFinishTime, tracking.TransferTime, tracking.RetryCount,
-		tracking.LastRetryTime, tracking.ErrorMessage, tracking.UpdatedAt,
-		tracking.SourceRequestID,
+		tracking.LastRetryTime, tracking.TransferRetryCount, tracking.ErrorMessage, tracking.DownloadProgress,
+		tracking.DownloadSpeed, tracking.DownloadETA, tracking.TransferPath,
+		tracking.AvailableTime, tracking.MissingEpisodes, tracking.UpdatedAt, tracking.SourceRequestID,
 	)
 	return err
 }
 
-// ListTrackingByStatus 根据状态列出跟踪记录
+// ListTrackingByStatus 根据状态列出跟踪记录（limit <= 0 表示不限制）
 func (s *SQLiteStore) ListTrackingByStatus(status TrackingStatus, limit int) ([]*SubscriptionTracking, error) {
 	query := `
-		SELECT id, source_request_id, tmdb_id, title, media_type, subscribe_status,
-			subscribe_time, download_start_time, download_finish_time, transfer_time,
-			retry_count, last_retry_time, error_message, created_at, updated_at
+		SELECT ` + trackingColumns + `
 		FROM subscription_tracking
 		WHERE subscribe_status = ?
 		ORDER BY created_at DESC
 		LIMIT ?
 	`
 
-	rows, err := s.db.Query(query, status, limit)
+	rows, err := s.db.Query(query, status, sqlLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTrackings(rows)
+}
+
+// ListTrackingByTMDBID 根据 TMDB ID 和媒体类型列出跟踪记录（剧集的多个请求可能对应同一 ID）
+func (s *SQLiteStore) ListTrackingByTMDBID(tmdbID int, mediaType MediaType) ([]*SubscriptionTracking, error) {
+	query := `
+		SELECT ` + trackingColumns + `
+		FROM subscription_tracking
+		WHERE tmdb_id = ? AND media_type = ?
+		ORDER BY created_at DESC
+	`
+
+	rows, err := s.db.Query(query, tmdbID, mediaType)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	return scanTrackings(rows)
+}
+
+// scanTrackings 扫描跟踪记录列表
+func scanTrackings(rows *sql.Rows) ([]*SubscriptionTracking, error) {
 	var trackings []*SubscriptionTracking
 	for rows.Next() {
-		tracking := &SubscriptionTracking{}
-		err := rows.Scan(
-			&tracking.ID, &tracking.SourceRequestID, &tracking.TMDBID, &tracking.Title,
-			&tracking.MediaType, &tracking.SubscribeStatus, &tracking.SubscribeTime,
-			&tracking.DownloadStartTime, &tracking.DownloadFinishTime, &tracking.TransferTime,
-			&tracking.RetryCount, &tracking.LastRetryTime, &tracking.ErrorMessage,
-			&tracking.CreatedAt, &tracking.UpdatedAt,
-		)
+		tracking, err := scanTracking(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -123,6 +149,24 @@ func (s *SQLiteStore) ListTrackingByStatus(status TrackingStatus, limit int) ([]
 	return trackings, rows.Err()
 }
 
+// scanTracking 扫描单条跟踪记录（*sql.Row 或 *sql.Rows）
+func scanTracking(scanner interface{ Scan(...any) error }) (*SubscriptionTracking, error) {
+	tracking := &SubscriptionTracking{}
+	err := scanner.Scan(
+		&tracking.ID, &tracking.SourceRequestID, &tracking.TMDBID, &tracking.Title,
+		&tracking.MediaType, &tracking.SubscribeStatus, &tracking.SubscribeTime,
+		&tracking.DownloadStartTime, &tracking.DownloadFinishTime, &tracking.TransferTime,
+		&tracking.RetryCount, &tracking.LastRetryTime, &tracking.TransferRetryCount, &tracking.ErrorMessage,
+		&tracking.DownloadProgress, &tracking.DownloadSpeed, &tracking.DownloadETA,
+		&tracking.TransferPath, &tracking.AvailableTime, &tracking.MissingEpisodes,
+		&tracking.CreatedAt, &tracking.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return tracking, nil
+}
+
 // SaveEvent 保存下载事件
 func (s *SQLiteStore) SaveEvent(event *DownloadEvent) error {
 	event.CreatedAt = time.Now()
@@ -138,31 +182,35 @@ func (s *SQLiteStore) SaveEvent(event *DownloadEvent) error {
 
 // ListEvents 列出指定请求的事件
 func (s *SQLiteStore) ListEvents(sourceRequestID string, limit int) ([]*DownloadEvent, error) {
+	return s.QueryEvents(EventQuery{SourceRequestID: sourceRequestID, Limit: limit})
+}
+
+// CountEventsSince 统计指定时间之后各类型事件的数量
+func (s *SQLiteStore) CountEventsSince(since time.Time) (map[EventType]int, error) {
 	query := `
-		SELECT id, source_request_id, event_type, event_data, created_at
+		SELECT event_type, COUNT(*)
 		FROM download_events
-		WHERE source_request_id = ?
-		ORDER BY created_at DESC
-		LIMIT ?
+		WHERE created_at >= ?
+		GROUP BY event_type
 	`
 
-	rows, err := s.db.Query(query, sourceRequestID, limit)
+	rows, err := s.db.Query(query, since)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var events []*DownloadEvent
+	counts := make(map[EventType]int)
 	for rows.Next() {
-		event := &DownloadEvent{}
-		err := rows.Scan(&event.ID, &event.SourceRequestID, &event.EventType, &event.EventData, &event.CreatedAt)
-		if err != nil {
+		var eventType EventType
+		var count int
+		if err := rows.Scan(&eventType, &count); err != nil {
 			return nil, err
 		}
-		events = append(events, event)
+		counts[eventType] = count
 	}
 
-	return events, rows.Err()
+	return counts, rows.Err()
 }
 
 // SaveReport 保存每日报告
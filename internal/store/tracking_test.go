@@ -47,6 +47,10 @@ func TestTrackingCRUD(t *testing.T) {
 	now := time.Now()
 	got.SubscribeStatus = TrackingSubscribed
 	got.SubscribeTime = &now
+	got.DownloadProgress = 42.5
+	got.DownloadSpeed = "2.5M/s"
+	got.TransferPath = "/media/movies/Test Movie (2024)"
+	got.AvailableTime = &now
 	if err := store.UpdateTracking(got); err != nil {
 		t.Fatalf("Failed to update tracking: %v", err)
 	}
@@ -59,6 +63,12 @@ func TestTrackingCRUD(t *testing.T) {
 	if updated.SubscribeStatus != TrackingSubscribed {
 		t.Errorf("Expected status 'subscribed', got '%s'", updated.SubscribeStatus)
 	}
+	if updated.DownloadProgress != 42.5 || updated.DownloadSpeed != "2.5M/s" {
+		t.Errorf("Expected progress 42.5 at 2.5M/s, got %v at %q", updated.DownloadProgress, updated.DownloadSpeed)
+	}
+	if updated.TransferPath != got.TransferPath || updated.AvailableTime == nil {
+		t.Errorf("Expected transfer path and available time, got %q, %v", updated.TransferPath, updated.AvailableTime)
+	}
 
 	// 测试按状态列出
 	trackings, err := store.ListTrackingByStatus(TrackingSubscribed, 10)
@@ -157,3 +167,315 @@ func TestReportCRUD(t *testing.T) {
 
 	t.Log("✅ Report CRUD test passed")
 }
+
+func TestCleanupCRUD(t *testing.T) {
+	// 创建临时数据库
+	dbPath := "/tmp/test_cleanups.db"
+	defer os.Remove(dbPath)
+
+	store, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	// 没有记录时返回 nil
+	got, err := store.GetLatestCleanup("test-789", "42")
+	if err != nil {
+		t.Fatalf("Failed to get cleanup: %v", err)
+	}
+	if got != nil {
+		t.Fatalf("Expected nil cleanup, got %+v", got)
+	}
+
+	// 先洗版，后删除
+	for _, action := range []CleanupAction{CleanupBestVersion, CleanupDeleted} {
+		cleanup := &SubscriptionCleanup{
+			SourceRequestID: "test-789",
+			MPSubscribeID:   "42",
+			Action:          action,
+		}
+		if err := store.SaveCleanup(cleanup); err != nil {
+			t.Fatalf("Failed to save cleanup: %v", err)
+		}
+	}
+
+	// 最近一次应为删除
+	got, err = store.GetLatestCleanup("test-789", "42")
+	if err != nil {
+		t.Fatalf("Failed to get cleanup: %v", err)
+	}
+	if got == nil || got.Action != CleanupDeleted {
+		t.Errorf("Expected latest action 'deleted', got %+v", got)
+	}
+
+	// 同一请求的其他订阅互不影响
+	got, err = store.GetLatestCleanup("test-789", "43")
+	if err != nil {
+		t.Fatalf("Failed to get cleanup: %v", err)
+	}
+	if got != nil {
+		t.Errorf("Expected nil cleanup for another subscription, got %+v", got)
+	}
+
+	cleanups, err := store.ListCleanups(10)
+	if err != nil {
+		t.Fatalf("Failed to list cleanups: %v", err)
+	}
+	if len(cleanups) != 2 {
+		t.Errorf("Expected 2 cleanups, got %d", len(cleanups))
+	}
+
+	t.Log("✅ Cleanup CRUD test passed")
+}
+
+func TestEpisodeTracking(t *testing.T) {
+	dbPath := "/tmp/test_episodes.db"
+	defer os.Remove(dbPath)
+
+	store, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	// 第 2 季共 3 集
+	for _, number := range []int{1, 2, 3} {
+		episode := &EpisodeTracking{
+			SourceRequestID: "test-tv",
+			Season:          2,
+			Episode:         number,
+			Status:          TrackingPending,
+		}
+		if err := store.SaveEpisode(episode); err != nil {
+			t.Fatalf("Failed to save episode: %v", err)
+		}
+	}
+
+	// 重复保存同一集应更新而不是新增
+	now := time.Now()
+	episode := &EpisodeTracking{
+		SourceRequestID: "test-tv",
+		Season:          2,
+		Episode:         1,
+		Status:          TrackingTransferred,
+		TransferTime:    &now,
+	}
+	if err := store.SaveEpisode(episode); err != nil {
+		t.Fatalf("Failed to update episode: %v", err)
+	}
+
+	episodes, err := store.ListEpisodes("test-tv")
+	if err != nil {
+		t.Fatalf("Failed to list episodes: %v", err)
+	}
+	if len(episodes) != 3 {
+		t.Fatalf("Expected 3 episodes, got %d", len(episodes))
+	}
+	if episodes[0].Episode != 1 || episodes[0].Status != TrackingTransferred {
+		t.Errorf("Expected episode 1 transferred, got E%d %s", episodes[0].Episode, episodes[0].Status)
+	}
+
+	progress := SummarizeEpisodes(episodes)
+	if got := FormatProgress(progress); got != "S02: 1/3" {
+		t.Errorf("Expected progress 'S02: 1/3', got %q", got)
+	}
+
+	// 播出日期：未知视为已播出，未来日期为待播出
+	airDate := time.Date(2030, 1, 1, 0, 0, 0, 0, time.Local)
+	episodes[2].AirDate = &airDate
+	episodes[2].Finale = true
+	episodes[2].LateTime = &now
+	if err := store.SaveEpisode(episodes[2]); err != nil {
+		t.Fatalf("Failed to save air date: %v", err)
+	}
+	episodes, err = store.ListEpisodes("test-tv")
+	if err != nil {
+		t.Fatalf("Failed to list episodes: %v", err)
+	}
+	if episodes[2].AirDate == nil || !episodes[2].AirDate.Equal(airDate) {
+		t.Errorf("Expected air date %v, got %v", airDate, episodes[2].AirDate)
+	}
+	if !episodes[2].Finale || episodes[2].LateTime == nil || episodes[1].Finale {
+		t.Errorf("Expected episode 3 finale with late time, got %+v", episodes[2])
+	}
+	if !episodes[1].Aired(now) || episodes[2].Aired(now) {
+		t.Error("Expected episode 2 aired and episode 3 upcoming")
+	}
+
+	missing := []*EpisodeTracking{
+		{Season: 2, Episode: 1}, {Season: 1, Episode: 5}, {Season: 1, Episode: 3}, {Season: 1, Episode: 4},
+	}
+	if got := FormatEpisodes(missing); got != "S01E03-E05, S02E01" {
+		t.Errorf("Expected 'S01E03-E05, S02E01', got %q", got)
+	}
+}
+
+func TestTrackerState(t *testing.T) {
+	dbPath := "/tmp/test_tracker_state.db"
+	defer os.Remove(dbPath)
+
+	store, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	// 不存在的键返回空字符串
+	value, err := store.GetTrackerState("download_history_cursor")
+	if err != nil {
+		t.Fatalf("Failed to get state: %v", err)
+	}
+	if value != "" {
+		t.Errorf("Expected empty value, got %q", value)
+	}
+
+	if err := store.SetTrackerState("download_history_cursor", "100"); err != nil {
+		t.Fatalf("Failed to set state: %v", err)
+	}
+	if err := store.SetTrackerState("download_history_cursor", "250"); err != nil {
+		t.Fatalf("Failed to overwrite state: %v", err)
+	}
+
+	value, err = store.GetTrackerState("download_history_cursor")
+	if err != nil {
+		t.Fatalf("Failed to get state: %v", err)
+	}
+	if value != "250" {
+		t.Errorf("Expected '250', got %q", value)
+	}
+}
+
+func TestCountEventsSince(t *testing.T) {
+	dbPath := "/tmp/test_count_events.db"
+	defer os.Remove(dbPath)
+
+	store, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	since := time.Now().Add(-time.Minute)
+	for _, eventType := range []EventType{EventSubscribed, EventSubscribed, EventTransferComplete} {
+		if err := store.SaveEvent(&DownloadEvent{SourceRequestID: "test-789", EventType: eventType}); err != nil {
+			t.Fatalf("Failed to save event: %v", err)
+		}
+	}
+
+	counts, err := store.CountEventsSince(since)
+	if err != nil {
+		t.Fatalf("Failed to count events: %v", err)
+	}
+	if counts[EventSubscribed] != 2 || counts[EventTransferComplete] != 1 {
+		t.Errorf("Unexpected counts: %v", counts)
+	}
+
+	counts, err = store.CountEventsSince(time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to count events: %v", err)
+	}
+	if len(counts) != 0 {
+		t.Errorf("Expected no events in the future, got %v", counts)
+	}
+}
+
+func TestUnmatchedEvents(t *testing.T) {
+	dbPath := "/tmp/test_unmatched_events.db"
+	defer os.Remove(dbPath)
+
+	store, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	for _, name := range []string{"Dune", "Andor"} {
+		event := &UnmatchedMediaEvent{
+			Source:    "jellyfin",
+			EventType: "ItemAdded",
+			ItemType:  "Movie",
+			ItemName:  name,
+			Reason:    "no tracking record",
+			Payload:   `{"NotificationType":"ItemAdded"}`,
+		}
+		if err := store.SaveUnmatchedEvent(event); err != nil {
+			t.Fatalf("Failed to save unmatched event: %v", err)
+		}
+		if event.ID == 0 {
+			t.Error("Expected event ID to be set")
+		}
+	}
+
+	events, err := store.ListUnmatchedEvents(1)
+	if err != nil {
+		t.Fatalf("Failed to list unmatched events: %v", err)
+	}
+	if len(events) != 1 || events[0].ItemName != "Andor" || events[0].Payload == "" {
+		t.Errorf("Expected latest event Andor with payload, got %+v", events)
+	}
+}
+
+func TestRequestLatency(t *testing.T) {
+	dbPath := "/tmp/test_request_latency.db"
+	defer os.Remove(dbPath)
+
+	store, err := NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	completed := time.Date(2025, 1, 22, 10, 0, 0, 0, time.Local)
+	for i, total := range []time.Duration{5 * time.Hour, 50 * time.Hour} {
+		latency := &RequestLatency{
+			SourceRequestID: []string{"movie-1", "tv-1"}[i],
+			Title:           "Title",
+			MediaType:       []MediaType{MediaTypeMovie, MediaTypeTV}[i],
+			CompletedAt:     completed,
+			Week:            "2025-01-20",
+			Stages:          map[LatencyStage]time.Duration{StageDownload: time.Hour, StageTotal: total},
+		}
+		if err := store.SaveRequestLatency(latency); err != nil {
+			t.Fatalf("Failed to save latency: %v", err)
+		}
+	}
+
+	latencies, err := store.ListRequestLatency(completed.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to list latency: %v", err)
+	}
+	if len(latencies) != 2 {
+		t.Fatalf("Expected 2 latencies, got %d", len(latencies))
+	}
+	if _, ok := latencies[0].Stages[StageSearch]; ok || latencies[0].Stages[StageDownload] != time.Hour {
+		t.Errorf("Unexpected stages %v", latencies[0].Stages)
+	}
+
+	slowest, err := store.ListSlowestRequests(completed.Add(-time.Hour), 1)
+	if err != nil {
+		t.Fatalf("Failed to list slowest: %v", err)
+	}
+	if len(slowest) != 1 || slowest[0].SourceRequestID != "tv-1" {
+		t.Errorf("Unexpected slowest %+v", slowest)
+	}
+
+	stat := &LatencyStat{Week: "2025-01-20", MediaType: MediaTypeTV, Stage: StageTotal, Samples: 1, P50: time.Hour, P90: 2 * time.Hour, Max: 3 * time.Hour}
+	if err := store.SaveLatencyStat(stat); err != nil {
+		t.Fatalf("Failed to save stat: %v", err)
+	}
+	stat.Samples = 2
+	if err := store.SaveLatencyStat(stat); err != nil {
+		t.Fatalf("Failed to update stat: %v", err)
+	}
+	stats, err := store.ListLatencyStats("2025-01-13")
+	if err != nil {
+		t.Fatalf("Failed to list stats: %v", err)
+	}
+	if len(stats) != 1 || stats[0].Samples != 2 || stats[0].Max != 3*time.Hour {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	if stats, _ := store.ListLatencyStats("2025-01-27"); len(stats) != 0 {
+		t.Errorf("Expected no stats after week, got %d", len(stats))
+	}
+}
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SaveUnmatchedEvent 保存未匹配的媒体服务器事件
+func (s *SQLiteStore) SaveUnmatchedEvent(event *UnmatchedMediaEvent) error {
+	event.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO unmatched_media_events (
+			source, event_type, item_type, item_name, tmdb_id, season, episode, reason, payload, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := s.db.Exec(query,
+		event.Source, event.EventType, event.ItemType, event.ItemName, event.TMDBID,
+		event.Season, event.Episode, event.Reason, event.Payload, event.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if id, err := result.LastInsertId(); err == nil {
+		event.ID = id
+	}
+	return nil
+}
+
+// ListUnmatchedEvents 列出最近的未匹配事件（limit <= 0 表示不限制）
+func (s *SQLiteStore) ListUnmatchedEvents(limit int) ([]*UnmatchedMediaEvent, error) {
+	query := `
+		SELECT id, source, event_type, item_type, item_name, tmdb_id, season, episode, reason, payload, created_at
+		FROM unmatched_media_events
+		ORDER BY id DESC
+		LIMIT ?
+	`
+
+	rows, err := s.db.Query(query, sqlLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*UnmatchedMediaEvent
+	for rows.Next() {
+		event := &UnmatchedMediaEvent{}
+		var payload sql.NullString
+		err := rows.Scan(
+			&event.ID, &event.Source, &event.EventType, &event.ItemType, &event.ItemName,
+			&event.TMDBID, &event.Season, &event.Episode, &event.Reason, &payload, &event.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		event.Payload = payload.String
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
@@ -0,0 +1,109 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// deliveryColumns webhook_deliveries 查询列，顺序与 scanDelivery 一致
+const deliveryColumns = `id, url, event, source_request_id, payload, status, attempts,
+	status_code, last_error, delivered_at, created_at, updated_at`
+
+// SaveWebhookDelivery 新增投递记录
+func (s *SQLiteStore) SaveWebhookDelivery(delivery *WebhookDelivery) error {
+	now := time.Now()
+	delivery.CreatedAt = now
+	delivery.UpdatedAt = now
+	if delivery.Status == "" {
+		delivery.Status = DeliveryPending
+	}
+
+	query := `
+		INSERT INTO webhook_deliveries (
+			url, event, source_request_id, payload, status, attempts,
+			status_code, last_error, delivered_at, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := s.db.Exec(query,
+		delivery.URL, delivery.Event, delivery.SourceRequestID, delivery.Payload, delivery.Status,
+		delivery.Attempts, delivery.StatusCode, delivery.LastError, delivery.DeliveredAt,
+		delivery.CreatedAt, delivery.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if id, err := result.LastInsertId(); err == nil {
+		delivery.ID = id
+	}
+	return nil
+}
+
+// UpdateWebhookDelivery 更新投递结果
+func (s *SQLiteStore) UpdateWebhookDelivery(delivery *WebhookDelivery) error {
+	delivery.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE webhook_deliveries SET
+			status = ?, attempts = ?, status_code = ?, last_error = ?, delivered_at = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	_, err := s.db.Exec(query,
+		delivery.Status, delivery.Attempts, delivery.StatusCode, delivery.LastError,
+		delivery.DeliveredAt, delivery.UpdatedAt, delivery.ID,
+	)
+	return err
+}
+
+// GetWebhookDelivery 获取投递记录
+func (s *SQLiteStore) GetWebhookDelivery(id int64) (*WebhookDelivery, error) {
+	delivery, err := scanDelivery(s.db.QueryRow(`SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// ListWebhookDeliveries 按状态列出投递记录（按创建时间从早到晚），status 为空表示全部
+func (s *SQLiteStore) ListWebhookDeliveries(status DeliveryStatus, limit int) ([]*WebhookDelivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries
+		WHERE (? = '' OR status = ?)
+		ORDER BY id ASC
+		LIMIT ?`
+
+	rows, err := s.db.Query(query, status, status, sqlLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// scanDelivery 扫描单条投递记录
+func scanDelivery(scanner interface{ Scan(...any) error }) (*WebhookDelivery, error) {
+	delivery := &WebhookDelivery{}
+	err := scanner.Scan(
+		&delivery.ID, &delivery.URL, &delivery.Event, &delivery.SourceRequestID, &delivery.Payload,
+		&delivery.Status, &delivery.Attempts, &delivery.StatusCode, &delivery.LastError,
+		&delivery.DeliveredAt, &delivery.CreatedAt, &delivery.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
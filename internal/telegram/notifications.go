@@ -122,6 +122,20 @@ func (b *Bot) sendPhotoAsync(photoURL, caption string) {
 	}()
 }
 
+// NotifyResourceFound MP 已找到资源通知
+func (b *Bot) NotifyResourceFound(title, username string) {
+	msg := fmt.Sprintf(
+		"🎯 <b>已找到资源</b>\n\n"+
+			"📺 %s\n"+
+			"👤 用户: %s\n"+
+			"⏰ %s",
+		html.EscapeString(title),
+		html.EscapeString(username),
+		time.Now().Format("2006-01-02 15:04:05"),
+	)
+	b.SendMessageAsync(msg)
+}
+
 // NotifyDownloadStarted 开始下载通知
 func (b *Bot) NotifyDownloadStarted(title string) {
 	msg := fmt.Sprintf(
@@ -146,6 +160,25 @@ func (b *Bot) NotifyDownloadComplete(title string) {
 	b.SendMessageAsync(msg)
 }
 
+// NotifyDownloadProgress 下载进度通知
+func (b *Bot) NotifyDownloadProgress(title string, progress float64, speed, eta string) {
+	msg := fmt.Sprintf(
+		"⏬ <b>下载进度</b>\n\n"+
+			"📺 %s\n"+
+			"📊 进度: %.1f%%\n",
+		html.EscapeString(title),
+		progress,
+	)
+	if speed != "" {
+		msg += fmt.Sprintf("🚀 速度: %s\n", html.EscapeString(speed))
+	}
+	if eta != "" {
+		msg += fmt.Sprintf("⏳ 剩余: %s\n", html.EscapeString(eta))
+	}
+	msg += fmt.Sprintf("⏰ %s", time.Now().Format("2006-01-02 15:04:05"))
+	b.SendMessageAsync(msg)
+}
+
 // NotifyTransferComplete 入库成功通知
 func (b *Bot) NotifyTransferComplete(title string) {
 	msg := fmt.Sprintf(
@@ -158,6 +191,73 @@ func (b *Bot) NotifyTransferComplete(title string) {
 	b.SendMessageAsync(msg)
 }
 
+// NotifyAvailable 媒体服务器中可观看通知
+func (b *Bot) NotifyAvailable(title string) {
+	msg := fmt.Sprintf(
+		"🍿 <b>可以观看了</b>\n\n"+
+			"📺 %s\n"+
+			"⏰ %s",
+		html.EscapeString(title),
+		time.Now().Format("2006-01-02 15:04:05"),
+	)
+	b.SendMessageAsync(msg)
+}
+
+// NotifyEpisodesTransferred 剧集新集入库通知
+// episodes 为本次入库的集（如 "S03E05"），missing 为已播出但尚未入库的集
+func (b *Bot) NotifyEpisodesTransferred(title, episodes, progress, missing string) {
+	msg := fmt.Sprintf(
+		"🆕 <b>%s 已入库</b>\n\n"+
+			"📺 %s\n"+
+			"📊 进度: %s\n",
+		html.EscapeString(episodes),
+		html.EscapeString(title),
+		html.EscapeString(progress),
+	)
+	if missing != "" {
+		msg += fmt.Sprintf("⚠️ 缺失: %s\n", html.EscapeString(missing))
+	}
+	msg += fmt.Sprintf("⏰ %s", time.Now().Format("2006-01-02 15:04:05"))
+	b.SendMessageAsync(msg)
+}
+
+// NotifyEpisodesLate 剧集播出后超时未下载通知
+func (b *Bot) NotifyEpisodesLate(title, episodes string, hours int) {
+	msg := fmt.Sprintf(
+		"⏰ <b>剧集更新延迟</b>\n\n"+
+			"📺 %s\n"+
+			"🕒 %s 已播出超过 %d 小时仍未下载\n"+
+			"⏰ %s",
+		html.EscapeString(title),
+		html.EscapeString(episodes),
+		hours,
+		time.Now().Format("2006-01-02 15:04:05"),
+	)
+	b.SendMessageAsync(msg)
+}
+
+// NotifyTorrentUnhealthy 种子异常通知，replaced 表示已让 MP 删除种子并重新搜索
+func (b *Bot) NotifyTorrentUnhealthy(title, torrent, reason string, replaced bool) {
+	action := "请检查下载器或在 MP 中更换资源"
+	if replaced {
+		action = "已让 MP 删除该种子并重新搜索"
+	}
+	msg := fmt.Sprintf(
+		"🩺 <b>种子异常</b>\n\n"+
+			"📺 %s\n"+
+			"🧲 %s\n"+
+			"💬 %s\n"+
+			"🔧 %s\n"+
+			"⏰ %s",
+		html.EscapeString(title),
+		html.EscapeString(torrent),
+		html.EscapeString(reason),
+		action,
+		time.Now().Format("2006-01-02 15:04:05"),
+	)
+	b.SendMessageAsync(msg)
+}
+
 // NotifyFailed 失败通知
 func (b *Bot) NotifyFailed(title, reason string) {
 	msg := fmt.Sprintf(
@@ -187,6 +287,22 @@ func (b *Bot) NotifyRetrying(title string, attempt, maxAttempts int) {
 	b.SendMessageAsync(msg)
 }
 
+// NotifySubscriptionDrift 订阅状态不一致通知
+func (b *Bot) NotifySubscriptionDrift(title, reason, action string) {
+	msg := fmt.Sprintf(
+		"🧭 <b>订阅状态异常</b>\n\n"+
+			"📺 %s\n"+
+			"💬 %s\n"+
+			"🔧 处理: %s\n"+
+			"⏰ %s",
+		html.EscapeString(title),
+		html.EscapeString(reason),
+		html.EscapeString(action),
+		time.Now().Format("2006-01-02 15:04:05"),
+	)
+	b.SendMessageAsync(msg)
+}
+
 // NotifyDailyReport 每日报告通知
 func (b *Bot) NotifyDailyReport(report string) {
 	msg := fmt.Sprintf(
@@ -220,4 +336,3 @@ func getMediaTypeEmoji(mediaType string) string {
 		return mediaType
 	}
 }
-
@@ -0,0 +1,52 @@
+package telegram
+
+import (
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/notify"
+)
+
+// Name 实现 notify.Notifier
+func (b *Bot) Name() string {
+	return "telegram"
+}
+
+// Notify 实现 notify.Notifier，按事件类型发送对应格式的消息
+func (b *Bot) Notify(n *notify.Notification) {
+	if !b.enabled {
+		return
+	}
+
+	switch n.Event {
+	case notify.EventSubscribed:
+		b.NotifySubscribed(n.Title, n.MediaType, n.TMDBID, n.PosterPath)
+	case notify.EventAlreadyExists:
+		b.NotifyAlreadyExists(n.Title, n.MediaType, n.TMDBID, n.PosterPath)
+	case notify.EventResourceFound:
+		b.NotifyResourceFound(n.Title, n.Username)
+	case notify.EventDownloadStarted:
+		b.NotifyDownloadStarted(n.Title)
+	case notify.EventDownloadProgress:
+		b.NotifyDownloadProgress(n.Title, n.Progress, n.Speed, n.ETA)
+	case notify.EventDownloadComplete:
+		b.NotifyDownloadComplete(n.Title)
+	case notify.EventTransferred:
+		b.NotifyTransferComplete(n.Title)
+	case notify.EventAvailable:
+		b.NotifyAvailable(n.Title)
+	case notify.EventEpisodesTransferred:
+		b.NotifyEpisodesTransferred(n.Title, n.Episodes, n.EpisodeProgress, n.Missing)
+	case notify.EventEpisodesLate:
+		b.NotifyEpisodesLate(n.Title, n.Episodes, n.LateHours)
+	case notify.EventTorrentUnhealthy:
+		b.NotifyTorrentUnhealthy(n.Title, n.Torrent, n.Reason, n.Replaced)
+	case notify.EventFailed:
+		b.NotifyFailed(n.Title, n.Reason)
+	case notify.EventRetrying:
+		b.NotifyRetrying(n.Title, n.Attempt, n.MaxAttempts)
+	case notify.EventDrift:
+		b.NotifySubscriptionDrift(n.Title, n.Reason, n.Action)
+	case notify.EventReport:
+		b.NotifyDailyReport(n.Report)
+	case notify.EventError:
+		b.NotifyError(n.Reason)
+	}
+}
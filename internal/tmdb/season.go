@@ -0,0 +1,81 @@
+package tmdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// airDateLayout TMDB 播出日期格式
+const airDateLayout = "2006-01-02"
+
+// Episode 单集信息
+type Episode struct {
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	SeasonNumber  int    `json:"season_number"`
+	EpisodeNumber int    `json:"episode_number"`
+	AirDate       string `json:"air_date"`     // YYYY-MM-DD，未定档时为空
+	EpisodeType   string `json:"episode_type"` // standard、mid_season 或 finale，旧数据可能为空
+}
+
+// IsFinale 是否为季终集
+func (e *Episode) IsFinale() bool {
+	return e.EpisodeType == "finale"
+}
+
+// AirTime 解析播出日期（本地时区零点），未定档时返回 false
+func (e *Episode) AirTime() (time.Time, bool) {
+	if e.AirDate == "" {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(airDateLayout, e.AirDate, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// SeasonDetails 季详情
+type SeasonDetails struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	SeasonNumber int       `json:"season_number"`
+	AirDate      string    `json:"air_date"`
+	Episodes     []Episode `json:"episodes"`
+}
+
+// GetSeasonDetails 获取季详情（包含每集的播出日期）
+func (c *Client) GetSeasonDetails(ctx context.Context, tvID, seasonNumber int) (*SeasonDetails, error) {
+	if c == nil {
+		return nil, fmt.Errorf("tmdb client not initialized")
+	}
+
+	url := fmt.Sprintf("%s/tv/%d/season/%d?api_key=%s&language=zh-CN", c.baseURL, tvID, seasonNumber, c.apiKey)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+	}
+
+	var details SeasonDetails
+	if err := json.NewDecoder(resp.Body).Decode(&details); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &details, nil
+}
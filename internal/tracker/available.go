@@ -0,0 +1,178 @@
+package tracker
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mediaserver"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+// checkAvailability 在媒体服务器中确认已入库的请求是否可观看
+// 只检查入库后 MediaServerConfirmHours 小时内的记录，超时后保持已入库状态
+func (t *Tracker) checkAvailability() error {
+	if t.mediaServer == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	transferred, err := t.store.ListTrackingByStatus(store.TrackingTransferred, 0)
+	if err != nil {
+		return fmt.Errorf("list transferred tracking: %w", err)
+	}
+
+	window := time.Duration(t.cfg.MediaServerConfirmHours) * time.Hour
+	now := time.Now()
+	for _, record := range transferred {
+		if t.ctx.Err() != nil {
+			return nil
+		}
+		if record.TransferTime == nil || now.Sub(*record.TransferTime) > window {
+			continue
+		}
+
+		available, err := t.confirmAvailable(record)
+		if err != nil {
+			t.logger.Warn("Failed to check media server",
+				zap.String("title", record.Title),
+				zap.Int("tmdb_id", record.TMDBID),
+				zap.Error(err),
+			)
+			continue
+		}
+		if available {
+			t.markAvailable(record)
+			continue
+		}
+
+		if t.cfg.MediaServerScan && !t.scanRequested[record.SourceRequestID] {
+			t.requestLibraryScan(record)
+		}
+	}
+
+	return nil
+}
+
+// confirmAvailable 判断请求的媒体是否已出现在媒体服务器中
+// 剧集需要请求的每一集（或每一季至少一集）都已出现
+func (t *Tracker) confirmAvailable(record *store.SubscriptionTracking) (bool, error) {
+	itemType := mediaserver.ItemMovie
+	if record.MediaType == store.MediaTypeTV {
+		itemType = mediaserver.ItemSeries
+	}
+
+	item, err := t.mediaServer.FindByTMDBID(t.ctx, record.TMDBID, itemType, record.Title)
+	if err != nil {
+		return false, fmt.Errorf("find item: %w", err)
+	}
+	if item == nil {
+		return false, nil
+	}
+	if record.MediaType != store.MediaTypeTV {
+		return true, nil
+	}
+
+	wanted, err := t.wantedEpisodes(record)
+	if err != nil || len(wanted) == 0 {
+		return err == nil, err
+	}
+
+	episodes, err := t.mediaServer.ListEpisodes(t.ctx, item.ID)
+	if err != nil {
+		return false, fmt.Errorf("list episodes: %w", err)
+	}
+	present := make(map[episodeKey]bool, len(episodes))
+	for _, episode := range episodes {
+		present[episodeKey{episode.ParentIndexNumber, episode.IndexNumber}] = true
+		// 键 episode 为 0 表示该季至少有一集
+		present[episodeKey{episode.ParentIndexNumber, 0}] = true
+	}
+
+	for _, key := range wanted {
+		if !present[key] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// wantedEpisodes 返回需要在媒体服务器中确认的集
+// 有单集跟踪时按集确认，否则按请求的季确认（episode 为 0）
+func (t *Tracker) wantedEpisodes(record *store.SubscriptionTracking) ([]episodeKey, error) {
+	episodes, err := t.store.ListEpisodes(record.SourceRequestID)
+	if err != nil {
+		return nil, fmt.Errorf("list episodes: %w", err)
+	}
+
+	var wanted []episodeKey
+	for _, episode := range episodes {
+		wanted = append(wanted, episodeKey{episode.Season, episode.Episode})
+	}
+	if len(wanted) > 0 {
+		return wanted, nil
+	}
+
+	req, err := t.store.GetRequest(record.SourceRequestID)
+	if err != nil {
+		return nil, fmt.Errorf("get request: %w", err)
+	}
+	if req == nil {
+		return nil, nil
+	}
+	seasons, err := req.GetSeasons()
+	if err != nil {
+		return nil, nil
+	}
+	for _, season := range seasons {
+		wanted = append(wanted, episodeKey{season, 0})
+	}
+	return wanted, nil
+}
+
+// requestLibraryScan 通知媒体服务器扫描入库目标目录（每个请求只触发一次）
+func (t *Tracker) requestLibraryScan(record *store.SubscriptionTracking) {
+	dir := ""
+	if record.TransferPath != "" {
+		dir = path.Dir(record.TransferPath)
+	}
+
+	if err := t.mediaServer.NotifyPathUpdated(t.ctx, dir); err != nil {
+		t.logger.Warn("Failed to trigger library scan",
+			zap.String("title", record.Title),
+			zap.String("path", dir),
+			zap.Error(err),
+		)
+		return
+	}
+
+	t.scanRequested[record.SourceRequestID] = true
+	t.logger.Info("Triggered library scan",
+		zap.String("title", record.Title),
+		zap.String("path", dir),
+	)
+}
+
+// markAvailable 将跟踪记录标记为可观看并通知
+func (t *Tracker) markAvailable(record *store.SubscriptionTracking) {
+	t.logger.Info("Media available in media server",
+		zap.String("title", record.Title),
+		zap.Int("tmdb_id", record.TMDBID),
+	)
+
+	now := time.Now()
+	payload := &store.AvailablePayload{}
+	if record.TransferTime != nil {
+		payload.Waited = now.Sub(*record.TransferTime).Round(time.Second).String()
+	}
+
+	err := t.lifecycle.Apply(record, Change{To: store.TrackingAvailable, Payload: payload, At: now})
+	if err != nil {
+		t.logger.Error("Failed to update tracking", zap.Error(err))
+		return
+	}
+	delete(t.scanRequested, record.SourceRequestID)
+}
@@ -0,0 +1,207 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+// 已完成订阅的处理策略
+const (
+	completedPolicyKeep        = "keep"         // 保留订阅
+	completedPolicyDelete      = "delete"       // 立即删除订阅
+	completedPolicyBestVersion = "best_version" // 洗版 N 天后删除
+)
+
+// runCleanup 定期清理已入库请求的 MP 订阅
+func (t *Tracker) runCleanup() {
+	defer t.wg.Done()
+
+	interval := time.Duration(t.cfg.MPCleanupInterval) * time.Minute
+	t.logger.Info("Subscription cleanup started",
+		zap.Duration("interval", interval),
+		zap.String("policy", t.cfg.MPCompletedPolicy),
+		zap.Int("best_version_days", t.cfg.MPBestVersionDays),
+	)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.ctx.Done():
+			t.logger.Info("Subscription cleanup stopped")
+			return
+		case <-ticker.C:
+			if err := t.cleanupCompletedSubscriptions(); err != nil {
+				t.logger.Error("Failed to clean up subscriptions", zap.Error(err))
+			}
+		}
+	}
+}
+
+// cleanupCompletedSubscriptions 按策略处理已入库请求的订阅
+func (t *Tracker) cleanupCompletedSubscriptions() error {
+	completed, err := t.store.ListTrackingByStatus(store.TrackingTransferred, 0)
+	if err != nil {
+		return fmt.Errorf("list transferred tracking: %w", err)
+	}
+	available, err := t.store.ListTrackingByStatus(store.TrackingAvailable, 0)
+	if err != nil {
+		return fmt.Errorf("list available tracking: %w", err)
+	}
+	completed = append(completed, available...)
+
+	for _, record := range completed {
+		if t.ctx.Err() != nil {
+			return nil
+		}
+
+		link, err := t.store.GetMPLink(record.SourceRequestID)
+		if err != nil {
+			t.logger.Error("Failed to get mp link", zap.Error(err))
+			continue
+		}
+		if link == nil || link.State != store.StatusSynced {
+			continue
+		}
+
+		for _, subscribeID := range t.requestSubscribeIDs(record, link) {
+			t.cleanupSubscription(record, subscribeID)
+		}
+	}
+
+	return nil
+}
+
+// requestSubscribeIDs 返回请求对应的全部 MP 订阅
+// 链接只保存了第一季的订阅，其余季按 TMDB ID 和季号向 MP 查询
+func (t *Tracker) requestSubscribeIDs(record *store.SubscriptionTracking, link *store.MPLink) []int {
+	var ids []int
+	if id, err := strconv.Atoi(link.MPSubscribeID); err == nil && id > 0 {
+		ids = append(ids, id)
+	}
+	if record.MediaType != store.MediaTypeTV || t.cfg.MPCompletedPolicy == completedPolicyKeep {
+		return ids
+	}
+
+	episodes, err := t.store.ListEpisodes(record.SourceRequestID)
+	if err != nil {
+		t.logger.Error("Failed to list episodes", zap.Error(err))
+		return ids
+	}
+	seen := make(map[int]bool)
+	for _, episode := range episodes {
+		if seen[episode.Season] {
+			continue
+		}
+		seen[episode.Season] = true
+
+		sub, err := t.mpClient.GetSubscribeByMedia(t.ctx, record.TMDBID, episode.Season)
+		if err != nil {
+			t.logger.Warn("Failed to get season subscription",
+				zap.String("source_request_id", record.SourceRequestID),
+				zap.Int("season", episode.Season),
+				zap.Error(err),
+			)
+			continue
+		}
+		if sub != nil && !slices.Contains(ids, sub.ID) {
+			ids = append(ids, sub.ID)
+		}
+	}
+	return ids
+}
+
+// cleanupSubscription 按策略处理请求下的一个订阅
+func (t *Tracker) cleanupSubscription(record *store.SubscriptionTracking, subscribeID int) {
+	mpSubscribeID := strconv.Itoa(subscribeID)
+	last, err := t.store.GetLatestCleanup(record.SourceRequestID, mpSubscribeID)
+	if err != nil {
+		t.logger.Error("Failed to get latest cleanup", zap.Error(err))
+		return
+	}
+
+	action, detail, err := t.nextCleanupAction(subscribeID, last)
+	if err != nil {
+		t.logger.Warn("Subscription cleanup failed",
+			zap.String("source_request_id", record.SourceRequestID),
+			zap.Int("subscribe_id", subscribeID),
+			zap.Error(err),
+		)
+		return
+	}
+	if action == "" {
+		return
+	}
+
+	cleanup := &store.SubscriptionCleanup{
+		SourceRequestID: record.SourceRequestID,
+		MPSubscribeID:   mpSubscribeID,
+		Action:          action,
+		Detail:          detail,
+	}
+	if err := t.store.SaveCleanup(cleanup); err != nil {
+		t.logger.Error("Failed to save cleanup", zap.Error(err))
+		return
+	}
+
+	t.logger.Info("Subscription cleaned up",
+		zap.String("source_request_id", record.SourceRequestID),
+		zap.String("title", record.Title),
+		zap.Int("subscribe_id", subscribeID),
+		zap.String("action", string(action)),
+	)
+}
+
+// nextCleanupAction 根据策略和上次清理记录执行下一步动作
+// 返回空动作表示本轮无需处理
+func (t *Tracker) nextCleanupAction(subscribeID int, last *store.SubscriptionCleanup) (store.CleanupAction, string, error) {
+	if last != nil {
+		switch last.Action {
+		case store.CleanupDeleted, store.CleanupGone:
+			return "", "", nil
+		case store.CleanupBestVersion:
+			days := t.cfg.MPBestVersionDays
+			if time.Since(last.CreatedAt) < time.Duration(days)*24*time.Hour {
+				return "", "", nil
+			}
+			return t.deleteSubscription(subscribeID, fmt.Sprintf("洗版 %d 天后删除", days))
+		}
+	}
+
+	switch t.cfg.MPCompletedPolicy {
+	case completedPolicyDelete:
+		return t.deleteSubscription(subscribeID, "入库后删除")
+	case completedPolicyBestVersion:
+		err := t.mpClient.SetBestVersion(t.ctx, subscribeID, true)
+		if errors.Is(err, mp.ErrSubscribeNotFound) {
+			return store.CleanupGone, "订阅已不存在", nil
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("enable best version: %w", err)
+		}
+		return store.CleanupBestVersion, fmt.Sprintf("洗版 %d 天", t.cfg.MPBestVersionDays), nil
+	default:
+		// keep：保留订阅，不做任何处理
+		return "", "", nil
+	}
+}
+
+// deleteSubscription 删除 MP 订阅
+func (t *Tracker) deleteSubscription(subscribeID int, detail string) (store.CleanupAction, string, error) {
+	err := t.mpClient.DeleteSubscribe(t.ctx, subscribeID)
+	if errors.Is(err, mp.ErrSubscribeNotFound) {
+		return store.CleanupGone, "订阅已不存在", nil
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("delete subscribe: %w", err)
+	}
+	return store.CleanupDeleted, detail, nil
+}
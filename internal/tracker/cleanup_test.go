@@ -0,0 +1,83 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/configs"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+// TestCleanupAllSeasonSubscriptions 多季请求的每个季订阅都按策略处理
+func TestCleanupAllSeasonSubscriptions(t *testing.T) {
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v1/login/access-token":
+			json.NewEncoder(w).Encode(mp.LoginResponse{AccessToken: "token", TokenType: "bearer"})
+			return
+		case r.URL.Path == "/api/v1/subscribe/media/tmdb:100":
+			season := r.URL.Query().Get("season")
+			w.Write([]byte(`{"id": 1` + season + `, "tmdbid": 100, "season": ` + season + `}`))
+			return
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/api/v1/subscribe/"):
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/api/v1/subscribe/"))
+		}
+		w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+
+	mpClient, err := mp.NewClient(mp.ClientConfig{BaseURL: server.URL, RateLimitPS: 100}, context.Background())
+	if err != nil {
+		t.Fatalf("create mp client: %v", err)
+	}
+
+	st := newTestStore(t)
+	record := &store.SubscriptionTracking{
+		SourceRequestID: "tv-1",
+		TMDBID:          100,
+		Title:           "测试",
+		MediaType:       store.MediaTypeTV,
+		SubscribeStatus: store.TrackingTransferred,
+	}
+	if err := st.SaveTracking(record); err != nil {
+		t.Fatalf("save tracking: %v", err)
+	}
+	for _, season := range []int{1, 2} {
+		episode := &store.EpisodeTracking{SourceRequestID: "tv-1", Season: season, Episode: 1, Status: store.TrackingTransferred}
+		if err := st.SaveEpisode(episode); err != nil {
+			t.Fatalf("save episode: %v", err)
+		}
+	}
+	// 链接只保存了第一季的订阅
+	if err := st.SaveMPLink(&store.MPLink{SourceRequestID: "tv-1", MPSubscribeID: "11", State: store.StatusSynced}); err != nil {
+		t.Fatalf("save mp link: %v", err)
+	}
+
+	tr := &Tracker{
+		cfg:      &configs.Config{MPCompletedPolicy: completedPolicyDelete},
+		mpClient: mpClient,
+		store:    st,
+		logger:   zap.NewNop(),
+		ctx:      context.Background(),
+	}
+
+	// 第二轮不再重复删除
+	for range 2 {
+		if err := tr.cleanupCompletedSubscriptions(); err != nil {
+			t.Fatalf("cleanup: %v", err)
+		}
+	}
+
+	slices.Sort(deleted)
+	if !slices.Equal(deleted, []string{"11", "12"}) {
+		t.Errorf("deleted subscriptions = %v, want [11 12]", deleted)
+	}
+}
@@ -0,0 +1,418 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/notify"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+// episodeKey 单集索引
+type episodeKey struct {
+	season  int
+	episode int
+}
+
+// expectedEpisode 请求需要的一集
+type expectedEpisode struct {
+	season  int
+	episode int
+	airDate *time.Time // 未知时为空
+	finale  bool       // 请求范围内的季终集
+}
+
+// ensureEpisodes 返回剧集请求的单集跟踪记录，首次调用时按请求内容创建
+// 无法确定集数时返回 nil，调用方按整条记录处理
+func (t *Tracker) ensureEpisodes(record *store.SubscriptionTracking) ([]*store.EpisodeTracking, error) {
+	episodes, err := t.store.ListEpisodes(record.SourceRequestID)
+	if err != nil {
+		return nil, fmt.Errorf("list episodes: %w", err)
+	}
+	if len(episodes) > 0 {
+		return episodes, nil
+	}
+
+	expected, err := t.expectedEpisodes(record)
+	if err != nil || len(expected) == 0 {
+		return nil, err
+	}
+
+	for _, e := range expected {
+		episode := &store.EpisodeTracking{
+			SourceRequestID: record.SourceRequestID,
+			Season:          e.season,
+			Episode:         e.episode,
+			Status:          store.TrackingPending,
+			AirDate:         e.airDate,
+			Finale:          e.finale,
+		}
+		if err := t.store.SaveEpisode(episode); err != nil {
+			return nil, fmt.Errorf("save episode: %w", err)
+		}
+	}
+
+	return t.store.ListEpisodes(record.SourceRequestID)
+}
+
+// expectedEpisodes 计算请求需要的全部集
+// 配置了 TMDB 时使用季详情中的集列表和播出日期；否则优先使用 Jellyseerr 请求中的集列表，
+// 再按 MP 订阅的开始集数和总集数推算
+func (t *Tracker) expectedEpisodes(record *store.SubscriptionTracking) ([]expectedEpisode, error) {
+	req, err := t.store.GetRequest(record.SourceRequestID)
+	if err != nil {
+		return nil, fmt.Errorf("get request: %w", err)
+	}
+	if req == nil {
+		return nil, nil
+	}
+
+	seasons, err := req.GetSeasons()
+	if err != nil || len(seasons) == 0 {
+		return nil, nil
+	}
+	requested, err := req.GetEpisodes()
+	if err != nil {
+		requested = map[int][]int{}
+	}
+
+	var expected []expectedEpisode
+	for _, season := range seasons {
+		if aired := t.tmdbEpisodes(record, season, requested[season]); len(aired) > 0 {
+			expected = append(expected, aired...)
+			continue
+		}
+
+		if numbers := requested[season]; len(numbers) > 0 {
+			for _, number := range numbers {
+				expected = append(expected, expectedEpisode{season: season, episode: number})
+			}
+			continue
+		}
+
+		sub, err := t.mpClient.GetSubscribeByMedia(t.ctx, record.TMDBID, season)
+		if err != nil {
+			return nil, fmt.Errorf("get subscribe for season %d: %w", season, err)
+		}
+		// 任意一季集数未知时放弃单集跟踪
+		if sub == nil || sub.TotalEpisode <= 0 {
+			return nil, nil
+		}
+
+		start := sub.StartEpisode
+		if start <= 0 {
+			start = 1
+		}
+		for number := start; number <= sub.TotalEpisode; number++ {
+			expected = append(expected, expectedEpisode{season: season, episode: number})
+		}
+	}
+
+	return expected, nil
+}
+
+// tmdbEpisodes 从 TMDB 季详情获取集列表、播出日期和季终集
+// requested 非空时只保留请求中的集；未定档的集暂不跟踪，定档后由播出计划刷新补充
+// 未配置 TMDB 或请求失败时返回 nil
+func (t *Tracker) tmdbEpisodes(record *store.SubscriptionTracking, season int, requested []int) []expectedEpisode {
+	if t.seasons == nil {
+		return nil
+	}
+
+	details, err := t.seasons.GetSeasonDetails(t.ctx, record.TMDBID, season)
+	if err != nil {
+		t.logger.Warn("Failed to get TMDB season details",
+			zap.String("title", record.Title),
+			zap.Int("season", season),
+			zap.Error(err),
+		)
+		return nil
+	}
+
+	wanted := make(map[int]bool, len(requested))
+	for _, number := range requested {
+		wanted[number] = true
+	}
+
+	// 请求指定了集时以最后一集为季终集；TMDB 有集类型但没有季终集说明本季仍在连载
+	finale, last, typed := 0, 0, false
+	for i := range details.Episodes {
+		episode := &details.Episodes[i]
+		if len(wanted) > 0 && !wanted[episode.EpisodeNumber] {
+			continue
+		}
+		if episode.EpisodeNumber > last {
+			last = episode.EpisodeNumber
+		}
+		if episode.EpisodeType != "" {
+			typed = true
+		}
+		if episode.IsFinale() {
+			finale = episode.EpisodeNumber
+		}
+	}
+	if len(wanted) > 0 || !typed {
+		finale = last
+	}
+
+	var expected []expectedEpisode
+	for i := range details.Episodes {
+		episode := &details.Episodes[i]
+		if len(wanted) > 0 && !wanted[episode.EpisodeNumber] {
+			continue
+		}
+		airTime, ok := episode.AirTime()
+		if !ok {
+			continue
+		}
+		expected = append(expected, expectedEpisode{
+			season:  season,
+			episode: episode.EpisodeNumber,
+			airDate: &airTime,
+			finale:  finale > 0 && episode.EpisodeNumber == finale,
+		})
+	}
+	return expected
+}
+
+// markEpisodesDownloading 将下载记录涉及的集标记为下载中，返回保存失败的错误
+func (t *Tracker) markEpisodesDownloading(record *store.SubscriptionTracking, item *mp.DownloadHistoryItem) error {
+	episodes, err := t.ensureEpisodes(record)
+	if err != nil {
+		t.logger.Warn("Failed to load episodes",
+			zap.String("source_request_id", record.SourceRequestID),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	now := time.Now()
+	var errs []error
+	for _, episode := range matchEpisodes(episodes, item.SeasonEpisodes()) {
+		if episode.Status != store.TrackingPending {
+			continue
+		}
+		episode.Status = store.TrackingDownloading
+		episode.DownloadTime = &now
+		if err := t.store.SaveEpisode(episode); err != nil {
+			t.logger.Error("Failed to save episode", zap.Error(err))
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// processEpisodeTransfers 按集处理剧集入库，返回保存失败的错误
+// 所有请求的集（连载剧集需包含季终集）都入库后才将整条记录标记为已入库，否则逐集通知
+func (t *Tracker) processEpisodeTransfers(record *store.SubscriptionTracking, items []mp.TransferHistoryItem) error {
+	episodes, err := t.ensureEpisodes(record)
+	if err != nil {
+		t.logger.Warn("Failed to load episodes",
+			zap.String("source_request_id", record.SourceRequestID),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	// 集数未知，退回整条记录的处理方式
+	if len(episodes) == 0 {
+		if CanTransition(record.SubscribeStatus, store.TrackingTransferred) {
+			return t.markTransferred(record)
+		}
+		return nil
+	}
+
+	now := time.Now()
+	var arrived []*store.EpisodeTracking
+	var errs []error
+	for _, item := range items {
+		for _, episode := range matchEpisodes(episodes, item.SeasonEpisodes()) {
+			if episode.Status == store.TrackingTransferred {
+				continue
+			}
+			status, transferTime := episode.Status, episode.TransferTime
+			episode.Status = store.TrackingTransferred
+			episode.TransferTime = &now
+			if err := t.store.SaveEpisode(episode); err != nil {
+				t.logger.Error("Failed to save episode", zap.Error(err))
+				episode.Status, episode.TransferTime = status, transferTime
+				errs = append(errs, err)
+				continue
+			}
+			arrived = append(arrived, episode)
+		}
+	}
+	if len(arrived) == 0 {
+		return errors.Join(errs...)
+	}
+
+	// 季终集入库、请求的集全部到齐时由入库完成通知代替单集通知
+	done, err := t.updateSeasonStatus(record, episodes, now)
+	errs = append(errs, err)
+	if done {
+		return errors.Join(errs...)
+	}
+
+	progress := store.SummarizeEpisodes(episodes)
+	summary := store.FormatProgress(progress)
+
+	t.logger.Info("Episodes transferred",
+		zap.String("title", record.Title),
+		zap.Int("tmdb_id", record.TMDBID),
+		zap.String("episodes", store.FormatEpisodes(arrived)),
+		zap.String("progress", summary),
+		zap.String("missing", record.MissingEpisodes),
+	)
+
+	// 每集只在入库时通知一次
+	n := recordNotification(notify.EventEpisodesTransferred, record)
+	n.Episodes = store.FormatEpisodes(arrived)
+	n.EpisodeProgress = summary
+	n.Missing = record.MissingEpisodes
+	t.notify(n)
+
+	event, err := store.NewEvent(record.SourceRequestID, &store.EpisodesArrivedPayload{
+		EventBase: store.EventBase{TMDBID: record.TMDBID, Title: record.Title},
+		Arrived:   len(arrived),
+		Progress:  progress,
+	})
+	if err != nil {
+		t.logger.Error("Failed to encode event", zap.Error(err))
+		return errors.Join(errs...)
+	}
+	if err := t.store.SaveEvent(event); err != nil {
+		t.logger.Error("Failed to save event", zap.Error(err))
+	}
+	return errors.Join(errs...)
+}
+
+// updateSeasonStatus 按单集情况更新剧集记录的状态，返回请求是否已完成和保存失败的错误
+// 已播出的集有缺失时为部分入库；已播出的集均已入库但还有集未播出或季终集尚未定档时为连载中；
+// 请求的集全部入库且包含季终集时才标记为已入库
+func (t *Tracker) updateSeasonStatus(record *store.SubscriptionTracking, episodes []*store.EpisodeTracking, now time.Time) (bool, error) {
+	missing, upcoming := episodeCompleteness(episodes, now)
+	progress := store.SummarizeEpisodes(episodes)
+
+	switch {
+	case len(missing) > 0:
+		return false, t.markPartiallyTransferred(record, missing, upcoming, progress)
+	case len(upcoming) > 0 || awaitingFinale(episodes, now):
+		return false, t.markAiring(record, upcoming, progress)
+	default:
+		if CanTransition(record.SubscribeStatus, store.TrackingTransferred) {
+			return true, t.markTransferred(record)
+		}
+		return true, nil
+	}
+}
+
+// finaleGracePeriod 季的最后一集播出后超过该时间仍没有新集定档，即使 TMDB 没有标记季终集也视为本季已完结
+const finaleGracePeriod = 60 * 24 * time.Hour
+
+// awaitingFinale 按 TMDB 播出计划跟踪的季是否还在等待季终集
+// 没有播出日期的季来自 Jellyseerr 或 MP 的集数，以请求的集为准；
+// TMDB 可能一直不标记季终集，最后一集播出超过 finaleGracePeriod 后不再等待
+func awaitingFinale(episodes []*store.EpisodeTracking, now time.Time) bool {
+	lastAired := make(map[int]time.Time)
+	finale := make(map[int]bool)
+	for _, episode := range episodes {
+		if episode.AirDate != nil && episode.AirDate.After(lastAired[episode.Season]) {
+			lastAired[episode.Season] = *episode.AirDate
+		}
+		if episode.Finale {
+			finale[episode.Season] = true
+		}
+	}
+	for season, last := range lastAired {
+		if !finale[season] && now.Sub(last) < finaleGracePeriod {
+			return true
+		}
+	}
+	return false
+}
+
+// episodeCompleteness 区分已播出但未入库的集和尚未播出的集
+func episodeCompleteness(episodes []*store.EpisodeTracking, now time.Time) (missing, upcoming []*store.EpisodeTracking) {
+	for _, episode := range episodes {
+		if episode.Status == store.TrackingTransferred {
+			continue
+		}
+		if episode.Aired(now) {
+			missing = append(missing, episode)
+		} else {
+			upcoming = append(upcoming, episode)
+		}
+	}
+	return missing, upcoming
+}
+
+// markPartiallyTransferred 将剧集记录标记为部分入库，并更新缺失的集
+func (t *Tracker) markPartiallyTransferred(record *store.SubscriptionTracking, missing, upcoming []*store.EpisodeTracking, progress []store.SeasonProgress) error {
+	list := store.FormatEpisodes(missing)
+
+	// 已是部分入库时只更新缺失列表
+	if record.SubscribeStatus == store.TrackingPartiallyTransferred {
+		if record.MissingEpisodes == list {
+			return nil
+		}
+		record.MissingEpisodes = list
+		if err := t.store.UpdateTracking(record); err != nil {
+			t.logger.Error("Failed to update tracking", zap.Error(err))
+			return err
+		}
+		return nil
+	}
+	if !CanTransition(record.SubscribeStatus, store.TrackingPartiallyTransferred) {
+		return nil
+	}
+
+	record.MissingEpisodes = list
+	return t.apply(record, Change{
+		To: store.TrackingPartiallyTransferred,
+		Payload: &store.PartiallyTransferredPayload{
+			Missing:  list,
+			Upcoming: len(upcoming),
+			Progress: progress,
+		},
+	})
+}
+
+// matchEpisodes 返回 季 -> 集 映射命中的单集记录
+// 集列表为空表示整季（季包）
+func matchEpisodes(episodes []*store.EpisodeTracking, seasonEpisodes map[int][]int) []*store.EpisodeTracking {
+	wanted := make(map[episodeKey]bool)
+	for season, numbers := range seasonEpisodes {
+		for _, number := range numbers {
+			wanted[episodeKey{season, number}] = true
+		}
+	}
+
+	var matched []*store.EpisodeTracking
+	for _, episode := range episodes {
+		numbers, ok := seasonEpisodes[episode.Season]
+		if !ok {
+			continue
+		}
+		if len(numbers) == 0 || wanted[episodeKey{episode.Season, episode.Episode}] {
+			matched = append(matched, episode)
+		}
+	}
+	return matched
+}
+
+// markAiring 将剧集记录标记为连载中，等待后续集播出
+func (t *Tracker) markAiring(record *store.SubscriptionTracking, upcoming []*store.EpisodeTracking, progress []store.SeasonProgress) error {
+	if record.SubscribeStatus == store.TrackingAiring || !CanTransition(record.SubscribeStatus, store.TrackingAiring) {
+		return nil
+	}
+
+	payload := &store.AiringPayload{Upcoming: len(upcoming), Progress: progress}
+	if len(upcoming) > 0 {
+		payload.NextEpisode = store.FormatEpisodes(upcoming[:1])
+		payload.NextAirDate = upcoming[0].AirDate
+	}
+	return t.apply(record, Change{To: store.TrackingAiring, Payload: payload})
+}
@@ -0,0 +1,276 @@
+package tracker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/configs"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/tmdb"
+	"go.uber.org/zap"
+)
+
+// fakeSeasons 固定返回的季详情
+type fakeSeasons map[int]*tmdb.SeasonDetails
+
+func (f fakeSeasons) GetSeasonDetails(ctx context.Context, tvID, seasonNumber int) (*tmdb.SeasonDetails, error) {
+	return f[seasonNumber], nil
+}
+
+func TestSeasonCompleteness(t *testing.T) {
+	st := newTestStore(t)
+
+	req := &store.Request{
+		SourceRequestID: "tv-1",
+		MediaType:       store.MediaTypeTV,
+		TMDBID:          1399,
+		Title:           "测试剧",
+		Status:          store.StatusSynced,
+		RequestedAt:     time.Now().Add(-72 * time.Hour),
+	}
+	req.SetSeasons([]int{1})
+	if err := st.SaveRequest(req); err != nil {
+		t.Fatalf("save request: %v", err)
+	}
+
+	record := seedTracking(t, st, "tv-1", store.TrackingDownloading)
+
+	// 前 4 集已播出，第 5 集下周播出
+	day := func(offset int) string { return time.Now().AddDate(0, 0, offset).Format("2006-01-02") }
+	tr := &Tracker{
+		cfg:    &configs.Config{},
+		store:  st,
+		logger: zap.NewNop(),
+		ctx:    context.Background(),
+		seasons: fakeSeasons{1: {SeasonNumber: 1, Episodes: []tmdb.Episode{
+			{SeasonNumber: 1, EpisodeNumber: 1, AirDate: day(-28)},
+			{SeasonNumber: 1, EpisodeNumber: 2, AirDate: day(-21)},
+			{SeasonNumber: 1, EpisodeNumber: 3, AirDate: day(-14)},
+			{SeasonNumber: 1, EpisodeNumber: 4, AirDate: day(-7)},
+			{SeasonNumber: 1, EpisodeNumber: 5, AirDate: day(7)},
+		}}},
+	}
+	tr.lifecycle = NewStateMachine(st, nil)
+
+	transfer := func(episodes string) {
+		t.Helper()
+		tr.processEpisodeTransfers(record, []mp.TransferHistoryItem{{Seasons: "S01", Episodes: episodes, Status: mp.TransferSuccess}})
+		saved, err := st.GetTracking("tv-1")
+		if err != nil || saved == nil {
+			t.Fatalf("get tracking: %v", err)
+		}
+		record = saved
+	}
+
+	// 缺少已播出的第 3、4 集
+	transfer("E01-E02")
+	if record.SubscribeStatus != store.TrackingPartiallyTransferred || record.MissingEpisodes != "S01E03-E04" {
+		t.Fatalf("expected partially transferred missing S01E03-E04, got %s %q", record.SubscribeStatus, record.MissingEpisodes)
+	}
+
+	episodes, err := st.ListEpisodes("tv-1")
+	if err != nil || len(episodes) != 5 {
+		t.Fatalf("expected 5 episodes, got %d (%v)", len(episodes), err)
+	}
+	if episodes[4].AirDate == nil || episodes[4].Aired(time.Now()) {
+		t.Errorf("episode 5 should carry a future air date, got %v", episodes[4].AirDate)
+	}
+
+	transfer("E03")
+	if record.SubscribeStatus != store.TrackingPartiallyTransferred || record.MissingEpisodes != "S01E04" {
+		t.Fatalf("expected missing S01E04, got %s %q", record.SubscribeStatus, record.MissingEpisodes)
+	}
+
+	// 已播出的集全部入库，未播出的第 5 集不算缺失，等待季终集
+	transfer("E04")
+	if record.SubscribeStatus != store.TrackingAiring || record.MissingEpisodes != "" {
+		t.Errorf("expected airing without missing episodes, got %s %q", record.SubscribeStatus, record.MissingEpisodes)
+	}
+
+	// 季终集入库后完成
+	transfer("E05")
+	if record.SubscribeStatus != store.TrackingTransferred {
+		t.Errorf("expected transferred after finale, got %s", record.SubscribeStatus)
+	}
+
+	events, _ := st.QueryEvents(store.EventQuery{SourceRequestID: "tv-1", Types: []store.EventType{store.EventPartiallyTransferred}})
+	if len(events) != 1 {
+		t.Fatalf("expected one partially transferred event, got %d", len(events))
+	}
+	payload, err := events[0].Payload()
+	if err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if partial := payload.(*store.PartiallyTransferredPayload); partial.Missing != "S01E03-E04" || partial.Upcoming != 1 {
+		t.Errorf("unexpected payload %+v", partial)
+	}
+}
+
+func TestTMDBFinale(t *testing.T) {
+	day := func(offset int) string { return time.Now().AddDate(0, 0, offset).Format("2006-01-02") }
+	record := &store.SubscriptionTracking{TMDBID: 1, Title: "测试剧"}
+	finales := func(tr *Tracker, requested []int) map[int]bool {
+		result := make(map[int]bool)
+		for _, e := range tr.tmdbEpisodes(record, 1, requested) {
+			result[e.episode] = e.finale
+		}
+		return result
+	}
+
+	// 连载中：TMDB 有集类型但没有季终集，未定档的集不跟踪
+	tr := &Tracker{logger: zap.NewNop(), ctx: context.Background(), seasons: fakeSeasons{1: {Episodes: []tmdb.Episode{
+		{EpisodeNumber: 1, AirDate: day(-7), EpisodeType: "standard"},
+		{EpisodeNumber: 2, AirDate: day(0), EpisodeType: "standard"},
+		{EpisodeNumber: 3, EpisodeType: "standard"},
+	}}}}
+	if got := finales(tr, nil); len(got) != 2 || got[1] || got[2] {
+		t.Errorf("airing season: unexpected episodes %v", got)
+	}
+	// 只请求部分集时以最后一集为准
+	if got := finales(tr, []int{1}); len(got) != 1 || !got[1] {
+		t.Errorf("requested episodes: unexpected episodes %v", got)
+	}
+
+	// 旧数据没有集类型，以最后一集为季终集
+	tr.seasons = fakeSeasons{1: {Episodes: []tmdb.Episode{
+		{EpisodeNumber: 1, AirDate: day(-14)},
+		{EpisodeNumber: 2, AirDate: day(-7)},
+	}}}
+	if got := finales(tr, nil); !got[2] || got[1] {
+		t.Errorf("untyped season: unexpected episodes %v", got)
+	}
+}
+
+// TestAwaitingFinale TMDB 一直没有标记季终集时，最后一集播出足够久后不再等待
+func TestAwaitingFinale(t *testing.T) {
+	now := time.Now()
+	aired := func(days int) *time.Time {
+		at := now.AddDate(0, 0, -days)
+		return &at
+	}
+
+	// 刚播出一周，可能还有集没定档
+	recent := []*store.EpisodeTracking{
+		{Season: 1, Episode: 1, AirDate: aired(14)},
+		{Season: 1, Episode: 2, AirDate: aired(7)},
+	}
+	if !awaitingFinale(recent, now) {
+		t.Error("recently aired season should still await its finale")
+	}
+
+	// 最后一集播出已超过宽限期
+	ended := []*store.EpisodeTracking{
+		{Season: 1, Episode: 1, AirDate: aired(100)},
+		{Season: 1, Episode: 2, AirDate: aired(93)},
+	}
+	if awaitingFinale(ended, now) {
+		t.Error("season past the grace period should not await a finale")
+	}
+
+	// 标记了季终集的季不需要等待；另一季仍在宽限期内
+	mixed := []*store.EpisodeTracking{
+		{Season: 1, Episode: 1, AirDate: aired(7), Finale: true},
+		{Season: 2, Episode: 1, AirDate: aired(100)},
+		{Season: 2, Episode: 2, AirDate: aired(3)},
+	}
+	if !awaitingFinale(mixed, now) {
+		t.Error("season 2 should still await its finale")
+	}
+	if awaitingFinale(mixed[:1], now) {
+		t.Error("season with a finale should not await one")
+	}
+}
+
+func TestAiringSchedule(t *testing.T) {
+	st := newTestStore(t)
+
+	req := &store.Request{
+		SourceRequestID: "tv-1",
+		MediaType:       store.MediaTypeTV,
+		TMDBID:          1399,
+		Title:           "测试剧",
+		Status:          store.StatusSynced,
+		RequestedAt:     time.Now().Add(-30 * 24 * time.Hour),
+	}
+	req.SetSeasons([]int{3})
+	if err := st.SaveRequest(req); err != nil {
+		t.Fatalf("save request: %v", err)
+	}
+
+	subscribed := time.Now().Add(-30 * 24 * time.Hour)
+	record := &store.SubscriptionTracking{
+		SourceRequestID: "tv-1",
+		TMDBID:          1399,
+		Title:           "测试剧",
+		MediaType:       store.MediaTypeTV,
+		SubscribeStatus: store.TrackingSubscribed,
+		SubscribeTime:   &subscribed,
+	}
+	if err := st.SaveTracking(record); err != nil {
+		t.Fatalf("save tracking: %v", err)
+	}
+
+	// 第 3 季已播出 2 集，第 3 集尚未定档
+	day := func(offset int) string { return time.Now().AddDate(0, 0, offset).Format("2006-01-02") }
+	seasons := fakeSeasons{3: {SeasonNumber: 3, Episodes: []tmdb.Episode{
+		{SeasonNumber: 3, EpisodeNumber: 1, AirDate: day(-10), EpisodeType: "standard"},
+		{SeasonNumber: 3, EpisodeNumber: 2, AirDate: day(-3), EpisodeType: "standard"},
+		{SeasonNumber: 3, EpisodeNumber: 3, EpisodeType: "finale"},
+	}}}
+	tr := &Tracker{
+		cfg:               &configs.Config{TrackerEpisodeLateHours: 24},
+		store:             st,
+		logger:            zap.NewNop(),
+		ctx:               context.Background(),
+		seasons:           seasons,
+		scheduleRefreshed: make(map[string]time.Time),
+	}
+	tr.lifecycle = NewStateMachine(st, nil)
+
+	now := time.Now()
+	if err := tr.checkAiringSchedule(now); err != nil {
+		t.Fatalf("check airing schedule: %v", err)
+	}
+	episodes, _ := st.ListEpisodes("tv-1")
+	if len(episodes) != 2 || episodes[0].LateTime == nil || episodes[1].LateTime == nil {
+		t.Fatalf("expected 2 late episodes, got %+v", episodes)
+	}
+
+	// 每集只提醒一次
+	if err := tr.checkAiringSchedule(now.Add(time.Hour)); err != nil {
+		t.Fatalf("check airing schedule: %v", err)
+	}
+	events, _ := st.QueryEvents(store.EventQuery{SourceRequestID: "tv-1", Types: []store.EventType{store.EventEpisodesLate}})
+	if len(events) != 1 {
+		t.Fatalf("expected one late event, got %d", len(events))
+	}
+
+	// 已播出的集入库后为连载中
+	tr.processEpisodeTransfers(record, []mp.TransferHistoryItem{{Seasons: "S03", Episodes: "E01-E02", Status: mp.TransferSuccess}})
+	record, _ = st.GetTracking("tv-1")
+	if record.SubscribeStatus != store.TrackingAiring {
+		t.Fatalf("expected airing, got %s", record.SubscribeStatus)
+	}
+
+	// 季终集定档后刷新播出计划，补充新的集
+	seasons[3].Episodes[2].AirDate = day(5)
+	if err := tr.checkAiringSchedule(now.Add(scheduleRefreshInterval)); err != nil {
+		t.Fatalf("check airing schedule: %v", err)
+	}
+	episodes, _ = st.ListEpisodes("tv-1")
+	if len(episodes) != 3 || !episodes[2].Finale || episodes[2].Status != store.TrackingPending {
+		t.Fatalf("expected finale to be tracked, got %+v", episodes)
+	}
+	record, _ = st.GetTracking("tv-1")
+	if record.SubscribeStatus != store.TrackingAiring {
+		t.Errorf("expected airing until finale arrives, got %s", record.SubscribeStatus)
+	}
+
+	tr.processEpisodeTransfers(record, []mp.TransferHistoryItem{{Seasons: "S03", Episodes: "E03", Status: mp.TransferSuccess}})
+	record, _ = st.GetTracking("tv-1")
+	if record.SubscribeStatus != store.TrackingTransferred {
+		t.Errorf("expected transferred after finale, got %s", record.SubscribeStatus)
+	}
+}
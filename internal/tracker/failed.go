@@ -0,0 +1,90 @@
+package tracker
+
+import (
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+// markTransferFailed 将跟踪记录标记为入库失败，按配置请求 MP 重新整理
+func (t *Tracker) markTransferFailed(record *store.SubscriptionTracking, item mp.TransferHistoryItem) error {
+	reason := item.ErrMsg
+	if reason == "" {
+		reason = "入库失败"
+	}
+
+	t.logger.Warn("Transfer failed",
+		zap.String("title", record.Title),
+		zap.Int("tmdb_id", record.TMDBID),
+		zap.Int("history_id", item.ID),
+		zap.String("reason", reason),
+	)
+
+	record.ErrorMessage = reason
+
+	retried := false
+	// 重新整理与看门狗搜索分别计数，互不占用次数
+	if t.cfg.MPRetryTransfer && record.TransferRetryCount < t.cfg.SmartRetryMaxAttempts {
+		if err := t.mpClient.RetryTransfer(t.ctx, item.ID); err != nil {
+			t.logger.Warn("Failed to retry transfer",
+				zap.String("title", record.Title),
+				zap.Int("history_id", item.ID),
+				zap.Error(err),
+			)
+		} else {
+			retried = true
+			record.TransferRetryCount++
+		}
+	}
+
+	return t.apply(record, Change{
+		To: store.TrackingFailed,
+		Payload: &store.FailedPayload{
+			HistoryID: item.ID,
+			Seasons:   item.Seasons,
+			Episodes:  item.Episodes,
+			Reason:    reason,
+			Retried:   retried,
+		},
+	})
+}
+
+// processEpisodeFailures 处理剧集的失败入库记录，返回保存失败的错误
+// 仅当失败的集尚未成功入库时才将请求标记为失败
+func (t *Tracker) processEpisodeFailures(record *store.SubscriptionTracking, items []mp.TransferHistoryItem) error {
+	episodes, err := t.ensureEpisodes(record)
+	if err != nil {
+		t.logger.Warn("Failed to load episodes",
+			zap.String("source_request_id", record.SourceRequestID),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	// 集数未知，按整条记录处理
+	if len(episodes) == 0 {
+		return t.markTransferFailed(record, items[0])
+	}
+
+	for _, item := range items {
+		failed := 0
+		for _, episode := range matchEpisodes(episodes, item.SeasonEpisodes()) {
+			if episode.Status == store.TrackingTransferred {
+				continue
+			}
+			failed++
+			if episode.Status == store.TrackingFailed {
+				continue
+			}
+			episode.Status = store.TrackingFailed
+			if err := t.store.SaveEpisode(episode); err != nil {
+				t.logger.Error("Failed to save episode", zap.Error(err))
+				return err
+			}
+		}
+		if failed > 0 {
+			return t.markTransferFailed(record, item)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/configs"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+// TestRetryBudgets 看门狗搜索和重新整理分别计数，互不占用次数
+func TestRetryBudgets(t *testing.T) {
+	transfers, searches := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v1/login/access-token":
+			json.NewEncoder(w).Encode(mp.LoginResponse{AccessToken: "token", TokenType: "bearer"})
+			return
+		case strings.HasPrefix(r.URL.Path, "/api/v1/subscribe/search/"):
+			searches++
+		case r.URL.Path == "/api/v1/transfer/manual":
+			transfers++
+		}
+		w.Write([]byte(`{"success": true}`))
+	}))
+	defer server.Close()
+
+	mpClient, err := mp.NewClient(mp.ClientConfig{BaseURL: server.URL, RateLimitPS: 100}, context.Background())
+	if err != nil {
+		t.Fatalf("create mp client: %v", err)
+	}
+
+	st := newTestStore(t)
+	record := seedTracking(t, st, "budget-1", store.TrackingSubscribed)
+	if err := st.SaveMPLink(&store.MPLink{SourceRequestID: "budget-1", MPSubscribeID: "9", State: store.StatusSynced}); err != nil {
+		t.Fatalf("save mp link: %v", err)
+	}
+
+	tr := &Tracker{
+		cfg: &configs.Config{
+			MPRetryTransfer:        true,
+			SmartRetryMaxAttempts:  1,
+			SmartRetryInitialDelay: 24,
+		},
+		mpClient: mpClient,
+		store:    st,
+		logger:   zap.NewNop(),
+		ctx:      context.Background(),
+	}
+	tr.lifecycle = NewStateMachine(st, nil)
+
+	// 用完看门狗的搜索次数
+	tr.searchStalled(record)
+	if searches != 1 || record.RetryCount != 1 {
+		t.Fatalf("searches = %d, retry count = %d", searches, record.RetryCount)
+	}
+	if tr.searchDue(record, time.Now().Add(365*24*time.Hour)) {
+		t.Error("search budget should be exhausted")
+	}
+
+	// 入库失败仍可请求重新整理
+	tr.markTransferFailed(record, mp.TransferHistoryItem{ID: 42, ErrMsg: "目标路径不存在"})
+	if transfers != 1 || record.TransferRetryCount != 1 || record.RetryCount != 1 {
+		t.Fatalf("transfers = %d, transfer retry count = %d, retry count = %d",
+			transfers, record.TransferRetryCount, record.RetryCount)
+	}
+
+	// 重新整理次数用完后不再请求
+	tr.markTransferFailed(record, mp.TransferHistoryItem{ID: 43})
+	if transfers != 1 {
+		t.Errorf("transfers = %d, want 1", transfers)
+	}
+
+	saved, _ := st.GetTracking("budget-1")
+	if saved.RetryCount != 1 || saved.TransferRetryCount != 1 {
+		t.Errorf("saved counts = %d / %d, want 1 / 1", saved.RetryCount, saved.TransferRetryCount)
+	}
+}
@@ -0,0 +1,227 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/downloader"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/notify"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+// trackTorrent 按下载历史中的 hash 关联种子和跟踪记录
+func (t *Tracker) trackTorrent(record *store.SubscriptionTracking, item *mp.DownloadHistoryItem) {
+	if t.downloader == nil || item.DownloadHash == "" {
+		return
+	}
+
+	err := t.store.TrackTorrent(&store.TrackedTorrent{
+		Hash:            item.DownloadHash,
+		SourceRequestID: record.SourceRequestID,
+		Name:            item.TorrentName,
+	})
+	if err != nil {
+		t.logger.Error("Failed to track torrent",
+			zap.String("hash", item.DownloadHash),
+			zap.Error(err),
+		)
+	}
+}
+
+// classifyTorrent 判断种子的健康问题
+// 暂停和排队视为正常，避免对用户手动暂停的种子告警
+func classifyTorrent(torrent *downloader.Torrent) (store.TorrentIssue, string) {
+	switch torrent.State {
+	case downloader.StateError:
+		return store.TorrentErrored, torrent.Error
+	case downloader.StateDownloading, downloader.StateStalled:
+		if torrent.Seeders == 0 && torrent.SwarmSeeders == 0 {
+			return store.TorrentNoSeeders, ""
+		}
+		if torrent.State == downloader.StateStalled {
+			return store.TorrentStalled, fmt.Sprintf("进度 %.1f%%", torrent.Progress)
+		}
+	}
+	return store.TorrentHealthy, ""
+}
+
+// torrentIssueLabel 健康问题的中文描述
+func torrentIssueLabel(issue store.TorrentIssue) string {
+	switch issue {
+	case store.TorrentStalled:
+		return "下载停滞"
+	case store.TorrentNoSeeders:
+		return "没有做种者"
+	case store.TorrentErrored:
+		return "下载出错"
+	default:
+		return "正常"
+	}
+}
+
+// checkTorrentHealth 直接查询下载器，检查跟踪请求的种子
+// 出错的种子立即告警；停滞或没有做种者持续 DOWNLOADER_STALL_HOURS 小时后告警，每次出现问题只告警一次
+func (t *Tracker) checkTorrentHealth(now time.Time) error {
+	if t.downloader == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tracked, err := t.store.ListActiveTorrents()
+	if err != nil {
+		return fmt.Errorf("list active torrents: %w", err)
+	}
+	if len(tracked) == 0 {
+		return nil
+	}
+
+	hashes := make([]string, len(tracked))
+	for i, torrent := range tracked {
+		hashes[i] = torrent.Hash
+	}
+	torrents, err := t.downloader.GetTorrents(t.ctx, hashes)
+	if err != nil {
+		return fmt.Errorf("get torrents from %s: %w", t.downloader.Type(), err)
+	}
+	byHash := make(map[string]*downloader.Torrent, len(torrents))
+	for i := range torrents {
+		byHash[torrents[i].Hash] = &torrents[i]
+	}
+
+	for _, entry := range tracked {
+		record, err := t.store.GetTracking(entry.SourceRequestID)
+		if err != nil {
+			t.logger.Error("Failed to get tracking", zap.Error(err))
+			continue
+		}
+
+		torrent := byHash[entry.Hash]
+		switch {
+		case torrent == nil, torrent.State == downloader.StateCompleted, record == nil, record.SubscribeStatus.IsCompleted():
+			// 已完成、已从下载器移除或请求已入库，不再检查
+			entry.ResolvedAt = &now
+			if torrent != nil {
+				entry.State = string(torrent.State)
+			}
+		default:
+			t.updateTorrentHealth(record, entry, torrent, now)
+		}
+
+		if err := t.store.UpdateTorrent(entry); err != nil {
+			t.logger.Error("Failed to update torrent", zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// updateTorrentHealth 更新单个种子的健康状态，达到告警条件时告警并按配置更换资源
+func (t *Tracker) updateTorrentHealth(record *store.SubscriptionTracking, entry *store.TrackedTorrent, torrent *downloader.Torrent, now time.Time) {
+	entry.Name = torrent.Name
+	entry.State = string(torrent.State)
+
+	issue, detail := classifyTorrent(torrent)
+	if issue == store.TorrentHealthy {
+		// 恢复正常后清空问题，再次出现时重新计时和告警
+		entry.Issue = store.TorrentHealthy
+		entry.IssueDetail = ""
+		entry.IssueSince = nil
+		entry.AlertedAt = nil
+		return
+	}
+
+	entry.Issue = issue
+	entry.IssueDetail = detail
+	if entry.IssueSince == nil {
+		entry.IssueSince = &now
+	}
+	if entry.AlertedAt != nil {
+		return
+	}
+	grace := time.Duration(t.cfg.DownloaderStallHours) * time.Hour
+	if issue != store.TorrentErrored && now.Sub(*entry.IssueSince) < grace {
+		return
+	}
+
+	entry.AlertedAt = &now
+	replaced := false
+	if t.cfg.DownloaderReplace && entry.ReplacedAt == nil {
+		replaced = t.replaceTorrent(record, entry)
+		if replaced {
+			entry.ReplacedAt = &now
+			entry.ResolvedAt = &now
+		}
+	}
+
+	t.logger.Warn("Unhealthy torrent",
+		zap.String("title", record.Title),
+		zap.String("hash", entry.Hash),
+		zap.String("name", entry.Name),
+		zap.String("issue", string(issue)),
+		zap.String("detail", detail),
+		zap.Bool("replaced", replaced),
+	)
+
+	reason := torrentIssueLabel(issue)
+	if detail != "" {
+		reason += "（" + detail + "）"
+	}
+	n := recordNotification(notify.EventTorrentUnhealthy, record)
+	n.Torrent = entry.Name
+	n.Reason = reason
+	n.Replaced = replaced
+	t.notify(n)
+
+	event, err := store.NewEvent(record.SourceRequestID, &store.TorrentUnhealthyPayload{
+		EventBase: store.EventBase{TMDBID: record.TMDBID, Title: record.Title},
+		Hash:      entry.Hash,
+		Name:      entry.Name,
+		Issue:     issue,
+		Detail:    detail,
+		Replaced:  replaced,
+	})
+	if err != nil {
+		t.logger.Error("Failed to encode event", zap.Error(err))
+		return
+	}
+	if err := t.store.SaveEvent(event); err != nil {
+		t.logger.Error("Failed to save event", zap.Error(err))
+	}
+}
+
+// replaceTorrent 让 MP 删除有问题的种子并重新搜索订阅，使其选择其他资源
+func (t *Tracker) replaceTorrent(record *store.SubscriptionTracking, entry *store.TrackedTorrent) bool {
+	if err := t.mpClient.DeleteDownload(t.ctx, entry.Hash); err != nil {
+		t.logger.Warn("Failed to delete torrent in MP",
+			zap.String("title", record.Title),
+			zap.String("hash", entry.Hash),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	link, err := t.store.GetMPLink(record.SourceRequestID)
+	if err != nil || link == nil {
+		return true
+	}
+	subscribeID, err := strconv.Atoi(link.MPSubscribeID)
+	if err != nil || subscribeID <= 0 {
+		return true
+	}
+
+	// 订阅已完成时 MP 会删除订阅，需要等待对账或人工处理
+	if err := t.mpClient.SearchSubscribe(t.ctx, subscribeID); err != nil && !errors.Is(err, mp.ErrSubscribeNotFound) {
+		t.logger.Warn("Failed to search subscription after deleting torrent",
+			zap.String("title", record.Title),
+			zap.Int("subscribe_id", subscribeID),
+			zap.Error(err),
+		)
+	}
+	return true
+}
@@ -0,0 +1,136 @@
+package tracker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/configs"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/downloader"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+// fakeDownloader 返回固定种子的下载器
+type fakeDownloader map[string]downloader.Torrent
+
+func (f fakeDownloader) Type() downloader.ClientType { return downloader.ClientQBittorrent }
+
+func (f fakeDownloader) GetTorrents(ctx context.Context, hashes []string) ([]downloader.Torrent, error) {
+	var torrents []downloader.Torrent
+	for _, hash := range hashes {
+		if torrent, ok := f[hash]; ok {
+			torrents = append(torrents, torrent)
+		}
+	}
+	return torrents, nil
+}
+
+func TestClassifyTorrent(t *testing.T) {
+	tests := []struct {
+		torrent downloader.Torrent
+		want    store.TorrentIssue
+	}{
+		{downloader.Torrent{State: downloader.StateDownloading, Seeders: 3, SwarmSeeders: 10}, store.TorrentHealthy},
+		{downloader.Torrent{State: downloader.StateStalled, Seeders: 0, SwarmSeeders: 2}, store.TorrentStalled},
+		{downloader.Torrent{State: downloader.StateStalled, Seeders: 0, SwarmSeeders: 0}, store.TorrentNoSeeders},
+		{downloader.Torrent{State: downloader.StateStalled, Seeders: 0, SwarmSeeders: -1}, store.TorrentStalled},
+		{downloader.Torrent{State: downloader.StateError, Error: "missingFiles"}, store.TorrentErrored},
+		{downloader.Torrent{State: downloader.StatePaused, SwarmSeeders: 0}, store.TorrentHealthy},
+	}
+	for _, tt := range tests {
+		if got, _ := classifyTorrent(&tt.torrent); got != tt.want {
+			t.Errorf("classifyTorrent(%+v) = %q, want %q", tt.torrent, got, tt.want)
+		}
+	}
+}
+
+func TestCheckTorrentHealth(t *testing.T) {
+	st := newTestStore(t)
+	record := seedTracking(t, st, "movie-1", store.TrackingDownloading)
+
+	client := fakeDownloader{
+		"aaa": {Hash: "aaa", Name: "Dune.2160p", State: downloader.StateStalled, SwarmSeeders: 4, Progress: 42},
+		"bbb": {Hash: "bbb", Name: "Dune.1080p", State: downloader.StateError, Error: "missingFiles"},
+	}
+	tr := &Tracker{
+		cfg:        &configs.Config{DownloaderStallHours: 6},
+		store:      st,
+		logger:     zap.NewNop(),
+		ctx:        context.Background(),
+		downloader: client,
+	}
+	tr.trackTorrent(record, &mp.DownloadHistoryItem{DownloadHash: "AAA", TorrentName: "Dune.2160p"})
+	tr.trackTorrent(record, &mp.DownloadHistoryItem{DownloadHash: "bbb"})
+	tr.trackTorrent(record, &mp.DownloadHistoryItem{DownloadHash: "ccc"})
+
+	unhealthyEvents := func() int {
+		t.Helper()
+		count, err := st.CountEvents(store.EventQuery{SourceRequestID: "movie-1", Types: []store.EventType{store.EventTorrentUnhealthy}})
+		if err != nil {
+			t.Fatalf("count events: %v", err)
+		}
+		return count
+	}
+	byHash := func() map[string]*store.TrackedTorrent {
+		t.Helper()
+		torrents, err := st.ListTorrents("movie-1")
+		if err != nil {
+			t.Fatalf("list torrents: %v", err)
+		}
+		result := make(map[string]*store.TrackedTorrent)
+		for _, torrent := range torrents {
+			result[torrent.Hash] = torrent
+		}
+		return result
+	}
+
+	// 出错立即告警；停滞开始计时；下载器中已不存在的种子不再检查
+	now := time.Now()
+	if err := tr.checkTorrentHealth(now); err != nil {
+		t.Fatalf("check torrent health: %v", err)
+	}
+	torrents := byHash()
+	if torrents["bbb"].Issue != store.TorrentErrored || torrents["bbb"].AlertedAt == nil || torrents["bbb"].Name != "Dune.1080p" {
+		t.Errorf("unexpected errored torrent %+v", torrents["bbb"])
+	}
+	if torrents["aaa"].Issue != store.TorrentStalled || torrents["aaa"].IssueSince == nil || torrents["aaa"].AlertedAt != nil {
+		t.Errorf("unexpected stalled torrent %+v", torrents["aaa"])
+	}
+	if torrents["ccc"].ResolvedAt == nil {
+		t.Errorf("missing torrent should be resolved %+v", torrents["ccc"])
+	}
+	if unhealthyEvents() != 1 {
+		t.Fatalf("expected 1 unhealthy event, got %d", unhealthyEvents())
+	}
+
+	// 停滞超过阈值后告警一次
+	if err := tr.checkTorrentHealth(now.Add(7 * time.Hour)); err != nil {
+		t.Fatalf("check torrent health: %v", err)
+	}
+	if err := tr.checkTorrentHealth(now.Add(8 * time.Hour)); err != nil {
+		t.Fatalf("check torrent health: %v", err)
+	}
+	if unhealthyEvents() != 2 || byHash()["aaa"].AlertedAt == nil {
+		t.Fatalf("expected stalled alert, got %d events", unhealthyEvents())
+	}
+
+	// 恢复下载后清空问题；下载完成后不再检查
+	client["aaa"] = downloader.Torrent{Hash: "aaa", Name: "Dune.2160p", State: downloader.StateDownloading, Seeders: 2, SwarmSeeders: 4}
+	client["bbb"] = downloader.Torrent{Hash: "bbb", Name: "Dune.1080p", State: downloader.StateCompleted}
+	if err := tr.checkTorrentHealth(now.Add(9 * time.Hour)); err != nil {
+		t.Fatalf("check torrent health: %v", err)
+	}
+	torrents = byHash()
+	if torrents["aaa"].Issue != store.TorrentHealthy || torrents["aaa"].IssueSince != nil || torrents["aaa"].AlertedAt != nil {
+		t.Errorf("recovered torrent should be healthy %+v", torrents["aaa"])
+	}
+	if torrents["bbb"].ResolvedAt == nil {
+		t.Errorf("completed torrent should be resolved %+v", torrents["bbb"])
+	}
+	active, _ := st.ListActiveTorrents()
+	if len(active) != 1 || active[0].Hash != "aaa" {
+		t.Errorf("unexpected active torrents %+v", active)
+	}
+}
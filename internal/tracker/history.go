@@ -0,0 +1,154 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
+	"go.uber.org/zap"
+)
+
+const (
+	historyPageSize = 100 // 每页历史记录数
+	historyMaxPages = 50  // 单次扫描的最大页数，防止游标丢失时翻遍整个历史
+
+	downloadHistoryCursorKey = "download_history_cursor"
+	transferHistoryCursorKey = "transfer_history_cursor"
+	resumeKeySuffix          = "_resume" // 续扫进度的状态键后缀
+)
+
+// historyScan 历史记录扫描进度
+type historyScan struct {
+	Cursor     int `json:"-"`      // 该 ID 及更早的记录都已处理
+	ResumePage int `json:"page"`   // 上次扫描达到页数上限时，下次从该页继续读取更早的记录；为 0 表示从第一页开始
+	Newest     int `json:"newest"` // 续扫开始前读到的最大 ID，续扫到游标后作为新的游标
+}
+
+// retryFrom 让 ID 为 failedID 的记录在下次扫描时重新读取
+func (s historyScan) retryFrom(failedID int) historyScan {
+	if s.ResumePage > 0 {
+		s.Newest = min(s.Newest, failedID-1)
+	} else {
+		s.Cursor = min(s.Cursor, failedID-1)
+	}
+	return s
+}
+
+// scanHistory 从第一页开始翻页，直到遇到游标（上次看到的最大 ID）为止
+// MP 历史记录按 ID 倒序返回；游标为 0（首次运行）时只读取第一页
+// 达到页数上限仍未遇到游标时游标保持不变，下次从下一页继续读取更早的记录，
+// 直到遇到游标后才将游标推进到读到的最大 ID，中间的记录不会被跳过
+// 返回游标之后的新记录和新的扫描进度
+func scanHistory[T any](scan historyScan, fetch func(page int) ([]T, error), id func(T) int) ([]T, historyScan, error) {
+	var items []T
+	start, newest := 1, scan.Cursor
+	if scan.ResumePage > 0 {
+		start, newest = scan.ResumePage, scan.Newest
+	}
+
+	for page := start; page < start+historyMaxPages; page++ {
+		batch, err := fetch(page)
+		if err != nil {
+			return nil, scan, err
+		}
+
+		reached := false
+		for _, item := range batch {
+			itemID := id(item)
+			if itemID <= scan.Cursor {
+				reached = true
+				continue
+			}
+			items = append(items, item)
+			// 续扫时读到的都是更早的记录，不推进游标
+			if scan.ResumePage == 0 && itemID > newest {
+				newest = itemID
+			}
+		}
+
+		if reached || scan.Cursor == 0 || len(batch) < historyPageSize {
+			return items, historyScan{Cursor: newest}, nil
+		}
+	}
+
+	return items, historyScan{Cursor: scan.Cursor, ResumePage: start + historyMaxPages, Newest: newest}, nil
+}
+
+// loadScan 读取历史记录游标和续扫进度
+func (t *Tracker) loadScan(key string) historyScan {
+	var scan historyScan
+	value, err := t.store.GetTrackerState(key)
+	if err != nil {
+		t.logger.Warn("Failed to load history cursor", zap.String("key", key), zap.Error(err))
+		return scan
+	}
+	scan.Cursor, _ = strconv.Atoi(value)
+
+	resume, err := t.store.GetTrackerState(key + resumeKeySuffix)
+	if err != nil {
+		t.logger.Warn("Failed to load history scan", zap.String("key", key), zap.Error(err))
+		return scan
+	}
+	if resume != "" {
+		if err := json.Unmarshal([]byte(resume), &scan); err != nil {
+			t.logger.Warn("Invalid history scan state", zap.String("key", key), zap.Error(err))
+			scan.ResumePage, scan.Newest = 0, 0
+		}
+	}
+	return scan
+}
+
+// saveScan 保存历史记录游标和续扫进度
+func (t *Tracker) saveScan(key string, scan historyScan) {
+	if scan.ResumePage > 0 {
+		t.logger.Warn("History backlog exceeds page limit, continuing with older pages next scan",
+			zap.String("key", key),
+			zap.Int("cursor", scan.Cursor),
+			zap.Int("resume_page", scan.ResumePage),
+		)
+	}
+
+	resume := ""
+	if scan.ResumePage > 0 {
+		data, _ := json.Marshal(scan)
+		resume = string(data)
+	}
+	if err := t.store.SetTrackerState(key, strconv.Itoa(scan.Cursor)); err != nil {
+		t.logger.Error("Failed to save history cursor", zap.String("key", key), zap.Error(err))
+		return
+	}
+	if err := t.store.SetTrackerState(key+resumeKeySuffix, resume); err != nil {
+		t.logger.Error("Failed to save history scan", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// fetchNewDownloadHistory 获取上次扫描之后的新下载记录
+func (t *Tracker) fetchNewDownloadHistory() ([]mp.DownloadHistoryItem, historyScan, error) {
+	scan := t.loadScan(downloadHistoryCursorKey)
+	items, next, err := scanHistory(scan,
+		func(page int) ([]mp.DownloadHistoryItem, error) {
+			return t.mpClient.GetDownloadHistory(t.ctx, page, historyPageSize)
+		},
+		func(item mp.DownloadHistoryItem) int { return item.ID },
+	)
+	if err != nil {
+		return nil, scan, fmt.Errorf("scan download history: %w", err)
+	}
+	return items, next, nil
+}
+
+// fetchNewTransferHistory 获取上次扫描之后的新入库记录
+func (t *Tracker) fetchNewTransferHistory() ([]mp.TransferHistoryItem, historyScan, error) {
+	scan := t.loadScan(transferHistoryCursorKey)
+	items, next, err := scanHistory(scan,
+		func(page int) ([]mp.TransferHistoryItem, error) {
+			return t.mpClient.GetTransferHistory(t.ctx, page, historyPageSize)
+		},
+		func(item mp.TransferHistoryItem) int { return item.ID },
+	)
+	if err != nil {
+		return nil, scan, fmt.Errorf("scan transfer history: %w", err)
+	}
+	return items, next, nil
+}
@@ -0,0 +1,138 @@
+package tracker
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/configs"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+// fakeHistory 按 ID 倒序分页返回历史记录
+func fakeHistory(newest int) func(page int) ([]int, error) {
+	return func(page int) ([]int, error) {
+		var batch []int
+		start := newest - (page-1)*historyPageSize
+		for id := start; id > start-historyPageSize && id > 0; id-- {
+			batch = append(batch, id)
+		}
+		return batch, nil
+	}
+}
+
+func TestScanHistory(t *testing.T) {
+	id := func(v int) int { return v }
+
+	// 首次运行只读取第一页
+	items, next, err := scanHistory(historyScan{}, fakeHistory(350), id)
+	if err != nil {
+		t.Fatalf("scanHistory: %v", err)
+	}
+	if len(items) != historyPageSize || next.Cursor != 350 {
+		t.Errorf("first run: got %d items, cursor %+v", len(items), next)
+	}
+
+	// 游标在第三页，需要翻页直到遇到游标
+	items, next, err = scanHistory(historyScan{Cursor: 120}, fakeHistory(350), id)
+	if err != nil {
+		t.Fatalf("scanHistory: %v", err)
+	}
+	if len(items) != 230 || next.Cursor != 350 {
+		t.Errorf("paged scan: got %d items, cursor %+v", len(items), next)
+	}
+
+	// 没有新记录时游标不变
+	items, next, err = scanHistory(historyScan{Cursor: 350}, fakeHistory(350), id)
+	if err != nil {
+		t.Fatalf("scanHistory: %v", err)
+	}
+	if len(items) != 0 || next.Cursor != 350 {
+		t.Errorf("no new items: got %v, cursor %+v", items, next)
+	}
+
+	// 只有少量新记录
+	items, _, _ = scanHistory(historyScan{Cursor: 347}, fakeHistory(350), id)
+	if want := []int{350, 349, 348}; !reflect.DeepEqual(items, want) {
+		t.Errorf("got %v, want %v", items, want)
+	}
+}
+
+func TestScanHistoryPageLimit(t *testing.T) {
+	id := func(v int) int { return v }
+	newest := (historyMaxPages + 2) * historyPageSize
+	pages := 0
+	fetch := func(page int) ([]int, error) {
+		pages++
+		return fakeHistory(newest)(page)
+	}
+
+	// 达到页数上限时游标不变，下次从下一页继续
+	items, next, err := scanHistory(historyScan{Cursor: 10}, fetch, id)
+	if err != nil {
+		t.Fatalf("scanHistory: %v", err)
+	}
+	want := historyScan{Cursor: 10, ResumePage: historyMaxPages + 1, Newest: newest}
+	if len(items) != historyMaxPages*historyPageSize || next != want || pages != historyMaxPages {
+		t.Fatalf("got %d items in %d pages, scan %+v", len(items), pages, next)
+	}
+
+	// 续扫读到游标后再推进到最大 ID
+	items, next, err = scanHistory(next, fetch, id)
+	if err != nil {
+		t.Fatalf("scanHistory: %v", err)
+	}
+	if len(items) != 2*historyPageSize-10 || items[len(items)-1] != 11 || next != (historyScan{Cursor: newest}) {
+		t.Errorf("resume: got %d items, scan %+v", len(items), next)
+	}
+}
+
+func TestHistoryScanRetryFrom(t *testing.T) {
+	if got := (historyScan{Cursor: 350}).retryFrom(200); got != (historyScan{Cursor: 199}) {
+		t.Errorf("retryFrom() = %+v", got)
+	}
+	resuming := historyScan{Cursor: 10, ResumePage: 51, Newest: 9000}
+	if got := resuming.retryFrom(5000); got != (historyScan{Cursor: 10, ResumePage: 51, Newest: 4999}) {
+		t.Errorf("retryFrom() = %+v", got)
+	}
+}
+
+// failingStore 跟踪记录保存总是失败的存储
+type failingStore struct {
+	store.Store
+}
+
+func (failingStore) UpdateTracking(*store.SubscriptionTracking) error {
+	return errors.New("database is locked")
+}
+
+func TestProcessTransferHistoryFailure(t *testing.T) {
+	st := newTestStore(t)
+	record := seedTracking(t, st, "movie-1", store.TrackingDownloading)
+
+	tr := &Tracker{
+		cfg:    &configs.Config{},
+		store:  st,
+		logger: zap.NewNop(),
+	}
+	tr.lifecycle = NewStateMachine(failingStore{st}, nil)
+
+	history := []mp.TransferHistoryItem{
+		{ID: 42, TMDBID: 100, Type: "电影", Status: mp.TransferSuccess},
+		{ID: 41, TMDBID: 200, Type: "电影", Status: mp.TransferSuccess},
+	}
+	if got := tr.processTransferHistory([]*store.SubscriptionTracking{record}, history); got != 42 {
+		t.Errorf("processTransferHistory() = %d, want 42", got)
+	}
+	if record.SubscribeStatus != store.TrackingDownloading {
+		t.Errorf("status = %s, want downloading", record.SubscribeStatus)
+	}
+
+	// 保存成功后不再需要重新处理
+	tr.lifecycle = NewStateMachine(st, nil)
+	if got := tr.processTransferHistory([]*store.SubscriptionTracking{record}, history); got != 0 {
+		t.Errorf("processTransferHistory() = %d, want 0", got)
+	}
+}
@@ -0,0 +1,247 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+// jellyfinWebhookPath Jellyfin Webhook 插件的接收路径
+const jellyfinWebhookPath = "/webhook/jellyfin"
+
+// flexInt 兼容数字和字符串的整数（Jellyfin Webhook 模板可能给数字加引号）
+type flexInt int
+
+// UnmarshalJSON 解析数字、数字字符串或空值
+func (n *flexInt) UnmarshalJSON(data []byte) error {
+	text := strings.Trim(string(data), `"`)
+	if text == "" || text == "null" {
+		*n = 0
+		return nil
+	}
+	value, err := strconv.Atoi(text)
+	if err != nil {
+		return fmt.Errorf("invalid number %s", data)
+	}
+	*n = flexInt(value)
+	return nil
+}
+
+// jellyfinEvent Jellyfin Webhook 插件请求体（通用模板中的字段）
+type jellyfinEvent struct {
+	NotificationType string  `json:"NotificationType"`
+	ItemID           string  `json:"ItemId"`
+	ItemType         string  `json:"ItemType"` // Movie, Series, Season, Episode
+	Name             string  `json:"Name"`
+	SeriesName       string  `json:"SeriesName"`
+	SeriesID         string  `json:"SeriesId"`
+	Year             flexInt `json:"Year"`
+	SeasonNumber     flexInt `json:"SeasonNumber"`
+	EpisodeNumber    flexInt `json:"EpisodeNumber"`
+	EpisodeNumberEnd flexInt `json:"EpisodeNumberEnd"` // 多集文件的最后一集
+	ProviderTMDB     flexInt `json:"Provider_tmdb"`
+}
+
+// displayName 用于日志和排查的名称
+func (e *jellyfinEvent) displayName() string {
+	if e.ItemType == "Episode" || e.ItemType == "Season" {
+		name := e.SeriesName
+		if e.SeasonNumber > 0 {
+			name += fmt.Sprintf(" S%02d", e.SeasonNumber)
+		}
+		if e.EpisodeNumber > 0 {
+			name += fmt.Sprintf("E%02d", e.EpisodeNumber)
+		}
+		return strings.TrimSpace(name)
+	}
+	return e.Name
+}
+
+// episodes 事件涉及的季集，集号为空表示整季
+func (e *jellyfinEvent) episodes() map[int][]int {
+	if e.EpisodeNumber <= 0 {
+		return map[int][]int{int(e.SeasonNumber): nil}
+	}
+	last := e.EpisodeNumberEnd
+	if last < e.EpisodeNumber {
+		last = e.EpisodeNumber
+	}
+	var numbers []int
+	for number := e.EpisodeNumber; number <= last; number++ {
+		numbers = append(numbers, int(number))
+	}
+	return map[int][]int{int(e.SeasonNumber): numbers}
+}
+
+// jellyfinHandler 接收 Jellyfin Webhook 的 HTTP 处理器
+type jellyfinHandler struct {
+	token  string
+	logger *zap.Logger
+	handle func(event *jellyfinEvent, payload []byte)
+}
+
+// ServeHTTP 校验令牌、解析请求体并处理 ItemAdded 事件
+func (h *jellyfinHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !authorizedRequest(r, h.token) {
+		h.logger.Warn("Rejected unauthorized Jellyfin webhook request", zap.String("remote_addr", r.RemoteAddr))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, webhookMaxBody))
+	if err != nil {
+		http.Error(w, "read body failed", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var event jellyfinEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		h.logger.Warn("Invalid Jellyfin webhook payload", zap.Error(err))
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	if event.NotificationType == "ItemAdded" {
+		h.handle(&event, body)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleJellyfinItemAdded 将 Jellyfin 新增条目对应到跟踪记录并标记为可观看
+func (t *Tracker) handleJellyfinItemAdded(event *jellyfinEvent, payload []byte) {
+	t.logger.Info("Received Jellyfin item added",
+		zap.String("item_type", event.ItemType),
+		zap.String("name", event.displayName()),
+	)
+
+	var mediaType store.MediaType
+	switch event.ItemType {
+	case "Movie":
+		mediaType = store.MediaTypeMovie
+	case "Episode":
+		mediaType = store.MediaTypeTV
+	default:
+		// 剧集和季的新增事件在第一集出现时就会触发，以之后的单集事件为准
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tmdbID, err := t.jellyfinTMDBID(event)
+	if err != nil {
+		t.logger.Warn("Failed to resolve Jellyfin series", zap.String("series_id", event.SeriesID), zap.Error(err))
+	}
+	if tmdbID == 0 {
+		t.saveUnmatchedJellyfinEvent(event, 0, "missing tmdb id", payload)
+		return
+	}
+
+	records, err := t.store.ListTrackingByTMDBID(tmdbID, mediaType)
+	if err != nil {
+		t.logger.Error("Failed to list tracking by tmdb id", zap.Error(err))
+		return
+	}
+
+	matched := false
+	for _, record := range records {
+		if record.SubscribeStatus == store.TrackingAvailable {
+			continue
+		}
+		matched = true
+
+		if mediaType == store.MediaTypeTV {
+			seasons := event.episodes()
+			items := []mp.TransferHistoryItem{{Seasons: fmt.Sprintf("S%02d", event.SeasonNumber)}}
+			if numbers := seasons[int(event.SeasonNumber)]; len(numbers) > 0 {
+				items[0].Episodes = fmt.Sprintf("E%02d-E%02d", numbers[0], numbers[len(numbers)-1])
+			}
+			if !record.SubscribeStatus.IsCompleted() {
+				t.processEpisodeTransfers(record, items)
+			}
+			// 全部集入库后再确认其余集也在媒体库中
+			if CanTransition(record.SubscribeStatus, store.TrackingAvailable) {
+				t.confirmJellyfinSeries(record)
+			}
+			continue
+		}
+
+		if !record.SubscribeStatus.IsCompleted() {
+			t.markTransferred(record)
+		}
+		t.markAvailable(record)
+	}
+
+	if !matched {
+		t.saveUnmatchedJellyfinEvent(event, tmdbID, "no open tracking record", payload)
+	}
+}
+
+// confirmJellyfinSeries 剧集全部入库后标记为可观看
+// 配置了媒体服务器时再确认一次所有集都已出现，否则信任 Webhook
+func (t *Tracker) confirmJellyfinSeries(record *store.SubscriptionTracking) {
+	if t.mediaServer != nil {
+		available, err := t.confirmAvailable(record)
+		if err != nil {
+			t.logger.Warn("Failed to check media server", zap.String("title", record.Title), zap.Error(err))
+			return
+		}
+		if !available {
+			return
+		}
+	}
+	t.markAvailable(record)
+}
+
+// jellyfinTMDBID 取事件对应的 TMDB ID，单集事件需要到媒体服务器查询所属剧集
+func (t *Tracker) jellyfinTMDBID(event *jellyfinEvent) (int, error) {
+	if event.ItemType == "Movie" {
+		return int(event.ProviderTMDB), nil
+	}
+	if event.SeriesID == "" || t.mediaServer == nil {
+		return 0, nil
+	}
+
+	series, err := t.mediaServer.GetItem(t.ctx, event.SeriesID)
+	if err != nil || series == nil {
+		return 0, err
+	}
+	tmdbID, _ := strconv.Atoi(series.ProviderID("Tmdb"))
+	return tmdbID, nil
+}
+
+// saveUnmatchedJellyfinEvent 保存未匹配的事件用于排查
+func (t *Tracker) saveUnmatchedJellyfinEvent(event *jellyfinEvent, tmdbID int, reason string, payload []byte) {
+	t.logger.Debug("Unmatched Jellyfin event",
+		zap.String("name", event.displayName()),
+		zap.Int("tmdb_id", tmdbID),
+		zap.String("reason", reason),
+	)
+
+	unmatched := &store.UnmatchedMediaEvent{
+		Source:    "jellyfin",
+		EventType: event.NotificationType,
+		ItemType:  event.ItemType,
+		ItemName:  event.displayName(),
+		TMDBID:    tmdbID,
+		Season:    int(event.SeasonNumber),
+		Episode:   int(event.EpisodeNumber),
+		Reason:    reason,
+		Payload:   string(payload),
+	}
+	if err := t.store.SaveUnmatchedEvent(unmatched); err != nil {
+		t.logger.Error("Failed to save unmatched event", zap.Error(err))
+	}
+}
@@ -0,0 +1,205 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/configs"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mediaserver"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+func TestJellyfinEventParse(t *testing.T) {
+	var movie jellyfinEvent
+	if err := json.Unmarshal(loadFixture(t, "jellyfin_item_added_movie.json"), &movie); err != nil {
+		t.Fatalf("unmarshal movie: %v", err)
+	}
+	if movie.ItemType != "Movie" || movie.ProviderTMDB != 693134 || movie.displayName() != "沙丘2" {
+		t.Errorf("unexpected movie event %+v", movie)
+	}
+
+	var episode jellyfinEvent
+	if err := json.Unmarshal(loadFixture(t, "jellyfin_item_added_episode.json"), &episode); err != nil {
+		t.Fatalf("unmarshal episode: %v", err)
+	}
+	if got := episode.displayName(); got != "三体 S01E03" {
+		t.Errorf("displayName() = %q", got)
+	}
+	if got, want := episode.episodes(), map[int][]int{1: {3}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("episodes() = %v, want %v", got, want)
+	}
+
+	multi := jellyfinEvent{SeasonNumber: 2, EpisodeNumber: 5, EpisodeNumberEnd: 6}
+	if got, want := multi.episodes(), map[int][]int{2: {5, 6}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("episodes() = %v, want %v", got, want)
+	}
+	season := jellyfinEvent{SeasonNumber: 2}
+	if got, want := season.episodes(), map[int][]int{2: nil}; !reflect.DeepEqual(got, want) {
+		t.Errorf("episodes() = %v, want %v", got, want)
+	}
+}
+
+func TestJellyfinHandler(t *testing.T) {
+	var handled []string
+	handler := &jellyfinHandler{
+		token:  "secret",
+		logger: zap.NewNop(),
+		handle: func(event *jellyfinEvent, payload []byte) { handled = append(handled, event.Name) },
+	}
+
+	send := func(body string) int {
+		req := httptest.NewRequest(http.MethodPost, jellyfinWebhookPath+"?token=secret", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := send(string(loadFixture(t, "jellyfin_item_added_movie.json"))); code != http.StatusNoContent {
+		t.Errorf("status = %d", code)
+	}
+	// 非 ItemAdded 事件直接忽略
+	if code := send(`{"NotificationType": "PlaybackStart", "Name": "x"}`); code != http.StatusNoContent {
+		t.Errorf("status = %d", code)
+	}
+	if code := send(`{"NotificationType": "ItemAdded", "SeasonNumber": "x"}`); code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", code)
+	}
+	if len(handled) != 1 || handled[0] != "沙丘2" {
+		t.Errorf("handled = %v", handled)
+	}
+}
+
+func TestHandleJellyfinItemAdded(t *testing.T) {
+	st := newTestStore(t)
+
+	subscribed := time.Now().Add(-48 * time.Hour)
+	if err := st.SaveTracking(&store.SubscriptionTracking{
+		SourceRequestID: "movie-1",
+		TMDBID:          693134,
+		Title:           "沙丘2",
+		MediaType:       store.MediaTypeMovie,
+		SubscribeStatus: store.TrackingDownloading,
+		SubscribeTime:   &subscribed,
+	}); err != nil {
+		t.Fatalf("save tracking: %v", err)
+	}
+
+	tr := &Tracker{
+		cfg:           &configs.Config{},
+		store:         st,
+		logger:        zap.NewNop(),
+		ctx:           context.Background(),
+		scanRequested: make(map[string]bool),
+	}
+	tr.lifecycle = NewStateMachine(st, nil)
+
+	payload := loadFixture(t, "jellyfin_item_added_movie.json")
+	var event jellyfinEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	tr.handleJellyfinItemAdded(&event, payload)
+
+	record, err := st.GetTracking("movie-1")
+	if err != nil {
+		t.Fatalf("get tracking: %v", err)
+	}
+	if record.SubscribeStatus != store.TrackingAvailable || record.TransferTime == nil || record.AvailableTime == nil {
+		t.Errorf("expected available record with timestamps, got %+v", record)
+	}
+	events, _ := st.ListEvents("movie-1", 10)
+	if len(events) != 2 || events[0].EventType != store.EventAvailable || events[1].EventType != store.EventTransferComplete {
+		t.Errorf("unexpected events %+v", events)
+	}
+
+	// 已可观看的记录不再匹配，作为未匹配事件保存；单集事件缺少媒体服务器时也无法匹配
+	tr.handleJellyfinItemAdded(&event, payload)
+	episodePayload := loadFixture(t, "jellyfin_item_added_episode.json")
+	var episode jellyfinEvent
+	if err := json.Unmarshal(episodePayload, &episode); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	tr.handleJellyfinItemAdded(&episode, episodePayload)
+
+	unmatched, err := st.ListUnmatchedEvents(0)
+	if err != nil {
+		t.Fatalf("list unmatched: %v", err)
+	}
+	if len(unmatched) != 2 {
+		t.Fatalf("expected 2 unmatched events, got %d", len(unmatched))
+	}
+	if unmatched[0].ItemName != "三体 S01E03" || unmatched[0].Reason != "missing tmdb id" {
+		t.Errorf("unexpected unmatched episode %+v", unmatched[0])
+	}
+	if unmatched[1].TMDBID != 693134 || unmatched[1].Payload == "" {
+		t.Errorf("unexpected unmatched movie %+v", unmatched[1])
+	}
+}
+
+func TestHandleJellyfinSeasonAdded(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(mediaserver.ItemsResponse{Items: []mediaserver.Item{
+			{ID: "series-1", Name: "三体", Type: mediaserver.ItemSeries, ProviderIDs: map[string]string{"Tmdb": "100"}},
+		}})
+	}))
+	defer server.Close()
+
+	st := newTestStore(t)
+	subscribed := time.Now().Add(-48 * time.Hour)
+	if err := st.SaveTracking(&store.SubscriptionTracking{
+		SourceRequestID: "tv-1",
+		TMDBID:          100,
+		Title:           "三体",
+		MediaType:       store.MediaTypeTV,
+		SubscribeStatus: store.TrackingDownloading,
+		SubscribeTime:   &subscribed,
+	}); err != nil {
+		t.Fatalf("save tracking: %v", err)
+	}
+	for episode := 1; episode <= 2; episode++ {
+		if err := st.SaveEpisode(&store.EpisodeTracking{SourceRequestID: "tv-1", Season: 1, Episode: episode, Status: store.TrackingPending}); err != nil {
+			t.Fatalf("save episode: %v", err)
+		}
+	}
+
+	tr := &Tracker{
+		cfg:           &configs.Config{},
+		store:         st,
+		logger:        zap.NewNop(),
+		ctx:           context.Background(),
+		mediaServer:   mediaserver.NewClient(mediaserver.ServerJellyfin, server.URL, "key"),
+		scanRequested: make(map[string]bool),
+	}
+	tr.lifecycle = NewStateMachine(st, nil)
+
+	// 新季的第一集出现时 Jellyfin 就会发送季的新增事件，不能当作整季入库
+	payload := []byte(`{"NotificationType": "ItemAdded", "ItemType": "Season", "SeriesName": "三体", "SeriesId": "series-1", "SeasonNumber": 1}`)
+	var event jellyfinEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	tr.handleJellyfinItemAdded(&event, payload)
+
+	episodes, err := st.ListEpisodes("tv-1")
+	if err != nil {
+		t.Fatalf("list episodes: %v", err)
+	}
+	if len(episodes) != 2 {
+		t.Fatalf("expected 2 episodes, got %d", len(episodes))
+	}
+	for _, episode := range episodes {
+		if episode.Status != store.TrackingPending || episode.TransferTime != nil {
+			t.Errorf("episode S%02dE%02d changed to %s", episode.Season, episode.Episode, episode.Status)
+		}
+	}
+	if got, _ := st.GetTracking("tv-1"); got.SubscribeStatus != store.TrackingDownloading {
+		t.Errorf("status = %s, want downloading", got.SubscribeStatus)
+	}
+}
@@ -0,0 +1,275 @@
+package tracker
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+const (
+	latencyWeeks  = 8 // 每次报告重新统计最近几周完成的请求
+	slowestLimit  = 3 // 报告中列出的最慢请求数
+	reportWindow  = 7 * 24 * time.Hour
+	latencyLayout = "2006-01-02"
+)
+
+// weekStart 返回所在周的周一零点
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return day.AddDate(0, 0, -offset)
+}
+
+// completedAt 返回请求的完成时间
+// requireAvailable 为 true（接入媒体服务器）时以可观看为完成，否则以入库为完成
+func completedAt(record *store.SubscriptionTracking, requireAvailable bool) (time.Time, bool) {
+	switch {
+	case record.SubscribeStatus == store.TrackingAvailable && record.AvailableTime != nil:
+		return *record.AvailableTime, true
+	case record.SubscribeStatus == store.TrackingTransferred && record.TransferTime != nil && !requireAvailable:
+		return *record.TransferTime, true
+	}
+	return time.Time{}, false
+}
+
+// requestLatency 计算已完成请求各阶段耗时
+// 未完成或订阅时已在库中（入库时间即订阅时间）的请求返回 nil
+func requestLatency(req *store.Request, record *store.SubscriptionTracking, requireAvailable bool) *store.RequestLatency {
+	completed, ok := completedAt(record, requireAvailable)
+	if !ok {
+		return nil
+	}
+	if record.SubscribeTime != nil && record.TransferTime != nil && record.TransferTime.Equal(*record.SubscribeTime) {
+		return nil
+	}
+
+	// 优先使用批准时间，旧记录退回请求时间
+	var approved *time.Time
+	if req != nil {
+		approved = req.ApprovedAt
+		if approved == nil && !req.RequestedAt.IsZero() {
+			approved = &req.RequestedAt
+		}
+	}
+
+	latency := &store.RequestLatency{
+		SourceRequestID: record.SourceRequestID,
+		Title:           record.Title,
+		MediaType:       record.MediaType,
+		CompletedAt:     completed,
+		Week:            weekStart(completed).Format(latencyLayout),
+		Stages:          make(map[store.LatencyStage]time.Duration),
+	}
+	stage := func(name store.LatencyStage, from, to *time.Time) {
+		if from != nil && to != nil && !to.Before(*from) {
+			latency.Stages[name] = to.Sub(*from)
+		}
+	}
+	stage(store.StageApproval, approved, record.SubscribeTime)
+	stage(store.StageSearch, record.SubscribeTime, record.DownloadStartTime)
+	stage(store.StageDownload, record.DownloadStartTime, record.DownloadFinishTime)
+	stage(store.StageTransfer, record.DownloadFinishTime, record.TransferTime)
+	stage(store.StageAvailable, record.TransferTime, record.AvailableTime)
+
+	start := approved
+	if start == nil {
+		start = record.SubscribeTime
+	}
+	stage(store.StageTotal, start, &completed)
+
+	return latency
+}
+
+// percentile 按最近秩法计算百分位，sorted 需已升序排列
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// aggregateLatency 按周、媒体类型和阶段汇总百分位
+func aggregateLatency(latencies []*store.RequestLatency) []*store.LatencyStat {
+	type groupKey struct {
+		week      string
+		mediaType store.MediaType
+		stage     store.LatencyStage
+	}
+	groups := make(map[groupKey][]time.Duration)
+	for _, latency := range latencies {
+		for stage, d := range latency.Stages {
+			key := groupKey{latency.Week, latency.MediaType, stage}
+			groups[key] = append(groups[key], d)
+		}
+	}
+
+	stats := make([]*store.LatencyStat, 0, len(groups))
+	for key, durations := range groups {
+		stats = append(stats, summarizeLatency(key.week, key.mediaType, key.stage, durations))
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Week != stats[j].Week {
+			return stats[i].Week < stats[j].Week
+		}
+		if stats[i].MediaType != stats[j].MediaType {
+			return stats[i].MediaType < stats[j].MediaType
+		}
+		return stats[i].Stage < stats[j].Stage
+	})
+	return stats
+}
+
+// summarizeLatency 计算一组耗时的百分位
+func summarizeLatency(week string, mediaType store.MediaType, stage store.LatencyStage, durations []time.Duration) *store.LatencyStat {
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return &store.LatencyStat{
+		Week:      week,
+		MediaType: mediaType,
+		Stage:     stage,
+		Samples:   len(sorted),
+		P50:       percentile(sorted, 50),
+		P90:       percentile(sorted, 90),
+		Max:       sorted[len(sorted)-1],
+	}
+}
+
+// refreshLatency 重新计算最近几周完成的请求耗时并更新每周统计
+func (t *Tracker) refreshLatency(now time.Time) error {
+	since := weekStart(now).AddDate(0, 0, -7*(latencyWeeks-1))
+	requireAvailable := t.mediaServer != nil
+
+	for _, status := range []store.TrackingStatus{store.TrackingTransferred, store.TrackingAvailable} {
+		records, err := t.store.ListTrackingByStatus(status, 0)
+		if err != nil {
+			return fmt.Errorf("list %s tracking: %w", status, err)
+		}
+		for _, record := range records {
+			if completed, ok := completedAt(record, requireAvailable); !ok || completed.Before(since) {
+				continue
+			}
+			req, err := t.store.GetRequest(record.SourceRequestID)
+			if err != nil {
+				return fmt.Errorf("get request: %w", err)
+			}
+			latency := requestLatency(req, record, requireAvailable)
+			if latency == nil {
+				continue
+			}
+			if err := t.store.SaveRequestLatency(latency); err != nil {
+				return fmt.Errorf("save request latency: %w", err)
+			}
+		}
+	}
+
+	latencies, err := t.store.ListRequestLatency(since)
+	if err != nil {
+		return fmt.Errorf("list request latency: %w", err)
+	}
+	stats := aggregateLatency(latencies)
+	for _, stat := range stats {
+		if err := t.store.SaveLatencyStat(stat); err != nil {
+			return fmt.Errorf("save latency stat: %w", err)
+		}
+	}
+
+	t.logger.Debug("Latency stats refreshed",
+		zap.Int("requests", len(latencies)),
+		zap.Int("stats", len(stats)),
+	)
+	return nil
+}
+
+// latencyLine 报告中一种媒体类型的总耗时
+type latencyLine struct {
+	MediaType store.MediaType `json:"media_type"`
+	Samples   int             `json:"samples"`
+	P50       time.Duration   `json:"p50"`
+	P90       time.Duration   `json:"p90"`
+}
+
+// slowRequest 报告中的慢请求
+type slowRequest struct {
+	Title     string          `json:"title"`
+	MediaType store.MediaType `json:"media_type"`
+	Total     time.Duration   `json:"total"`
+}
+
+// latencySummary 报告中近 7 天完成请求的耗时
+type latencySummary struct {
+	Completed int           `json:"completed"`
+	Lines     []latencyLine `json:"lines"`
+	Slowest   []slowRequest `json:"slowest,omitempty"`
+}
+
+// collectLatencySummary 汇总近 7 天完成的请求耗时，没有完成的请求时返回 nil
+func (t *Tracker) collectLatencySummary(now time.Time) (*latencySummary, error) {
+	since := now.Add(-reportWindow)
+	latencies, err := t.store.ListRequestLatency(since)
+	if err != nil {
+		return nil, fmt.Errorf("list request latency: %w", err)
+	}
+	if len(latencies) == 0 {
+		return nil, nil
+	}
+
+	byType := make(map[store.MediaType][]time.Duration)
+	for _, latency := range latencies {
+		if total, ok := latency.Stages[store.StageTotal]; ok {
+			byType[latency.MediaType] = append(byType[latency.MediaType], total)
+		}
+	}
+
+	summary := &latencySummary{Completed: len(latencies)}
+	for _, mediaType := range []store.MediaType{store.MediaTypeMovie, store.MediaTypeTV} {
+		durations := byType[mediaType]
+		if len(durations) == 0 {
+			continue
+		}
+		stat := summarizeLatency("", mediaType, store.StageTotal, durations)
+		summary.Lines = append(summary.Lines, latencyLine{
+			MediaType: mediaType,
+			Samples:   stat.Samples,
+			P50:       stat.P50,
+			P90:       stat.P90,
+		})
+	}
+
+	slowest, err := t.store.ListSlowestRequests(since, slowestLimit)
+	if err != nil {
+		return nil, fmt.Errorf("list slowest requests: %w", err)
+	}
+	for _, latency := range slowest {
+		summary.Slowest = append(summary.Slowest, slowRequest{
+			Title:     latency.Title,
+			MediaType: latency.MediaType,
+			Total:     latency.Stages[store.StageTotal],
+		})
+	}
+
+	return summary, nil
+}
+
+// formatLatency 将耗时格式化为 "2天3小时"、"5小时20分" 或 "12分"
+func formatLatency(d time.Duration) string {
+	d = d.Round(time.Minute)
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%d天%d小时", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%d小时%d分", hours, minutes)
+	default:
+		return fmt.Sprintf("%d分", minutes)
+	}
+}
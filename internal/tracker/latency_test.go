@@ -0,0 +1,139 @@
+package tracker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+)
+
+func TestWeekStart(t *testing.T) {
+	tests := []struct {
+		in   time.Time
+		want string
+	}{
+		{time.Date(2025, 1, 20, 9, 0, 0, 0, time.Local), "2025-01-20"}, // 周一
+		{time.Date(2025, 1, 22, 23, 59, 0, 0, time.Local), "2025-01-20"},
+		{time.Date(2025, 1, 26, 12, 0, 0, 0, time.Local), "2025-01-20"}, // 周日
+		{time.Date(2025, 1, 1, 12, 0, 0, 0, time.Local), "2024-12-30"},
+	}
+	for _, tt := range tests {
+		if got := weekStart(tt.in).Format(latencyLayout); got != tt.want {
+			t.Errorf("weekStart(%v) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRequestLatency(t *testing.T) {
+	base := time.Date(2025, 1, 20, 8, 0, 0, 0, time.Local)
+	at := func(hours int) *time.Time {
+		v := base.Add(time.Duration(hours) * time.Hour)
+		return &v
+	}
+
+	req := &store.Request{SourceRequestID: "1", RequestedAt: *at(-24), ApprovedAt: at(0)}
+	record := &store.SubscriptionTracking{
+		SourceRequestID:    "1",
+		Title:              "Dune",
+		MediaType:          store.MediaTypeMovie,
+		SubscribeStatus:    store.TrackingAvailable,
+		SubscribeTime:      at(1),
+		DownloadStartTime:  at(5),
+		DownloadFinishTime: at(7),
+		TransferTime:       at(8),
+		AvailableTime:      at(10),
+	}
+
+	latency := requestLatency(req, record, true)
+	if latency == nil {
+		t.Fatal("expected latency for available record")
+	}
+	want := map[store.LatencyStage]time.Duration{
+		store.StageApproval:  time.Hour,
+		store.StageSearch:    4 * time.Hour,
+		store.StageDownload:  2 * time.Hour,
+		store.StageTransfer:  time.Hour,
+		store.StageAvailable: 2 * time.Hour,
+		store.StageTotal:     10 * time.Hour,
+	}
+	for stage, d := range want {
+		if latency.Stages[stage] != d {
+			t.Errorf("stage %s = %v, want %v", stage, latency.Stages[stage], d)
+		}
+	}
+	if latency.Week != "2025-01-20" || !latency.CompletedAt.Equal(*at(10)) {
+		t.Errorf("unexpected week %s / completed %v", latency.Week, latency.CompletedAt)
+	}
+
+	// 没有批准时间的旧请求使用请求时间；跳过下载记录时缺少对应阶段
+	req.ApprovedAt = nil
+	record.SubscribeStatus = store.TrackingTransferred
+	record.DownloadStartTime = nil
+	record.DownloadFinishTime = nil
+	record.AvailableTime = nil
+	latency = requestLatency(req, record, false)
+	if latency == nil {
+		t.Fatal("expected latency for transferred record without media server")
+	}
+	if latency.Stages[store.StageTotal] != 32*time.Hour {
+		t.Errorf("total = %v, want 32h", latency.Stages[store.StageTotal])
+	}
+	if _, ok := latency.Stages[store.StageSearch]; ok {
+		t.Error("search stage should be missing without download start time")
+	}
+
+	// 接入媒体服务器时，已入库但未确认可观看的请求尚未完成
+	if requestLatency(req, record, true) != nil {
+		t.Error("transferred record should not be complete when media server is required")
+	}
+
+	// 订阅时已在库中的请求不计入
+	record.TransferTime = record.SubscribeTime
+	if requestLatency(req, record, false) != nil {
+		t.Error("already existing media should be skipped")
+	}
+}
+
+func TestAggregateLatency(t *testing.T) {
+	var latencies []*store.RequestLatency
+	for i := 1; i <= 10; i++ {
+		latencies = append(latencies, &store.RequestLatency{
+			MediaType: store.MediaTypeMovie,
+			Week:      "2025-01-20",
+			Stages:    map[store.LatencyStage]time.Duration{store.StageTotal: time.Duration(i) * time.Hour},
+		})
+	}
+	latencies = append(latencies, &store.RequestLatency{
+		MediaType: store.MediaTypeTV,
+		Week:      "2025-01-13",
+		Stages:    map[store.LatencyStage]time.Duration{store.StageTotal: 30 * time.Hour},
+	})
+
+	stats := aggregateLatency(latencies)
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 stats, got %d", len(stats))
+	}
+	tv, movie := stats[0], stats[1]
+	if tv.Week != "2025-01-13" || tv.Samples != 1 || tv.P50 != 30*time.Hour || tv.P90 != 30*time.Hour {
+		t.Errorf("unexpected tv stat %+v", tv)
+	}
+	if movie.Samples != 10 || movie.P50 != 5*time.Hour || movie.P90 != 9*time.Hour || movie.Max != 10*time.Hour {
+		t.Errorf("unexpected movie stat %+v", movie)
+	}
+}
+
+func TestFormatLatency(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{50 * time.Hour, "2天2小时"},
+		{5*time.Hour + 20*time.Minute, "5小时20分"},
+		{12*time.Minute + 20*time.Second, "12分"},
+	}
+	for _, tt := range tests {
+		if got := formatLatency(tt.in); got != tt.want {
+			t.Errorf("formatLatency(%v) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package tracker
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+var (
+	// tmdbLinkPattern 匹配 TMDB 链接，如 https://www.themoviedb.org/movie/424
+	tmdbLinkPattern = regexp.MustCompile(`themoviedb\.org/(movie|tv)/(\d+)`)
+	// tmdbIDPattern 匹配 "tmdb:424"、"tmdbid=424"、"TMDB ID: 424"
+	tmdbIDPattern = regexp.MustCompile(`(?i)tmdb\s*(?:id)?\s*[:=：]\s*(\d+)`)
+	// seasonTokenPattern 匹配 "S01"、"S01-S03"，前面不能是字母
+	seasonTokenPattern = regexp.MustCompile(`(?i)(?:^|[^a-z])(S\d{1,3}(?:\s*-\s*S?\d{1,3})?)`)
+	// episodeTokenPattern 匹配 "E01"、"E01-E03"，前面不能是字母（允许 S01E01）
+	episodeTokenPattern = regexp.MustCompile(`(?i)(?:^|[^a-z])(E\d{1,4}(?:\s*-\s*E?\d{1,4})?)`)
+)
+
+// notificationMedia 从通知中解析出的媒体信息
+type notificationMedia struct {
+	TMDBID    int
+	MediaType store.MediaType // 为空表示未知
+	Seasons   string          // 如 "S01"
+	Episodes  string          // 如 "E01-E03"
+}
+
+// parseNotificationMedia 从通知的链接和文本中解析 TMDB ID 和季集
+func parseNotificationMedia(notification *MPNotification) notificationMedia {
+	var media notificationMedia
+
+	sources := []string{notification.Text, notification.Title}
+	if notification.Link != nil {
+		sources = append([]string{*notification.Link}, sources...)
+	}
+
+	for _, source := range sources {
+		if m := tmdbLinkPattern.FindStringSubmatch(source); m != nil {
+			media.TMDBID, _ = strconv.Atoi(m[2])
+			media.MediaType = store.MediaTypeMovie
+			if m[1] == "tv" {
+				media.MediaType = store.MediaTypeTV
+			}
+			break
+		}
+		if m := tmdbIDPattern.FindStringSubmatch(source); m != nil {
+			media.TMDBID, _ = strconv.Atoi(m[1])
+			break
+		}
+	}
+
+	text := notification.Title + " " + notification.Text
+	media.Seasons = joinTokens(seasonTokenPattern, text)
+	media.Episodes = joinTokens(episodeTokenPattern, text)
+	if media.MediaType == "" && media.Seasons != "" {
+		media.MediaType = store.MediaTypeTV
+	}
+
+	return media
+}
+
+// joinTokens 提取所有匹配的片段并以空格连接
+func joinTokens(pattern *regexp.Regexp, text string) string {
+	var tokens []string
+	for _, m := range pattern.FindAllStringSubmatch(text, -1) {
+		tokens = append(tokens, m[1])
+	}
+	return strings.Join(tokens, " ")
+}
+
+// resolveNotification 找到通知对应的跟踪记录
+// 通知中没有 TMDB ID 时，交给 MP 按标题识别
+func (t *Tracker) resolveNotification(notification *MPNotification) (notificationMedia, []*store.SubscriptionTracking, error) {
+	media := parseNotificationMedia(notification)
+
+	if media.TMDBID == 0 {
+		recognized, err := t.mpClient.RecognizeMedia(t.ctx, extractMediaTitle(notification.Title))
+		if err != nil {
+			return media, nil, err
+		}
+		if recognized == nil {
+			return media, nil, nil
+		}
+		media.TMDBID = recognized.TMDBID
+		media.MediaType = store.MediaTypeMovie
+		if recognized.IsTV() {
+			media.MediaType = store.MediaTypeTV
+		}
+	}
+
+	mediaTypes := []store.MediaType{media.MediaType}
+	if media.MediaType == "" {
+		mediaTypes = []store.MediaType{store.MediaTypeMovie, store.MediaTypeTV}
+	}
+
+	var records []*store.SubscriptionTracking
+	for _, mediaType := range mediaTypes {
+		found, err := t.store.ListTrackingByTMDBID(media.TMDBID, mediaType)
+		if err != nil {
+			return media, nil, err
+		}
+		records = append(records, found...)
+	}
+
+	return media, records, nil
+}
+
+// applyNotification 按通知推进匹配到的跟踪记录
+// 与轮询使用同一套状态转换，已由轮询处理过的转换不会重复通知
+// 返回 false 表示没有匹配的跟踪记录
+func (t *Tracker) applyNotification(notification *MPNotification) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	media, records, err := t.resolveNotification(notification)
+	if err != nil {
+		t.logger.Warn("Failed to resolve notification",
+			zap.String("title", notification.Title),
+			zap.Error(err),
+		)
+		return false
+	}
+	if len(records) == 0 {
+		t.logger.Debug("Notification does not match any tracking record",
+			zap.String("title", notification.Title),
+			zap.Int("tmdb_id", media.TMDBID),
+		)
+		return false
+	}
+
+	for _, record := range records {
+		t.logger.Debug("Applying notification to tracking record",
+			zap.String("content_type", notification.CType),
+			zap.String("source_request_id", record.SourceRequestID),
+			zap.String("status", string(record.SubscribeStatus)),
+		)
+
+		switch notification.CType {
+		case "downloadStart":
+			if CanApply(record, store.TrackingDownloading) {
+				t.markDownloadStarted(record)
+			}
+			if record.MediaType == store.MediaTypeTV {
+				t.markEpisodesDownloading(record, &mp.DownloadHistoryItem{Seasons: media.Seasons, Episodes: media.Episodes})
+			}
+		case "downloadComplete":
+			if CanTransition(record.SubscribeStatus, store.TrackingDownloaded) {
+				t.markDownloaded(record)
+			}
+		case "transferComplete":
+			if record.MediaType == store.MediaTypeTV {
+				// 通知中没有集号时无法区分单集和整季，交给入库历史按实际的集处理
+				if media.Episodes == "" {
+					t.logger.Debug("Transfer notification without episodes, waiting for transfer history",
+						zap.String("source_request_id", record.SourceRequestID),
+						zap.String("title", notification.Title),
+					)
+					continue
+				}
+				if !record.SubscribeStatus.IsCompleted() {
+					t.processEpisodeTransfers(record, []mp.TransferHistoryItem{{Seasons: media.Seasons, Episodes: media.Episodes}})
+				}
+				continue
+			}
+			if CanTransition(record.SubscribeStatus, store.TrackingTransferred) {
+				t.markTransferred(record)
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,104 @@
+package tracker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/configs"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+func TestParseNotificationMedia(t *testing.T) {
+	link := "https://www.themoviedb.org/tv/1399"
+
+	tests := []struct {
+		name         string
+		notification MPNotification
+		want         notificationMedia
+	}{
+		{
+			name:         "tmdb link",
+			notification: MPNotification{Title: "权力的游戏 (2011) S01 E01-E03 入库完成", Link: &link},
+			want:         notificationMedia{TMDBID: 1399, MediaType: store.MediaTypeTV, Seasons: "S01", Episodes: "E01-E03"},
+		},
+		{
+			name:         "tmdb id in text",
+			notification: MPNotification{Title: "辛德勒的名单 (1993) 开始下载", Text: "TMDB ID: 424\n站点：xxx"},
+			want:         notificationMedia{TMDBID: 424},
+		},
+		{
+			name:         "compact season episode",
+			notification: MPNotification{Title: "某剧 S02E05 下载完成"},
+			want:         notificationMedia{MediaType: store.MediaTypeTV, Seasons: "S02", Episodes: "E05"},
+		},
+		{
+			name:         "no media info",
+			notification: MPNotification{Title: "辛德勒的名单 (1993) 入库完成", Text: "质量：WEB-DL 1080p x265"},
+			want:         notificationMedia{},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := parseNotificationMedia(&tt.notification); got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestApplyTransferNotificationEpisodes(t *testing.T) {
+	st := newTestStore(t)
+	subscribed := time.Now().Add(-48 * time.Hour)
+	if err := st.SaveTracking(&store.SubscriptionTracking{
+		SourceRequestID: "tv-1",
+		TMDBID:          1399,
+		Title:           "权力的游戏",
+		MediaType:       store.MediaTypeTV,
+		SubscribeStatus: store.TrackingDownloading,
+		SubscribeTime:   &subscribed,
+	}); err != nil {
+		t.Fatalf("save tracking: %v", err)
+	}
+	for episode := 1; episode <= 3; episode++ {
+		if err := st.SaveEpisode(&store.EpisodeTracking{SourceRequestID: "tv-1", Season: 1, Episode: episode, Status: store.TrackingPending}); err != nil {
+			t.Fatalf("save episode: %v", err)
+		}
+	}
+
+	tr := &Tracker{
+		cfg:    &configs.Config{},
+		store:  st,
+		logger: zap.NewNop(),
+		ctx:    context.Background(),
+	}
+	tr.lifecycle = NewStateMachine(st, nil)
+
+	transferred := func() int {
+		episodes, err := st.ListEpisodes("tv-1")
+		if err != nil {
+			t.Fatalf("list episodes: %v", err)
+		}
+		count := 0
+		for _, episode := range episodes {
+			if episode.Status == store.TrackingTransferred {
+				count++
+			}
+		}
+		return count
+	}
+
+	// 只有季号时不当作整季入库
+	link := "https://www.themoviedb.org/tv/1399"
+	if !tr.applyNotification(&MPNotification{CType: "transferComplete", Title: "权力的游戏 (2011) S01 已入库", Link: &link}) {
+		t.Fatal("expected notification to match")
+	}
+	if got := transferred(); got != 0 {
+		t.Errorf("transferred = %d, want 0", got)
+	}
+
+	tr.applyNotification(&MPNotification{CType: "transferComplete", Title: "权力的游戏 (2011) S01 E01-E02 已入库", Link: &link})
+	if got := transferred(); got != 2 {
+		t.Errorf("transferred = %d, want 2", got)
+	}
+}
@@ -0,0 +1,165 @@
+package tracker
+
+import (
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/notify"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+// completedProgress 任务离开下载列表前的进度达到该值才视为下载完成
+// 进度较低时任务可能是被用户删除或被下载器移除，交给入库历史判断
+const completedProgress = 99.0
+
+// downloadProgress 一条跟踪记录对应的下载任务汇总
+type downloadProgress struct {
+	percent float64
+	speed   string
+	eta     string
+}
+
+// checkDownloadProgress 按下载器中的任务更新进度
+func (t *Tracker) checkDownloadProgress(tracking []*store.SubscriptionTracking) {
+	torrents, err := t.mpClient.GetDownloading(t.ctx)
+	if err != nil {
+		t.logger.Warn("Failed to get downloading torrents", zap.Error(err))
+		return
+	}
+	t.applyDownloadProgress(tracking, torrents)
+}
+
+// applyDownloadProgress 按下载任务更新跟踪记录的进度
+// MP 只返回未完成的任务，下载中的记录对应的任务在接近完成后消失即视为下载完成
+func (t *Tracker) applyDownloadProgress(tracking []*store.SubscriptionTracking, torrents []mp.DownloadingTorrent) {
+	for _, record := range tracking {
+		switch record.SubscribeStatus {
+		case store.TrackingSubscribed, store.TrackingManualSearch, store.TrackingDownloading:
+		case store.TrackingDownloaded:
+			// 电影下载完成后不会再回到下载中，不再更新进度
+			if !CanApply(record, store.TrackingDownloading) {
+				continue
+			}
+		default:
+			continue
+		}
+
+		progress, ok := summarizeTorrents(record, torrents)
+		if !ok {
+			if record.SubscribeStatus != store.TrackingDownloading || record.DownloadProgress <= 0 {
+				continue
+			}
+			// 任务接近完成后离开下载列表
+			if record.DownloadProgress >= completedProgress {
+				t.markDownloaded(record)
+				continue
+			}
+			// 任务被删除或被下载器移除，清空进度，是否完成以入库历史为准
+			t.logger.Info("Download task left downloader before completion",
+				zap.String("title", record.Title),
+				zap.Int("tmdb_id", record.TMDBID),
+				zap.Float64("last_progress", record.DownloadProgress),
+			)
+			record.DownloadProgress = 0
+			record.DownloadSpeed = ""
+			record.DownloadETA = ""
+			if err := t.store.UpdateTracking(record); err != nil {
+				t.logger.Error("Failed to update tracking", zap.Error(err))
+			}
+			continue
+		}
+
+		// 剧集后续分集开始下载时从下载完成回到下载中
+		if record.SubscribeStatus != store.TrackingDownloading && CanApply(record, store.TrackingDownloading) {
+			t.markDownloadStarted(record)
+		}
+		if progress.percent >= 100 {
+			t.markDownloaded(record)
+			continue
+		}
+
+		previous := record.DownloadProgress
+		record.DownloadProgress = progress.percent
+		record.DownloadSpeed = progress.speed
+		record.DownloadETA = progress.eta
+		if err := t.store.UpdateTracking(record); err != nil {
+			t.logger.Error("Failed to update tracking", zap.Error(err))
+			continue
+		}
+
+		t.logger.Debug("Download progress",
+			zap.String("title", record.Title),
+			zap.Float64("progress", progress.percent),
+			zap.String("speed", progress.speed),
+			zap.String("eta", progress.eta),
+		)
+
+		if crossedStep(previous, progress.percent, t.cfg.TrackerProgressStep) {
+			n := recordNotification(notify.EventDownloadProgress, record)
+			n.Progress = progress.percent
+			n.Speed = progress.speed
+			n.ETA = progress.eta
+			t.notify(n)
+		}
+	}
+}
+
+// summarizeTorrents 汇总跟踪记录对应的下载任务
+// 多个任务（如剧集分集下载）按大小加权计算进度，速度和剩余时间取最慢的任务
+func summarizeTorrents(record *store.SubscriptionTracking, torrents []mp.DownloadingTorrent) (downloadProgress, bool) {
+	mpType := "电影"
+	if record.MediaType == store.MediaTypeTV {
+		mpType = "电视剧"
+	}
+
+	var result downloadProgress
+	var totalSize, doneSize float64
+	slowest := -1.0
+	matched := 0
+	for _, torrent := range torrents {
+		if torrent.Media.TMDBID != record.TMDBID || (torrent.Media.Type != "" && torrent.Media.Type != mpType) {
+			continue
+		}
+		matched++
+		totalSize += torrent.Size
+		doneSize += torrent.Size * torrent.Progress / 100
+		if slowest < 0 || torrent.Progress < slowest {
+			slowest = torrent.Progress
+			result.speed = torrent.DLSpeed
+			if result.speed != "" {
+				result.speed += "/s"
+			}
+			result.eta = torrent.LeftTime
+		}
+		result.percent += torrent.Progress
+	}
+	if matched == 0 {
+		return result, false
+	}
+
+	if totalSize > 0 {
+		result.percent = doneSize / totalSize * 100
+	} else {
+		result.percent /= float64(matched)
+	}
+	return result, true
+}
+
+// crossedStep 进度是否跨过了通知步长（如 50 表示 50% 时通知）
+func crossedStep(previous, current float64, step int) bool {
+	if step <= 0 || step >= 100 {
+		return false
+	}
+	return int(current)/step > int(previous)/step
+}
+
+// markDownloaded 将跟踪记录标记为下载完成
+func (t *Tracker) markDownloaded(record *store.SubscriptionTracking) {
+	t.logger.Info("Download completed",
+		zap.String("title", record.Title),
+		zap.Int("tmdb_id", record.TMDBID),
+	)
+
+	if err := t.lifecycle.Apply(record, Change{To: store.TrackingDownloaded}); err != nil {
+		t.logger.Error("Failed to update tracking", zap.Error(err))
+	}
+}
@@ -0,0 +1,106 @@
+package tracker
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/configs"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+func newTorrent(tmdbID int, mediaType string, size, progress float64, speed, left string) mp.DownloadingTorrent {
+	torrent := mp.DownloadingTorrent{Size: size, Progress: progress, DLSpeed: speed, LeftTime: left}
+	torrent.Media.TMDBID = tmdbID
+	torrent.Media.Type = mediaType
+	return torrent
+}
+
+func TestSummarizeTorrents(t *testing.T) {
+	record := &store.SubscriptionTracking{TMDBID: 100, MediaType: store.MediaTypeTV}
+	torrents := []mp.DownloadingTorrent{
+		newTorrent(100, "电视剧", 3000, 100, "", ""),
+		newTorrent(100, "电视剧", 1000, 20, "1.5M", "10分钟"),
+		newTorrent(100, "电影", 1000, 50, "3M", "1分钟"), // 同 ID 的电影不匹配
+		newTorrent(200, "电视剧", 1000, 50, "3M", "1分钟"),
+	}
+
+	progress, ok := summarizeTorrents(record, torrents)
+	if !ok {
+		t.Fatal("expected matching torrents")
+	}
+	// (3000*100% + 1000*20%) / 4000 = 80%
+	if math.Abs(progress.percent-80) > 0.001 {
+		t.Errorf("percent = %v, want 80", progress.percent)
+	}
+	if progress.speed != "1.5M/s" || progress.eta != "10分钟" {
+		t.Errorf("speed/eta = %q/%q, want slowest torrent", progress.speed, progress.eta)
+	}
+
+	if _, ok := summarizeTorrents(&store.SubscriptionTracking{TMDBID: 300, MediaType: store.MediaTypeMovie}, torrents); ok {
+		t.Error("expected no matching torrents")
+	}
+}
+
+func TestCrossedStep(t *testing.T) {
+	tests := []struct {
+		previous, current float64
+		step              int
+		want              bool
+	}{
+		{10, 40, 50, false},
+		{40, 55, 50, true},
+		{55, 90, 50, false},
+		{0, 30, 25, true},
+		{0, 99, 0, false},
+	}
+	for _, tt := range tests {
+		if got := crossedStep(tt.previous, tt.current, tt.step); got != tt.want {
+			t.Errorf("crossedStep(%v, %v, %d) = %v, want %v", tt.previous, tt.current, tt.step, got, tt.want)
+		}
+	}
+}
+
+func TestApplyDownloadProgress(t *testing.T) {
+	st := newTestStore(t)
+	var events []store.EventType
+	tr := &Tracker{
+		cfg:    &configs.Config{},
+		store:  st,
+		logger: zap.NewNop(),
+		ctx:    context.Background(),
+	}
+	tr.lifecycle = NewStateMachine(st, func(r *store.SubscriptionTracking, from store.TrackingStatus, change Change) {
+		events = append(events, change.Payload.EventType())
+	})
+
+	// 任务在进度较低时消失不算下载完成
+	deleted := seedTracking(t, st, "deleted", store.TrackingDownloading)
+	deleted.DownloadProgress = 40
+	tr.applyDownloadProgress([]*store.SubscriptionTracking{deleted}, nil)
+	if saved, _ := st.GetTracking("deleted"); saved.SubscribeStatus != store.TrackingDownloading || saved.DownloadProgress != 0 {
+		t.Errorf("deleted task: status = %s, progress = %v", saved.SubscribeStatus, saved.DownloadProgress)
+	}
+
+	// 接近完成后消失视为下载完成
+	finished := seedTracking(t, st, "finished", store.TrackingDownloading)
+	finished.DownloadProgress = 99.5
+	tr.applyDownloadProgress([]*store.SubscriptionTracking{finished}, nil)
+	if saved, _ := st.GetTracking("finished"); saved.SubscribeStatus != store.TrackingDownloaded {
+		t.Errorf("finished task: status = %s, want downloaded", saved.SubscribeStatus)
+	}
+
+	// 已下载完成的电影再次出现在下载列表中时不更新进度，也不再转换
+	events = nil
+	downloaded := seedTracking(t, st, "downloaded", store.TrackingDownloaded)
+	downloaded.DownloadProgress = 100
+	for _, percent := range []float64{60, 100} {
+		torrents := []mp.DownloadingTorrent{newTorrent(100, "电影", 1000, percent, "", "")}
+		tr.applyDownloadProgress([]*store.SubscriptionTracking{downloaded}, torrents)
+	}
+	if downloaded.DownloadProgress != 100 || len(events) != 0 {
+		t.Errorf("downloaded movie: progress = %v, events = %v", downloaded.DownloadProgress, events)
+	}
+}
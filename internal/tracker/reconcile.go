@@ -0,0 +1,240 @@
+package tracker
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/notify"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+// driftKind 订阅不一致类型
+type driftKind string
+
+const (
+	driftMissing  driftKind = "missing"  // MP 中订阅已被删除
+	driftPaused   driftKind = "paused"   // MP 中订阅已暂停
+	driftModified driftKind = "modified" // MP 中订阅指向了其他媒体
+)
+
+// 订阅丢失时的处理方式
+const (
+	driftActionOrphan   = "orphan"
+	driftActionRecreate = "recreate"
+)
+
+// runReconciler 定期对账本地状态与 MP 订阅
+func (t *Tracker) runReconciler() {
+	defer t.wg.Done()
+
+	interval := time.Duration(t.cfg.TrackerReconcileInterval) * time.Minute
+	t.logger.Info("Subscription reconciler started",
+		zap.Duration("interval", interval),
+		zap.String("drift_action", t.cfg.TrackerDriftAction),
+	)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.ctx.Done():
+			t.logger.Info("Subscription reconciler stopped")
+			return
+		case <-ticker.C:
+			if err := t.reconcileSubscriptions(); err != nil {
+				t.logger.Error("Failed to reconcile subscriptions", zap.Error(err))
+			}
+		}
+	}
+}
+
+// reconcileSubscriptions 检查每个已同步链接的 MP 订阅是否仍然存在且一致
+func (t *Tracker) reconcileSubscriptions() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	links, err := t.store.ListLinksByState(store.StatusSynced, 0)
+	if err != nil {
+		return fmt.Errorf("list synced links: %w", err)
+	}
+
+	checked := 0
+	for _, link := range links {
+		if t.ctx.Err() != nil {
+			return nil
+		}
+
+		subscribeID, err := strconv.Atoi(link.MPSubscribeID)
+		if err != nil || subscribeID <= 0 {
+			continue
+		}
+
+		tracking, err := t.store.GetTracking(link.SourceRequestID)
+		if err != nil {
+			t.logger.Error("Failed to get tracking", zap.Error(err))
+			continue
+		}
+		if tracking == nil || !awaitingSubscription(tracking.SubscribeStatus) {
+			continue
+		}
+
+		sub, err := t.mpClient.GetSubscribe(t.ctx, subscribeID)
+		if err != nil {
+			t.logger.Warn("Failed to get MP subscription",
+				zap.String("source_request_id", link.SourceRequestID),
+				zap.Int("subscribe_id", subscribeID),
+				zap.Error(err),
+			)
+			continue
+		}
+		checked++
+
+		kind, reason := t.detectDrift(tracking, sub)
+		if kind == "" {
+			// 之前标记的暂停已恢复
+			if link.LastError != "" {
+				link.LastError = ""
+				if err := t.store.UpdateMPLink(link); err != nil {
+					t.logger.Error("Failed to update mp link", zap.Error(err))
+				}
+			}
+			continue
+		}
+
+		t.handleDrift(link, tracking, subscribeID, kind, reason)
+	}
+
+	t.logger.Debug("Subscription reconcile finished",
+		zap.Int("links", len(links)),
+		zap.Int("checked", checked),
+	)
+
+	return nil
+}
+
+// awaitingSubscription 是否仍在等待 MP 订阅找到资源
+// MP 会在下载完成后自动删除订阅，开始下载之后的记录不再对账
+func awaitingSubscription(status store.TrackingStatus) bool {
+	return status == store.TrackingSubscribed || status == store.TrackingManualSearch
+}
+
+// isOrphaned 对账时是否已被标记为孤立
+func (t *Tracker) isOrphaned(sourceRequestID string) bool {
+	link, err := t.store.GetMPLink(sourceRequestID)
+	if err != nil {
+		t.logger.Error("Failed to get mp link", zap.Error(err))
+		return false
+	}
+	return link != nil && link.State == store.StatusOrphaned
+}
+
+// detectDrift 比较本地记录与 MP 订阅
+func (t *Tracker) detectDrift(tracking *store.SubscriptionTracking, sub *mp.Subscription) (driftKind, string) {
+	if sub == nil {
+		return driftMissing, "MoviePilot 中的订阅已被删除"
+	}
+	if sub.TMDBID != 0 && sub.TMDBID != tracking.TMDBID {
+		return driftModified, fmt.Sprintf("订阅已指向其他媒体 (TMDB %d)", sub.TMDBID)
+	}
+	if tracking.MediaType == store.MediaTypeTV && sub.Season > 0 {
+		if req, err := t.store.GetRequest(tracking.SourceRequestID); err == nil && req != nil {
+			if seasons, err := req.GetSeasons(); err == nil && len(seasons) > 0 && seasons[0] != sub.Season {
+				return driftModified, fmt.Sprintf("订阅季号已变更为 S%02d", sub.Season)
+			}
+		}
+	}
+	if sub.State == mp.SubscribeStatePaused {
+		return driftPaused, "MoviePilot 中的订阅已暂停"
+	}
+	return "", ""
+}
+
+// handleDrift 记录并处理不一致
+func (t *Tracker) handleDrift(link *store.MPLink, tracking *store.SubscriptionTracking, subscribeID int, kind driftKind, reason string) {
+	lastError := "drift: " + reason
+
+	// 暂停只做标记，已标记过的不重复通知
+	if kind == driftPaused && link.LastError == lastError {
+		return
+	}
+
+	action := "flagged"
+	switch {
+	case kind == driftPaused:
+		link.LastError = lastError
+	case t.cfg.TrackerDriftAction == driftActionRecreate:
+		// 重置为待同步，下一轮同步会重新创建订阅
+		action = driftActionRecreate
+		link.State = store.StatusPending
+		link.LastError = lastError
+		if err := t.store.UpdateRequestStatus(link.SourceRequestID, store.StatusPending); err != nil {
+			t.logger.Error("Failed to update request status", zap.Error(err))
+			return
+		}
+	default:
+		action = driftActionOrphan
+		link.State = store.StatusOrphaned
+		link.LastError = lastError
+	}
+
+	payload := &store.DriftDetectedPayload{
+		SubscribeID: subscribeID,
+		Kind:        string(kind),
+		Reason:      reason,
+		Action:      action,
+	}
+
+	if action == driftActionOrphan {
+		// 孤立的记录停止跟踪，状态转换时写入不一致事件
+		tracking.ErrorMessage = reason
+		err := t.lifecycle.Apply(tracking, Change{To: store.TrackingFailed, Payload: payload})
+		if err != nil {
+			t.logger.Error("Failed to update tracking", zap.Error(err))
+		}
+	}
+
+	if err := t.store.UpdateMPLink(link); err != nil {
+		t.logger.Error("Failed to update mp link", zap.Error(err))
+		return
+	}
+
+	t.logger.Warn("Subscription drift detected",
+		zap.String("source_request_id", link.SourceRequestID),
+		zap.String("title", tracking.Title),
+		zap.Int("subscribe_id", subscribeID),
+		zap.String("kind", string(kind)),
+		zap.String("action", action),
+	)
+
+	if action != driftActionOrphan {
+		payload.TMDBID = tracking.TMDBID
+		payload.Title = tracking.Title
+		event, err := store.NewEvent(link.SourceRequestID, payload)
+		if err != nil {
+			t.logger.Error("Failed to encode event", zap.Error(err))
+		} else if err := t.store.SaveEvent(event); err != nil {
+			t.logger.Error("Failed to save event", zap.Error(err))
+		}
+	}
+
+	n := recordNotification(notify.EventDrift, tracking)
+	n.Reason = reason
+	n.Action = driftActionLabel(action)
+	t.notify(n)
+}
+
+// driftActionLabel 处理方式的中文描述
+func driftActionLabel(action string) string {
+	switch action {
+	case driftActionRecreate:
+		return "将在下次同步时重新订阅"
+	case driftActionOrphan:
+		return "已标记为孤立，停止跟踪"
+	default:
+		return "仅标记，请手动检查"
+	}
+}
@@ -0,0 +1,50 @@
+package tracker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/configs"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+func TestAwaitingSubscription(t *testing.T) {
+	for _, status := range allTrackingStatuses {
+		want := status == store.TrackingSubscribed || status == store.TrackingManualSearch
+		if got := awaitingSubscription(status); got != want {
+			t.Errorf("awaitingSubscription(%s) = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestHandleDriftOrphan(t *testing.T) {
+	st := newTestStore(t)
+	record := seedTracking(t, st, "drift-1", store.TrackingSubscribed)
+	link := &store.MPLink{SourceRequestID: "drift-1", MPSubscribeID: "5", State: store.StatusSynced}
+	if err := st.SaveMPLink(link); err != nil {
+		t.Fatalf("save mp link: %v", err)
+	}
+
+	tr := &Tracker{
+		cfg:    &configs.Config{TrackerDriftAction: driftActionOrphan},
+		store:  st,
+		logger: zap.NewNop(),
+		ctx:    context.Background(),
+	}
+	tr.lifecycle = NewStateMachine(st, nil)
+
+	if tr.isOrphaned("drift-1") {
+		t.Fatal("synced link should not be orphaned")
+	}
+	tr.handleDrift(link, record, 5, driftMissing, "MoviePilot 中的订阅已被删除")
+
+	got, _ := st.GetTracking("drift-1")
+	if got.SubscribeStatus != store.TrackingFailed {
+		t.Errorf("status = %s, want failed", got.SubscribeStatus)
+	}
+	// 孤立的记录不再被轮询
+	if !tr.isOrphaned("drift-1") {
+		t.Error("expected link to be orphaned")
+	}
+}
@@ -0,0 +1,178 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/notify"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+// attentionItem 需要人工处理的请求
+type attentionItem struct {
+	SourceRequestID string `json:"source_request_id"`
+	Title           string `json:"title"`
+	Status          string `json:"status"`
+	Days            int    `json:"days"`     // 已订阅天数
+	Searches        int    `json:"searches"` // 已触发的搜索次数
+}
+
+// reportContent 每日报告详情（保存为 DailyReport.ReportContent）
+type reportContent struct {
+	InProgress int             `json:"in_progress"` // 仍在等待入库的请求数
+	Attention  []attentionItem `json:"attention,omitempty"`
+	Latency    *latencySummary `json:"latency,omitempty"` // 近 7 天完成请求的耗时
+}
+
+// runDailyReport 每天在 ReportTime 生成并发送报告
+func (t *Tracker) runDailyReport() {
+	defer t.wg.Done()
+
+	t.logger.Info("Daily report scheduler started", zap.String("report_time", t.cfg.ReportTime))
+
+	for {
+		next := nextReportTime(time.Now(), t.cfg.ReportTime)
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-t.ctx.Done():
+			timer.Stop()
+			t.logger.Info("Daily report scheduler stopped")
+			return
+		case <-timer.C:
+			if err := t.sendDailyReport(time.Now()); err != nil {
+				t.logger.Error("Failed to send daily report", zap.Error(err))
+			}
+		}
+	}
+}
+
+// nextReportTime 计算下一次报告时间，格式错误时使用 09:00
+func nextReportTime(now time.Time, reportTime string) time.Time {
+	clock, err := time.Parse("15:04", reportTime)
+	if err != nil {
+		clock, _ = time.Parse("15:04", "09:00")
+	}
+
+	next := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// sendDailyReport 生成、保存并发送过去 24 小时的报告
+func (t *Tracker) sendDailyReport(now time.Time) error {
+	counts, err := t.store.CountEventsSince(now.Add(-24 * time.Hour))
+	if err != nil {
+		return fmt.Errorf("count events: %w", err)
+	}
+
+	// 耗时统计失败不影响报告发送
+	if err := t.refreshLatency(now); err != nil {
+		t.logger.Warn("Failed to refresh latency stats", zap.Error(err))
+	}
+
+	content, err := t.collectReportContent(now)
+	if err != nil {
+		return err
+	}
+
+	data, _ := json.Marshal(content)
+	report := &store.DailyReport{
+		ReportDate:       now.Format("2006-01-02"),
+		TotalSubscribed:  counts[store.EventSubscribed] + counts[store.EventAdopted],
+		TotalDownloaded:  counts[store.EventDownloadComplete],
+		TotalTransferred: counts[store.EventTransferComplete],
+		TotalFailed:      counts[store.EventFailed],
+		ReportContent:    string(data),
+	}
+	if err := t.store.SaveReport(report); err != nil {
+		return fmt.Errorf("save report: %w", err)
+	}
+
+	t.logger.Info("Daily report generated",
+		zap.String("date", report.ReportDate),
+		zap.Int("attention", len(content.Attention)),
+	)
+
+	t.notify(&notify.Notification{
+		Event:  notify.EventReport,
+		Report: formatDailyReport(report, content),
+	})
+
+	return nil
+}
+
+// collectReportContent 统计仍在等待的请求，找出需要人工处理的请求
+func (t *Tracker) collectReportContent(now time.Time) (*reportContent, error) {
+	content := &reportContent{}
+	for _, status := range []store.TrackingStatus{
+		store.TrackingSubscribed,
+		store.TrackingManualSearch,
+		store.TrackingDownloading,
+		store.TrackingDownloaded,
+		store.TrackingPartiallyTransferred,
+		store.TrackingAiring,
+	} {
+		records, err := t.store.ListTrackingByStatus(status, 0)
+		if err != nil {
+			return nil, fmt.Errorf("list %s tracking: %w", status, err)
+		}
+		content.InProgress += len(records)
+
+		for _, record := range records {
+			if !t.needsAttention(record, now) {
+				continue
+			}
+			content.Attention = append(content.Attention, attentionItem{
+				SourceRequestID: record.SourceRequestID,
+				Title:           record.Title,
+				Status:          string(record.SubscribeStatus),
+				Days:            int(now.Sub(subscribedAt(record)).Hours() / 24),
+				Searches:        record.RetryCount,
+			})
+		}
+	}
+
+	latency, err := t.collectLatencySummary(now)
+	if err != nil {
+		return nil, err
+	}
+	content.Latency = latency
+	return content, nil
+}
+
+// formatDailyReport 格式化为 Telegram HTML 文本
+func formatDailyReport(report *store.DailyReport, content *reportContent) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📅 %s\n\n", report.ReportDate)
+	fmt.Fprintf(&b, "✅ 新订阅: %d\n", report.TotalSubscribed)
+	fmt.Fprintf(&b, "⬇️ 下载完成: %d\n", report.TotalDownloaded)
+	fmt.Fprintf(&b, "📦 入库: %d\n", report.TotalTransferred)
+	fmt.Fprintf(&b, "❌ 失败: %d\n", report.TotalFailed)
+	fmt.Fprintf(&b, "⏳ 等待中: %d\n", content.InProgress)
+
+	if content.Latency != nil {
+		fmt.Fprintf(&b, "\n⏱️ <b>近 7 天完成 %d 个（批准到完成耗时）</b>\n", content.Latency.Completed)
+		for _, line := range content.Latency.Lines {
+			fmt.Fprintf(&b, "• %s (%d): 中位 %s，P90 %s\n", notify.MediaTypeLabel(string(line.MediaType)), line.Samples, formatLatency(line.P50), formatLatency(line.P90))
+		}
+		for _, item := range content.Latency.Slowest {
+			fmt.Fprintf(&b, "🐢 %s — %s\n", html.EscapeString(item.Title), formatLatency(item.Total))
+		}
+	}
+
+	if len(content.Attention) > 0 {
+		fmt.Fprintf(&b, "\n⚠️ <b>需要人工处理 (%d)</b>\n", len(content.Attention))
+		for _, item := range content.Attention {
+			fmt.Fprintf(&b, "• %s — 已订阅 %d 天，搜索 %d 次\n", html.EscapeString(item.Title), item.Days, item.Searches)
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,208 @@
+package tracker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/notify"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+// scheduleRefreshInterval 每条剧集记录重新获取 TMDB 播出计划的间隔
+const scheduleRefreshInterval = 6 * time.Hour
+
+// checkAiringSchedule 按 TMDB 播出计划检查仍在跟踪的剧集
+// 补充新定档的集、提醒播出后超时未下载的集，并在季终集定档后重新判断是否完成
+func (t *Tracker) checkAiringSchedule(now time.Time) error {
+	if t.seasons == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, status := range []store.TrackingStatus{
+		store.TrackingSubscribed,
+		store.TrackingManualSearch,
+		store.TrackingDownloading,
+		store.TrackingDownloaded,
+		store.TrackingPartiallyTransferred,
+		store.TrackingAiring,
+	} {
+		records, err := t.store.ListTrackingByStatus(status, 0)
+		if err != nil {
+			return fmt.Errorf("list %s tracking: %w", status, err)
+		}
+
+		for _, record := range records {
+			if t.ctx.Err() != nil {
+				return nil
+			}
+			if record.MediaType != store.MediaTypeTV {
+				continue
+			}
+
+			episodes, err := t.scheduledEpisodes(record, now)
+			if err != nil {
+				t.logger.Warn("Failed to refresh airing schedule",
+					zap.String("title", record.Title),
+					zap.Int("tmdb_id", record.TMDBID),
+					zap.Error(err),
+				)
+				continue
+			}
+			if len(episodes) == 0 {
+				continue
+			}
+
+			t.checkLateEpisodes(record, episodes, now)
+
+			// 已有集入库的记录按最新的播出计划重新判断
+			if record.SubscribeStatus == store.TrackingPartiallyTransferred || record.SubscribeStatus == store.TrackingAiring {
+				t.updateSeasonStatus(record, episodes, now)
+			}
+		}
+	}
+
+	return nil
+}
+
+// scheduledEpisodes 返回剧集记录的单集跟踪，到期时先按 TMDB 刷新播出计划
+func (t *Tracker) scheduledEpisodes(record *store.SubscriptionTracking, now time.Time) ([]*store.EpisodeTracking, error) {
+	if last, ok := t.scheduleRefreshed[record.SourceRequestID]; ok && now.Sub(last) < scheduleRefreshInterval {
+		return t.store.ListEpisodes(record.SourceRequestID)
+	}
+	t.scheduleRefreshed[record.SourceRequestID] = now
+
+	episodes, err := t.ensureEpisodes(record)
+	if err != nil || len(episodes) == 0 {
+		return episodes, err
+	}
+	return t.refreshSchedule(record, episodes)
+}
+
+// refreshSchedule 按 TMDB 季详情补充新定档的集，更新播出日期和季终集
+func (t *Tracker) refreshSchedule(record *store.SubscriptionTracking, episodes []*store.EpisodeTracking) ([]*store.EpisodeTracking, error) {
+	req, err := t.store.GetRequest(record.SourceRequestID)
+	if err != nil {
+		return nil, fmt.Errorf("get request: %w", err)
+	}
+	if req == nil {
+		return episodes, nil
+	}
+	seasons, err := req.GetSeasons()
+	if err != nil {
+		return episodes, nil
+	}
+	requested, err := req.GetEpisodes()
+	if err != nil {
+		requested = map[int][]int{}
+	}
+
+	tracked := make(map[episodeKey]*store.EpisodeTracking, len(episodes))
+	for _, episode := range episodes {
+		tracked[episodeKey{episode.Season, episode.Episode}] = episode
+	}
+
+	changed := 0
+	for _, season := range seasons {
+		for _, e := range t.tmdbEpisodes(record, season, requested[season]) {
+			episode, ok := tracked[episodeKey{e.season, e.episode}]
+			if !ok {
+				episode = &store.EpisodeTracking{
+					SourceRequestID: record.SourceRequestID,
+					Season:          e.season,
+					Episode:         e.episode,
+					Status:          store.TrackingPending,
+				}
+			} else if sameTime(episode.AirDate, e.airDate) && episode.Finale == e.finale {
+				continue
+			}
+
+			episode.AirDate = e.airDate
+			episode.Finale = e.finale
+			if err := t.store.SaveEpisode(episode); err != nil {
+				return nil, fmt.Errorf("save episode: %w", err)
+			}
+			changed++
+		}
+	}
+
+	if changed == 0 {
+		return episodes, nil
+	}
+
+	t.logger.Info("Airing schedule updated",
+		zap.String("title", record.Title),
+		zap.Int("tmdb_id", record.TMDBID),
+		zap.Int("changed", changed),
+	)
+	return t.store.ListEpisodes(record.SourceRequestID)
+}
+
+// sameTime 比较两个可能为空的时间
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+// checkLateEpisodes 提醒播出超过 TRACKER_EPISODE_LATE_HOURS 小时仍未开始下载的集，每集只提醒一次
+func (t *Tracker) checkLateEpisodes(record *store.SubscriptionTracking, episodes []*store.EpisodeTracking, now time.Time) {
+	if t.cfg.TrackerEpisodeLateHours <= 0 {
+		return
+	}
+	threshold := time.Duration(t.cfg.TrackerEpisodeLateHours) * time.Hour
+
+	var late []*store.EpisodeTracking
+	for _, episode := range episodes {
+		if episode.Status != store.TrackingPending || episode.AirDate == nil || episode.LateTime != nil {
+			continue
+		}
+		// 订阅前已播出的集从订阅时间起算
+		since := *episode.AirDate
+		if record.SubscribeTime != nil && record.SubscribeTime.After(since) {
+			since = *record.SubscribeTime
+		}
+		if now.Sub(since) < threshold {
+			continue
+		}
+		episode.LateTime = &now
+		if err := t.store.SaveEpisode(episode); err != nil {
+			t.logger.Error("Failed to save episode", zap.Error(err))
+			continue
+		}
+		late = append(late, episode)
+	}
+	if len(late) == 0 {
+		return
+	}
+
+	list := store.FormatEpisodes(late)
+	t.logger.Warn("Aired episodes not downloaded",
+		zap.String("title", record.Title),
+		zap.Int("tmdb_id", record.TMDBID),
+		zap.String("episodes", list),
+		zap.Int("late_hours", t.cfg.TrackerEpisodeLateHours),
+	)
+
+	n := recordNotification(notify.EventEpisodesLate, record)
+	n.Episodes = list
+	n.LateHours = t.cfg.TrackerEpisodeLateHours
+	t.notify(n)
+
+	event, err := store.NewEvent(record.SourceRequestID, &store.EpisodesLatePayload{
+		EventBase: store.EventBase{TMDBID: record.TMDBID, Title: record.Title},
+		Episodes:  list,
+		Hours:     t.cfg.TrackerEpisodeLateHours,
+	})
+	if err != nil {
+		t.logger.Error("Failed to encode event", zap.Error(err))
+		return
+	}
+	if err := t.store.SaveEvent(event); err != nil {
+		t.logger.Error("Failed to save event", zap.Error(err))
+	}
+}
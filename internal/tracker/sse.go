@@ -4,12 +4,14 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
 	"go.uber.org/zap"
 )
 
@@ -17,11 +19,11 @@ import (
 type MPNotification struct {
 	Channel           *string `json:"channel"`
 	Source            *string `json:"source"`
-	MType             string  `json:"mtype"`              // 通知类型，如 "订阅"
-	CType             string  `json:"ctype"`              // 内容类型，如 "subscribeAdded", "subscribeComplete"
-	Title             string  `json:"title"`              // 标题
-	Text              string  `json:"text"`               // 详细文本
-	Image             string  `json:"image"`              // 海报图片
+	MType             string  `json:"mtype"` // 通知类型，如 "订阅"
+	CType             string  `json:"ctype"` // 内容类型，如 "subscribeAdded", "subscribeComplete"
+	Title             string  `json:"title"` // 标题
+	Text              string  `json:"text"`  // 详细文本
+	Image             string  `json:"image"` // 海报图片
 	Link              *string `json:"link"`
 	UserID            *string `json:"userid"`
 	Username          string  `json:"username"`
@@ -38,22 +40,42 @@ type SSEMessage struct {
 	Message MPNotification `json:"message"`
 }
 
+// ResourceTokenSource 提供 SSE 认证所需的资源令牌
+type ResourceTokenSource interface {
+	GetResourceToken(ctx context.Context) (*mp.ResourceToken, error)
+}
+
+// SSE 重连退避
+const (
+	sseInitialBackoff = time.Second
+	sseMaxBackoff     = 5 * time.Minute
+)
+
+// errSSEUnauthorized 资源令牌无效或过期
+var errSSEUnauthorized = errors.New("sse unauthorized")
+
 // SSEClient SSE 客户端
 type SSEClient struct {
-	baseURL  string
-	token    string
-	logger   *zap.Logger
-	ctx      context.Context
-	onMessage func(*MPNotification)
+	baseURL     string
+	tokens      ResourceTokenSource
+	logger      *zap.Logger
+	ctx         context.Context
+	onMessage   func(*MPNotification)
+	httpClient  *http.Client
+	token       *mp.ResourceToken
+	lastEventID string // 最后收到的事件 ID，重连时用于续传
 }
 
 // NewSSEClient 创建 SSE 客户端
-func NewSSEClient(baseURL, token string, logger *zap.Logger, ctx context.Context) *SSEClient {
+func NewSSEClient(baseURL string, tokens ResourceTokenSource, logger *zap.Logger, ctx context.Context) *SSEClient {
 	return &SSEClient{
 		baseURL: baseURL,
-		token:   token,
+		tokens:  tokens,
 		logger:  logger,
 		ctx:     ctx,
+		httpClient: &http.Client{
+			Timeout: 0, // SSE 不应该有超时
+		},
 	}
 }
 
@@ -62,94 +84,137 @@ func (c *SSEClient) SetMessageHandler(handler func(*MPNotification)) {
 	c.onMessage = handler
 }
 
-// Connect 连接到 SSE 端点
+// Connect 连接到 SSE 端点，断开后按指数退避重连，直到 ctx 取消
 func (c *SSEClient) Connect() error {
 	url := fmt.Sprintf("%s/api/v1/system/message", c.baseURL)
 
 	c.logger.Info("Connecting to MP SSE endpoint", zap.String("url", url))
 
+	backoff := sseInitialBackoff
 	for {
+		established, err := c.connectOnce(url)
+		if c.ctx.Err() != nil {
+			c.logger.Info("SSE client stopped")
+			return nil
+		}
+
+		// 成功建立过连接则重置退避
+		if established {
+			backoff = sseInitialBackoff
+		}
+		if errors.Is(err, errSSEUnauthorized) {
+			// 丢弃令牌，下次连接前重新获取
+			c.token = nil
+		}
+
+		c.logger.Warn("SSE connection lost, reconnecting",
+			zap.Error(err),
+			zap.Duration("backoff", backoff),
+			zap.String("last_event_id", c.lastEventID),
+		)
+
 		select {
 		case <-c.ctx.Done():
 			c.logger.Info("SSE client stopped")
 			return nil
-		default:
-			if err := c.connectOnce(url); err != nil {
-				c.logger.Error("SSE connection failed, retrying in 30s",
-					zap.Error(err),
-				)
-				time.Sleep(30 * time.Second)
-			}
+		case <-time.After(backoff):
 		}
+
+		backoff = nextBackoff(backoff)
 	}
 }
 
-// connectOnce 单次连接尝试
-func (c *SSEClient) connectOnce(url string) error {
+// nextBackoff 退避时间翻倍，不超过 sseMaxBackoff
+func nextBackoff(d time.Duration) time.Duration {
+	d *= 2
+	if d > sseMaxBackoff {
+		return sseMaxBackoff
+	}
+	return d
+}
+
+// connectOnce 单次连接尝试，返回是否成功建立了连接
+func (c *SSEClient) connectOnce(url string) (bool, error) {
+	if c.token.Expired() {
+		token, err := c.tokens.GetResourceToken(c.ctx)
+		if err != nil {
+			return false, fmt.Errorf("get resource token: %w", err)
+		}
+		c.token = token
+		c.logger.Debug("Got MP resource token", zap.Time("expires_at", token.ExpiresAt))
+	}
+
 	req, err := http.NewRequestWithContext(c.ctx, "GET", url, nil)
 	if err != nil {
-		return fmt.Errorf("create request: %w", err)
+		return false, fmt.Errorf("create request: %w", err)
 	}
 
-	// 设置认证头
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
+	// MP 的消息接口只接受资源令牌 Cookie
+	req.AddCookie(&http.Cookie{Name: mp.ResourceTokenCookie, Value: c.token.Value})
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Cache-Control", "no-cache")
-	req.Header.Set("Connection", "keep-alive")
-
-	client := &http.Client{
-		Timeout: 0, // SSE 不应该有超时
+	if c.lastEventID != "" {
+		req.Header.Set("Last-Event-ID", c.lastEventID)
 	}
 
-	resp, err := client.Do(req)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("do request: %w", err)
+		return false, fmt.Errorf("do request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return false, fmt.Errorf("%w: status %d", errSSEUnauthorized, resp.StatusCode)
+	}
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+		return false, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
 	}
 
 	c.logger.Info("SSE connection established")
 
-	// 读取 SSE 流
-	reader := bufio.NewReader(resp.Body)
+	return true, c.readStream(resp.Body)
+}
+
+// readStream 读取 SSE 流直到连接断开
+func (c *SSEClient) readStream(body io.Reader) error {
+	reader := bufio.NewReader(body)
 	var eventData strings.Builder
+	eventID := ""
 
 	for {
-		select {
-		case <-c.ctx.Done():
-			return nil
-		default:
-			line, err := reader.ReadString('\n')
-			if err != nil {
-				if err == io.EOF {
-					c.logger.Info("SSE connection closed by server")
-					return fmt.Errorf("connection closed")
-				}
-				return fmt.Errorf("read line: %w", err)
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				c.logger.Info("SSE connection closed by server")
+				return fmt.Errorf("connection closed")
 			}
+			return fmt.Errorf("read line: %w", err)
+		}
 
-			line = strings.TrimRight(line, "\n\r")
+		line = strings.TrimRight(line, "\n\r")
 
+		switch {
+		case strings.HasPrefix(line, ":"):
 			// 跳过注释行（用于保持连接）
-			if strings.HasPrefix(line, ":") {
-				continue
+		case line == "":
+			// 空行表示事件结束
+			if eventID != "" {
+				c.lastEventID = eventID
+				eventID = ""
 			}
-
-			// SSE 格式：data: {json}
-			if strings.HasPrefix(line, "data: ") {
-				data := strings.TrimPrefix(line, "data: ")
-				eventData.WriteString(data)
-			} else if line == "" {
-				// 空行表示事件结束
-				if eventData.Len() > 0 {
-					c.handleEvent(eventData.String())
-					eventData.Reset()
-				}
+			if eventData.Len() > 0 {
+				c.handleEvent(eventData.String())
+				eventData.Reset()
+			}
+		case strings.HasPrefix(line, "data:"):
+			// SSE 格式：data: {json}，多行 data 以换行连接
+			if eventData.Len() > 0 {
+				eventData.WriteByte('\n')
 			}
+			eventData.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		case strings.HasPrefix(line, "id:"):
+			eventID = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
 		}
 	}
 }
@@ -0,0 +1,114 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
+	"go.uber.org/zap"
+)
+
+// fakeTokenSource 每次调用返回一个新令牌
+type fakeTokenSource struct {
+	calls int
+}
+
+func (f *fakeTokenSource) GetResourceToken(ctx context.Context) (*mp.ResourceToken, error) {
+	f.calls++
+	return &mp.ResourceToken{
+		Value:     fmt.Sprintf("token-%d", f.calls),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil
+}
+
+func TestSSEClientConnectOnce(t *testing.T) {
+	var lastEventIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(mp.ResourceTokenCookie)
+		if err != nil || cookie.Value != "token-2" {
+			// 第一个令牌视为已失效
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": ping\n\n")
+		fmt.Fprint(w, "id: 41\n")
+		fmt.Fprint(w, "data: {\"message\": {\"title\": \"A 开始下载\",\n")
+		fmt.Fprint(w, "data: \"mtype\": \"下载\"}}\n\n")
+		fmt.Fprint(w, "id: 42\n")
+		fmt.Fprint(w, "data:{\"message\": {\"title\": \"B 入库完成\"}}\n\n")
+	}))
+	defer server.Close()
+
+	tokens := &fakeTokenSource{}
+	client := NewSSEClient(server.URL, tokens, zap.NewNop(), context.Background())
+
+	var titles []string
+	client.SetMessageHandler(func(n *MPNotification) {
+		titles = append(titles, n.Title)
+	})
+	url := server.URL + "/api/v1/system/message"
+
+	// 令牌被拒绝
+	established, err := client.connectOnce(url)
+	if established || err == nil {
+		t.Fatalf("connectOnce() = %v, %v, want unauthorized", established, err)
+	}
+	client.token = nil
+
+	// 重新获取令牌后连接成功，读取到服务端关闭
+	established, err = client.connectOnce(url)
+	if !established {
+		t.Fatalf("connectOnce() not established: %v", err)
+	}
+	if len(titles) != 2 || titles[0] != "A 开始下载" || titles[1] != "B 入库完成" {
+		t.Errorf("titles = %v", titles)
+	}
+	if client.lastEventID != "42" {
+		t.Errorf("lastEventID = %q, want 42", client.lastEventID)
+	}
+
+	// 重连时带上 Last-Event-ID，令牌未过期不重新获取
+	if _, err := client.connectOnce(url); err == nil {
+		t.Fatal("expected connection closed error")
+	}
+	if tokens.calls != 2 {
+		t.Errorf("token calls = %d, want 2", tokens.calls)
+	}
+	if len(lastEventIDs) != 2 || lastEventIDs[0] != "" || lastEventIDs[1] != "42" {
+		t.Errorf("Last-Event-ID headers = %q", lastEventIDs)
+	}
+}
+
+func TestSSEClientStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	client := NewSSEClient("http://127.0.0.1:1", &fakeTokenSource{}, zap.NewNop(), ctx)
+
+	done := make(chan error, 1)
+	go func() { done <- client.Connect() }()
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Connect() = %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Connect() did not return after cancel")
+	}
+}
+
+func TestNextBackoff(t *testing.T) {
+	if got := nextBackoff(time.Second); got != 2*time.Second {
+		t.Errorf("nextBackoff(1s) = %v", got)
+	}
+	if got := nextBackoff(4 * time.Minute); got != sseMaxBackoff {
+		t.Errorf("nextBackoff(4m) = %v, want %v", got, sseMaxBackoff)
+	}
+}
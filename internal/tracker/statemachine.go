@@ -0,0 +1,262 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+)
+
+// ErrIllegalTransition 状态转换不在转换表中
+var ErrIllegalTransition = errors.New("illegal tracking transition")
+
+// transitionKey 状态转换（起始状态 -> 目标状态）
+type transitionKey struct {
+	from store.TrackingStatus
+	to   store.TrackingStatus
+}
+
+// transitionRule 转换时写入的事件和需要记录的时间
+type transitionRule struct {
+	event store.EventType
+	stamp func(record *store.SubscriptionTracking, at time.Time)
+}
+
+// transitions 跟踪状态转换表，不在表中的转换一律拒绝
+// 新建的跟踪记录以 pending 为起始状态
+var transitions = map[transitionKey]transitionRule{
+	// 订阅（重新订阅时清空之前的下载和入库进度）
+	{store.TrackingPending, store.TrackingSubscribed}:      {store.EventSubscribed, stampSubscribed},
+	{store.TrackingSubscribed, store.TrackingSubscribed}:   {store.EventSubscribed, stampSubscribed},
+	{store.TrackingManualSearch, store.TrackingSubscribed}: {store.EventSubscribed, stampSubscribed},
+	{store.TrackingDownloading, store.TrackingSubscribed}:  {store.EventSubscribed, stampSubscribed},
+	{store.TrackingDownloaded, store.TrackingSubscribed}:   {store.EventSubscribed, stampSubscribed},
+	{store.TrackingFailed, store.TrackingSubscribed}:       {store.EventSubscribed, stampSubscribed},
+
+	{store.TrackingPartiallyTransferred, store.TrackingSubscribed}: {store.EventSubscribed, stampSubscribed},
+	{store.TrackingAiring, store.TrackingSubscribed}:               {store.EventSubscribed, stampSubscribed},
+
+	// 订阅时 MP 报告已在媒体库中
+	{store.TrackingPending, store.TrackingTransferred}: {store.EventAlreadyExists, stampAlreadyExists},
+
+	// 停滞订阅触发搜索
+	{store.TrackingSubscribed, store.TrackingManualSearch}:   {store.EventManualSearch, stampSearched},
+	{store.TrackingManualSearch, store.TrackingManualSearch}: {store.EventManualSearch, stampSearched},
+
+	// 开始下载（剧集后续分集可从下载完成重新进入下载中，见 tvOnlyTransitions）
+	{store.TrackingSubscribed, store.TrackingDownloading}:   {store.EventDownloadStarted, stampDownloadStarted},
+	{store.TrackingManualSearch, store.TrackingDownloading}: {store.EventDownloadStarted, stampDownloadStarted},
+	{store.TrackingDownloaded, store.TrackingDownloading}:   {store.EventDownloadStarted, stampDownloadStarted},
+
+	// 下载完成
+	{store.TrackingSubscribed, store.TrackingDownloaded}:   {store.EventDownloadComplete, stampDownloaded},
+	{store.TrackingManualSearch, store.TrackingDownloaded}: {store.EventDownloadComplete, stampDownloaded},
+	{store.TrackingDownloading, store.TrackingDownloaded}:  {store.EventDownloadComplete, stampDownloaded},
+
+	// 入库（下载记录可能被错过，任何等待中的状态都可以直接入库；失败后 MP 重新整理成功也可入库）
+	{store.TrackingSubscribed, store.TrackingTransferred}:   {store.EventTransferComplete, stampTransferred},
+	{store.TrackingManualSearch, store.TrackingTransferred}: {store.EventTransferComplete, stampTransferred},
+	{store.TrackingDownloading, store.TrackingTransferred}:  {store.EventTransferComplete, stampTransferred},
+	{store.TrackingDownloaded, store.TrackingTransferred}:   {store.EventTransferComplete, stampTransferred},
+	{store.TrackingFailed, store.TrackingTransferred}:       {store.EventTransferComplete, stampTransferred},
+
+	{store.TrackingPartiallyTransferred, store.TrackingTransferred}: {store.EventTransferComplete, stampTransferred},
+	{store.TrackingAiring, store.TrackingTransferred}:               {store.EventTransferComplete, stampTransferred},
+
+	// 剧集部分集入库，仍有已播出的集缺失
+	{store.TrackingSubscribed, store.TrackingPartiallyTransferred}:   {store.EventPartiallyTransferred, stampDownloadStarted},
+	{store.TrackingManualSearch, store.TrackingPartiallyTransferred}: {store.EventPartiallyTransferred, stampDownloadStarted},
+	{store.TrackingDownloading, store.TrackingPartiallyTransferred}:  {store.EventPartiallyTransferred, stampDownloadStarted},
+	{store.TrackingDownloaded, store.TrackingPartiallyTransferred}:   {store.EventPartiallyTransferred, stampDownloadStarted},
+	{store.TrackingFailed, store.TrackingPartiallyTransferred}:       {store.EventPartiallyTransferred, stampDownloadStarted},
+	{store.TrackingAiring, store.TrackingPartiallyTransferred}:       {store.EventPartiallyTransferred, stampDownloadStarted},
+
+	// 连载剧集已播出的集均已入库，等待后续集播出（季终集入库后才算完成）
+	{store.TrackingSubscribed, store.TrackingAiring}:           {store.EventAiring, stampAiring},
+	{store.TrackingManualSearch, store.TrackingAiring}:         {store.EventAiring, stampAiring},
+	{store.TrackingDownloading, store.TrackingAiring}:          {store.EventAiring, stampAiring},
+	{store.TrackingDownloaded, store.TrackingAiring}:           {store.EventAiring, stampAiring},
+	{store.TrackingFailed, store.TrackingAiring}:               {store.EventAiring, stampAiring},
+	{store.TrackingPartiallyTransferred, store.TrackingAiring}: {store.EventAiring, stampAiring},
+
+	// 失败（入库失败或订阅丢失；失败后再次失败记录新的原因）
+	{store.TrackingSubscribed, store.TrackingFailed}:   {store.EventFailed, nil},
+	{store.TrackingManualSearch, store.TrackingFailed}: {store.EventFailed, nil},
+	{store.TrackingDownloading, store.TrackingFailed}:  {store.EventFailed, nil},
+	{store.TrackingDownloaded, store.TrackingFailed}:   {store.EventFailed, nil},
+	{store.TrackingFailed, store.TrackingFailed}:       {store.EventFailed, nil},
+
+	{store.TrackingPartiallyTransferred, store.TrackingFailed}: {store.EventFailed, nil},
+	{store.TrackingAiring, store.TrackingFailed}:               {store.EventFailed, nil},
+
+	// 媒体服务器中可观看
+	{store.TrackingTransferred, store.TrackingAvailable}: {store.EventAvailable, stampAvailable},
+}
+
+// tvOnlyTransitions 只允许剧集使用的转换
+// 电影下载完成后不会再有新的分集，重复的下载记录或通知不能让它回到下载中
+var tvOnlyTransitions = map[transitionKey]bool{
+	{store.TrackingDownloaded, store.TrackingDownloading}: true,
+}
+
+// stampSubscribed 记录订阅时间，清空之前的下载和入库进度
+func stampSubscribed(record *store.SubscriptionTracking, at time.Time) {
+	record.SubscribeTime = &at
+	record.DownloadStartTime = nil
+	record.DownloadFinishTime = nil
+	record.TransferTime = nil
+	record.DownloadProgress = 0
+	record.DownloadSpeed = ""
+	record.DownloadETA = ""
+	record.MissingEpisodes = ""
+}
+
+// stampAlreadyExists 订阅时间和入库时间相同，表示订阅时已在库中
+func stampAlreadyExists(record *store.SubscriptionTracking, at time.Time) {
+	record.SubscribeTime = &at
+	record.TransferTime = &at
+}
+
+// stampSearched 记录最近一次搜索时间
+func stampSearched(record *store.SubscriptionTracking, at time.Time) {
+	record.LastRetryTime = &at
+}
+
+// stampDownloadStarted 记录首次开始下载的时间
+func stampDownloadStarted(record *store.SubscriptionTracking, at time.Time) {
+	if record.DownloadStartTime == nil {
+		record.DownloadStartTime = &at
+	}
+}
+
+// stampAiring 记录首次开始下载的时间，清空缺失的集
+func stampAiring(record *store.SubscriptionTracking, at time.Time) {
+	stampDownloadStarted(record, at)
+	record.MissingEpisodes = ""
+}
+
+// stampDownloaded 记录下载完成时间
+func stampDownloaded(record *store.SubscriptionTracking, at time.Time) {
+	stampDownloadStarted(record, at)
+	record.DownloadFinishTime = &at
+	record.DownloadProgress = 100
+	record.DownloadSpeed = ""
+	record.DownloadETA = ""
+}
+
+// stampTransferred 记录入库时间，清空缺失的集
+func stampTransferred(record *store.SubscriptionTracking, at time.Time) {
+	record.TransferTime = &at
+	record.MissingEpisodes = ""
+}
+
+// stampAvailable 记录确认可观看的时间
+func stampAvailable(record *store.SubscriptionTracking, at time.Time) {
+	if record.TransferTime == nil {
+		record.TransferTime = &at
+	}
+	record.AvailableTime = &at
+}
+
+// CanTransition 判断状态转换是否合法（空状态视为 pending）
+func CanTransition(from, to store.TrackingStatus) bool {
+	if from == "" {
+		from = store.TrackingPending
+	}
+	_, ok := transitions[transitionKey{from, to}]
+	return ok
+}
+
+// CanApply 判断记录能否转换到目标状态，在 CanTransition 的基础上检查媒体类型
+func CanApply(record *store.SubscriptionTracking, to store.TrackingStatus) bool {
+	from := record.SubscribeStatus
+	if from == "" {
+		from = store.TrackingPending
+	}
+	if tvOnlyTransitions[transitionKey{from, to}] && record.MediaType != store.MediaTypeTV {
+		return false
+	}
+	return CanTransition(from, to)
+}
+
+// Change 一次状态转换
+type Change struct {
+	To      store.TrackingStatus
+	Payload store.EventPayload // 事件数据，为空时使用转换表中的事件类型；接管订阅等场景可指定其他事件
+	At      time.Time          // 为零值时使用当前时间
+}
+
+// TransitionHook 每次合法转换后调用，用于发送通知
+type TransitionHook func(record *store.SubscriptionTracking, from store.TrackingStatus, change Change)
+
+// StateMachine 跟踪状态机，负责校验转换、记录时间、保存记录和事件
+type StateMachine struct {
+	store store.Store
+	hook  TransitionHook
+}
+
+// NewStateMachine 创建状态机，hook 可以为 nil
+func NewStateMachine(st store.Store, hook TransitionHook) *StateMachine {
+	return &StateMachine{store: st, hook: hook}
+}
+
+// Apply 执行状态转换
+// 非法转换返回 ErrIllegalTransition 且不修改记录；保存失败时恢复记录
+func (m *StateMachine) Apply(record *store.SubscriptionTracking, change Change) error {
+	from := record.SubscribeStatus
+	if from == "" {
+		from = store.TrackingPending
+	}
+
+	rule, ok := transitions[transitionKey{from, change.To}]
+	if !ok || !CanApply(record, change.To) {
+		return fmt.Errorf("%w: %s -> %s", ErrIllegalTransition, from, change.To)
+	}
+
+	at := change.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if change.Payload == nil {
+		change.Payload = store.NewEventPayload(rule.event)
+	}
+	change.At = at
+
+	previous := *record
+	record.SubscribeStatus = change.To
+	if rule.stamp != nil {
+		rule.stamp(record, at)
+	}
+
+	var err error
+	if record.ID == 0 {
+		err = m.store.SaveTracking(record)
+	} else {
+		err = m.store.UpdateTracking(record)
+	}
+	if err != nil {
+		*record = previous
+		return fmt.Errorf("save tracking: %w", err)
+	}
+
+	base := change.Payload.Base()
+	base.TMDBID = record.TMDBID
+	base.Title = record.Title
+	base.From = from
+	base.To = change.To
+
+	event, eventErr := store.NewEvent(record.SourceRequestID, change.Payload)
+	if eventErr == nil {
+		eventErr = m.store.SaveEvent(event)
+	}
+
+	if m.hook != nil {
+		m.hook(record, from, change)
+	}
+
+	if eventErr != nil {
+		return fmt.Errorf("save event: %w", eventErr)
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/configs"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/notify"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+var allTrackingStatuses = []store.TrackingStatus{
+	store.TrackingPending,
+	store.TrackingSubscribed,
+	store.TrackingManualSearch,
+	store.TrackingDownloading,
+	store.TrackingDownloaded,
+	store.TrackingTransferred,
+	store.TrackingFailed,
+	store.TrackingAvailable,
+	store.TrackingPartiallyTransferred,
+	store.TrackingAiring,
+}
+
+func newTestStore(t *testing.T) store.Store {
+	t.Helper()
+	st, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+	return st
+}
+
+// seedTracking 保存指定状态的跟踪记录（pending 不落库，由状态机首次保存）
+func seedTracking(t *testing.T, st store.Store, id string, status store.TrackingStatus) *store.SubscriptionTracking {
+	t.Helper()
+	subscribed := time.Now().Add(-72 * time.Hour)
+	record := &store.SubscriptionTracking{
+		SourceRequestID: id,
+		TMDBID:          100,
+		Title:           "测试",
+		MediaType:       store.MediaTypeMovie,
+		SubscribeStatus: status,
+		SubscribeTime:   &subscribed,
+	}
+	if status == store.TrackingPending {
+		record.SubscribeTime = nil
+		return record
+	}
+	if err := st.SaveTracking(record); err != nil {
+		t.Fatalf("save tracking: %v", err)
+	}
+	saved, err := st.GetTracking(id)
+	if err != nil || saved == nil {
+		t.Fatalf("get tracking: %v", err)
+	}
+	return saved
+}
+
+func TestStateMachineTransitions(t *testing.T) {
+	st := newTestStore(t)
+
+	for key, rule := range transitions {
+		id := fmt.Sprintf("%s-%s", key.from, key.to)
+		record := seedTracking(t, st, id, key.from)
+		if tvOnlyTransitions[key] {
+			record.MediaType = store.MediaTypeTV
+		}
+
+		var hookFrom store.TrackingStatus
+		calls := 0
+		machine := NewStateMachine(st, func(r *store.SubscriptionTracking, from store.TrackingStatus, change Change) {
+			calls++
+			hookFrom = from
+		})
+
+		at := time.Now().Truncate(time.Second)
+		if err := machine.Apply(record, Change{To: key.to, At: at}); err != nil {
+			t.Fatalf("%s: apply: %v", id, err)
+		}
+		if calls != 1 || hookFrom != key.from {
+			t.Errorf("%s: hook called %d times from %q", id, calls, hookFrom)
+		}
+
+		saved, err := st.GetTracking(id)
+		if err != nil || saved == nil {
+			t.Fatalf("%s: get tracking: %v", id, err)
+		}
+		if saved.SubscribeStatus != key.to {
+			t.Errorf("%s: status = %s", id, saved.SubscribeStatus)
+		}
+
+		switch key.to {
+		case store.TrackingSubscribed:
+			if saved.SubscribeTime == nil || !saved.SubscribeTime.Equal(at) || saved.TransferTime != nil {
+				t.Errorf("%s: unexpected subscribe stamps %+v", id, saved)
+			}
+		case store.TrackingManualSearch:
+			if saved.LastRetryTime == nil {
+				t.Errorf("%s: last retry time not set", id)
+			}
+		case store.TrackingDownloading:
+			if saved.DownloadStartTime == nil {
+				t.Errorf("%s: download start time not set", id)
+			}
+		case store.TrackingDownloaded:
+			if saved.DownloadFinishTime == nil || saved.DownloadProgress != 100 {
+				t.Errorf("%s: unexpected download stamps %+v", id, saved)
+			}
+		case store.TrackingTransferred:
+			if saved.TransferTime == nil {
+				t.Errorf("%s: transfer time not set", id)
+			}
+			if key.from == store.TrackingPending && !saved.TransferTime.Equal(*saved.SubscribeTime) {
+				t.Errorf("%s: already existing media should share subscribe and transfer time", id)
+			}
+		case store.TrackingPartiallyTransferred:
+			if saved.DownloadStartTime == nil {
+				t.Errorf("%s: download start time not set", id)
+			}
+		case store.TrackingAvailable:
+			if saved.AvailableTime == nil {
+				t.Errorf("%s: available time not set", id)
+			}
+		}
+
+		events, err := st.ListEvents(id, 10)
+		if err != nil {
+			t.Fatalf("%s: list events: %v", id, err)
+		}
+		if len(events) != 1 || events[0].EventType != rule.event {
+			t.Fatalf("%s: unexpected events %+v", id, events)
+		}
+		payload, err := events[0].Payload()
+		if err != nil {
+			t.Fatalf("%s: decode payload: %v", id, err)
+		}
+		if base := payload.Base(); base.From != key.from || base.To != key.to || base.Title != "测试" {
+			t.Errorf("%s: unexpected payload %+v", id, payload)
+		}
+	}
+}
+
+func TestStateMachineRejectsIllegalTransitions(t *testing.T) {
+	st := newTestStore(t)
+	machine := NewStateMachine(st, func(*store.SubscriptionTracking, store.TrackingStatus, Change) {
+		t.Error("hook called for illegal transition")
+	})
+
+	for _, from := range allTrackingStatuses {
+		for _, to := range allTrackingStatuses {
+			if CanTransition(from, to) {
+				continue
+			}
+			id := fmt.Sprintf("illegal-%s-%s", from, to)
+			record := seedTracking(t, st, id, from)
+			before := *record
+
+			err := machine.Apply(record, Change{To: to})
+			if !errors.Is(err, ErrIllegalTransition) {
+				t.Errorf("%s: expected ErrIllegalTransition, got %v", id, err)
+			}
+			if record.SubscribeStatus != before.SubscribeStatus || record.UpdatedAt != before.UpdatedAt {
+				t.Errorf("%s: record modified", id)
+			}
+			events, _ := st.ListEvents(id, 10)
+			if len(events) != 0 {
+				t.Errorf("%s: unexpected events %+v", id, events)
+			}
+		}
+	}
+
+	// 可观看是终态
+	for _, to := range allTrackingStatuses {
+		if CanTransition(store.TrackingAvailable, to) {
+			t.Errorf("available -> %s should be illegal", to)
+		}
+	}
+}
+
+func TestStateMachineEventOverride(t *testing.T) {
+	st := newTestStore(t)
+	machine := NewStateMachine(st, nil)
+
+	if !CanTransition("", store.TrackingSubscribed) {
+		t.Error("empty status should be treated as pending")
+	}
+
+	record := seedTracking(t, st, "adopted-1", store.TrackingPending)
+	err := machine.Apply(record, Change{
+		To:      store.TrackingSubscribed,
+		Payload: &store.AdoptedPayload{SubscribeIDs: []int{7}},
+	})
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	events, _ := st.ListEvents("adopted-1", 10)
+	if len(events) != 1 || events[0].EventType != store.EventAdopted {
+		t.Fatalf("unexpected events %+v", events)
+	}
+	payload, err := events[0].Payload()
+	if err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	adopted, ok := payload.(*store.AdoptedPayload)
+	if !ok || adopted.From != store.TrackingPending || adopted.TMDBID != 100 || len(adopted.SubscribeIDs) != 1 {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+}
+
+// TestDownloadedMovieStaysDownloaded 电影下载完成后，轮询到的下载记录和重复的开始下载通知不能让它回到下载中
+func TestDownloadedMovieStaysDownloaded(t *testing.T) {
+	st := newTestStore(t)
+	record := seedTracking(t, st, "movie-downloaded", store.TrackingDownloaded)
+
+	var events []store.EventType
+	tr := &Tracker{
+		cfg:    &configs.Config{},
+		store:  st,
+		logger: zap.NewNop(),
+		ctx:    context.Background(),
+	}
+	tr.lifecycle = NewStateMachine(st, func(r *store.SubscriptionTracking, from store.TrackingStatus, change Change) {
+		events = append(events, change.Payload.EventType())
+	})
+
+	if CanApply(record, store.TrackingDownloading) {
+		t.Error("downloaded movie should not go back to downloading")
+	}
+	if err := tr.lifecycle.Apply(record, Change{To: store.TrackingDownloading}); !errors.Is(err, ErrIllegalTransition) {
+		t.Errorf("expected ErrIllegalTransition, got %v", err)
+	}
+
+	tr.processDownloadHistory([]*store.SubscriptionTracking{record}, []mp.DownloadHistoryItem{
+		{ID: 1, Title: "测试", Type: "电影", TMDBID: 100},
+	})
+	link := "https://www.themoviedb.org/movie/100"
+	tr.applyNotification(&MPNotification{CType: "downloadStart", Title: "测试 开始下载", Link: &link})
+
+	if len(events) != 0 {
+		t.Errorf("unexpected transitions %v", events)
+	}
+	saved, _ := st.GetTracking("movie-downloaded")
+	if saved.SubscribeStatus != store.TrackingDownloaded {
+		t.Errorf("status = %s, want downloaded", saved.SubscribeStatus)
+	}
+
+	// 剧集后续分集仍可从下载完成回到下载中
+	show := seedTracking(t, st, "show-downloaded", store.TrackingDownloaded)
+	show.MediaType = store.MediaTypeTV
+	if err := tr.lifecycle.Apply(show, Change{To: store.TrackingDownloading}); err != nil {
+		t.Errorf("tv downloaded -> downloading: %v", err)
+	}
+}
+
+// recordingNotifier 记录收到的通知
+type recordingNotifier struct {
+	events []notify.Event
+}
+
+func (r *recordingNotifier) Name() string { return "recording" }
+
+func (r *recordingNotifier) Notify(n *notify.Notification) {
+	r.events = append(r.events, n.Event)
+}
+
+// TestTrackerApply 同步器的转换以存储中的状态为准，并由跟踪器发送通知
+func TestTrackerApply(t *testing.T) {
+	st := newTestStore(t)
+	notifier := &recordingNotifier{}
+	tr := NewTracker(&configs.Config{}, nil, st, notifier, zap.NewNop())
+
+	// 新请求订阅成功
+	record := &store.SubscriptionTracking{SourceRequestID: "new", TMDBID: 100, Title: "测试", MediaType: store.MediaTypeMovie, SubscribeStatus: store.TrackingPending}
+	if err := tr.Apply(record, Change{To: store.TrackingSubscribed}); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	// 同步器持有的记录已过期，存储中已入库，不能回退为已订阅
+	seedTracking(t, st, "done", store.TrackingTransferred)
+	stale := &store.SubscriptionTracking{SourceRequestID: "done", TMDBID: 100, Title: "测试", MediaType: store.MediaTypeMovie, SubscribeStatus: store.TrackingPending}
+	if err := tr.Apply(stale, Change{To: store.TrackingSubscribed}); !errors.Is(err, ErrIllegalTransition) {
+		t.Errorf("Apply() error = %v, want ErrIllegalTransition", err)
+	}
+	if saved, _ := st.GetTracking("done"); saved.SubscribeStatus != store.TrackingTransferred {
+		t.Errorf("status = %s, want transferred", saved.SubscribeStatus)
+	}
+
+	if len(notifier.events) != 1 || notifier.events[0] != notify.EventSubscribed {
+		t.Errorf("notifications = %v, want [subscribed]", notifier.events)
+	}
+}
@@ -2,42 +2,79 @@ package tracker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/yourusername/jellyseerr-moviepilot-syncer/configs"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/downloader"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mediaserver"
 	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/notify"
 	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
-	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/telegram"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/tmdb"
 	"go.uber.org/zap"
 )
 
 // Tracker 订阅跟踪器
 type Tracker struct {
-	cfg       *configs.Config
-	mpClient  *mp.Client
-	store     store.Store
-	telegram  *telegram.Bot
-	logger    *zap.Logger
-	ctx       context.Context
-	cancel    context.CancelFunc
-	wg        sync.WaitGroup
+	cfg      *configs.Config
+	mpClient *mp.Client
+	store    store.Store
+	notifier notify.Notifier // 为 nil 表示不发送通知
+	logger   *zap.Logger
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	mu       sync.Mutex // 串行化轮询、SSE 和同步器对跟踪记录的状态变更
+
+	lifecycle     *StateMachine       // 跟踪状态转换
+	mediaServer   *mediaserver.Client // 为 nil 表示未配置媒体服务器
+	scanRequested map[string]bool     // 已触发媒体库扫描的请求
+	seasons       seasonSource        // 为 nil 表示未配置 TMDB
+	downloader    downloader.Client   // 为 nil 表示未配置下载器
+
+	scheduleRefreshed map[string]time.Time // 剧集记录上次刷新播出计划的时间
+}
+
+// seasonSource 剧集季详情来源
+type seasonSource interface {
+	GetSeasonDetails(ctx context.Context, tvID, seasonNumber int) (*tmdb.SeasonDetails, error)
 }
 
 // NewTracker 创建跟踪器
-func NewTracker(cfg *configs.Config, mpClient *mp.Client, st store.Store, tg *telegram.Bot, logger *zap.Logger) *Tracker {
+func NewTracker(cfg *configs.Config, mpClient *mp.Client, st store.Store, notifier notify.Notifier, logger *zap.Logger) *Tracker {
 	ctx, cancel := context.WithCancel(context.Background())
-	return &Tracker{
-		cfg:      cfg,
-		mpClient: mpClient,
-		store:    st,
-		telegram: tg,
-		logger:   logger,
-		ctx:      ctx,
-		cancel:   cancel,
+	t := &Tracker{
+		cfg:           cfg,
+		mpClient:      mpClient,
+		store:         st,
+		notifier:      notifier,
+		logger:        logger,
+		ctx:           ctx,
+		cancel:        cancel,
+		scanRequested: make(map[string]bool),
+
+		scheduleRefreshed: make(map[string]time.Time),
+	}
+	t.lifecycle = NewStateMachine(st, t.notifyTransition)
+	if cfg.MediaServerURL != "" {
+		t.mediaServer = mediaserver.NewClient(mediaserver.ServerType(cfg.MediaServerType), cfg.MediaServerURL, cfg.MediaServerAPIKey)
 	}
+	if client := tmdb.NewClient(cfg.TMDPAPIKey); client != nil {
+		t.seasons = client
+	}
+	if cfg.DownloaderURL != "" {
+		client, err := downloader.NewClient(downloader.ClientType(cfg.DownloaderType), cfg.DownloaderURL, cfg.DownloaderUsername, cfg.DownloaderPassword)
+		if err != nil {
+			logger.Warn("Downloader disabled", zap.Error(err))
+		} else {
+			t.downloader = client
+		}
+	}
+	return t
 }
 
 // Start 启动跟踪器
@@ -54,17 +91,46 @@ func (t *Tracker) Start() error {
 
 	// 启动 SSE 监听器（如果启用）
 	if t.cfg.TrackerSSEEnabled {
-		t.logger.Warn("SSE is enabled but MP API requires resource_token which is not available via login API. SSE will likely fail with 403 errors. Consider disabling SSE (TRACKER_SSE_ENABLED=false) and use polling only.")
 		t.wg.Add(1)
 		go t.runSSEListener()
 	} else {
 		t.logger.Info("SSE disabled, using polling only")
 	}
 
+	// 启动 MP 通知 Webhook 接收服务（如果配置）
+	if t.cfg.TrackerWebhookAddr != "" {
+		t.wg.Add(1)
+		go t.runWebhookServer()
+	}
+
 	// 启动轮询检查器
 	t.wg.Add(1)
 	go t.runPollingChecker()
 
+	// 启动已完成订阅清理（策略为 keep 时不启动）
+	if t.cfg.MPCompletedPolicy != "" && t.cfg.MPCompletedPolicy != completedPolicyKeep && t.cfg.MPCleanupInterval > 0 {
+		t.wg.Add(1)
+		go t.runCleanup()
+	}
+
+	// 启动停滞订阅看门狗（智能重试）
+	if t.cfg.SmartRetryEnabled && t.cfg.SmartRetryCheckInterval > 0 {
+		t.wg.Add(1)
+		go t.runWatchdog()
+	}
+
+	// 启动每日报告
+	if t.cfg.ReportEnabled {
+		t.wg.Add(1)
+		go t.runDailyReport()
+	}
+
+	// 启动 MP 订阅对账（如果启用）
+	if t.cfg.TrackerReconcileInterval > 0 {
+		t.wg.Add(1)
+		go t.runReconciler()
+	}
+
 	return nil
 }
 
@@ -83,15 +149,8 @@ func (t *Tracker) runSSEListener() {
 
 	t.logger.Info("SSE listener started")
 
-	// 获取 MP Token
-	token, err := t.mpClient.GetToken(t.ctx)
-	if err != nil {
-		t.logger.Error("Failed to get MP token for SSE", zap.Error(err))
-		return
-	}
-
-	// 创建 SSE 客户端
-	sseClient := NewSSEClient(t.cfg.MPURL, token, t.logger, t.ctx)
+	// 创建 SSE 客户端（资源令牌由 MP 客户端获取和刷新）
+	sseClient := NewSSEClient(t.cfg.MPURL, t.mpClient, t.logger, t.ctx)
 
 	// 设置消息处理器
 	sseClient.SetMessageHandler(func(notification *MPNotification) {
@@ -116,9 +175,7 @@ func (t *Tracker) runPollingChecker() {
 	defer ticker.Stop()
 
 	// 立即执行一次
-	if err := t.checkDownloadStatus(); err != nil {
-		t.logger.Error("Failed to check download status", zap.Error(err))
-	}
+	t.poll()
 
 	for {
 		select {
@@ -126,35 +183,84 @@ func (t *Tracker) runPollingChecker() {
 			t.logger.Info("Polling checker stopped")
 			return
 		case <-ticker.C:
-			if err := t.checkDownloadStatus(); err != nil {
-				t.logger.Error("Failed to check download status", zap.Error(err))
-			}
+			t.poll()
 		}
 	}
 }
 
+// poll 检查 MP 下载和入库状态、剧集播出计划和种子健康，再到媒体服务器确认可观看
+func (t *Tracker) poll() {
+	if err := t.checkDownloadStatus(); err != nil {
+		t.logger.Error("Failed to check download status", zap.Error(err))
+	}
+	if err := t.checkAiringSchedule(time.Now()); err != nil {
+		t.logger.Error("Failed to check airing schedule", zap.Error(err))
+	}
+	if err := t.checkTorrentHealth(time.Now()); err != nil {
+		t.logger.Warn("Failed to check torrent health", zap.Error(err))
+	}
+	if err := t.checkAvailability(); err != nil {
+		t.logger.Error("Failed to check availability", zap.Error(err))
+	}
+}
+
 // checkDownloadStatus 检查下载状态
 func (t *Tracker) checkDownloadStatus() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	t.logger.Debug("Checking download status")
 
 	// 获取所有已订阅但未完成的跟踪记录
-	subscribed, err := t.store.ListTrackingByStatus(store.TrackingSubscribed, 100)
+	subscribed, err := t.store.ListTrackingByStatus(store.TrackingSubscribed, 0)
 	if err != nil {
 		return fmt.Errorf("list subscribed tracking: %w", err)
 	}
 
-	downloading, err := t.store.ListTrackingByStatus(store.TrackingDownloading, 100)
+	downloading, err := t.store.ListTrackingByStatus(store.TrackingDownloading, 0)
 	if err != nil {
 		return fmt.Errorf("list downloading tracking: %w", err)
 	}
 
-	downloaded, err := t.store.ListTrackingByStatus(store.TrackingDownloaded, 100)
+	downloaded, err := t.store.ListTrackingByStatus(store.TrackingDownloaded, 0)
 	if err != nil {
 		return fmt.Errorf("list downloaded tracking: %w", err)
 	}
 
-	allTracking := append(subscribed, downloading...)
+	// 看门狗触发过搜索的记录
+	searching, err := t.store.ListTrackingByStatus(store.TrackingManualSearch, 0)
+	if err != nil {
+		return fmt.Errorf("list manual search tracking: %w", err)
+	}
+
+	// 部分入库和连载中的剧集继续跟踪后续的集
+	partial, err := t.store.ListTrackingByStatus(store.TrackingPartiallyTransferred, 0)
+	if err != nil {
+		return fmt.Errorf("list partially transferred tracking: %w", err)
+	}
+	airing, err := t.store.ListTrackingByStatus(store.TrackingAiring, 0)
+	if err != nil {
+		return fmt.Errorf("list airing tracking: %w", err)
+	}
+
+	// 入库失败的记录继续跟踪，MP 重新整理成功后恢复；已标记为孤立的记录停止跟踪
+	failedRecords, err := t.store.ListTrackingByStatus(store.TrackingFailed, 0)
+	if err != nil {
+		return fmt.Errorf("list failed tracking: %w", err)
+	}
+	var failed []*store.SubscriptionTracking
+	for _, record := range failedRecords {
+		if !t.isOrphaned(record.SourceRequestID) {
+			failed = append(failed, record)
+		}
+	}
+
+	allTracking := append(subscribed, searching...)
+	allTracking = append(allTracking, downloading...)
 	allTracking = append(allTracking, downloaded...)
+	allTracking = append(allTracking, partial...)
+	allTracking = append(allTracking, airing...)
+	allTracking = append(allTracking, failed...)
 
 	if len(allTracking) == 0 {
 		t.logger.Debug("No tracking records to check")
@@ -165,30 +271,45 @@ func (t *Tracker) checkDownloadStatus() error {
 		zap.Int("count", len(allTracking)),
 	)
 
-	// 获取下载历史
-	downloadHistory, err := t.mpClient.GetDownloadHistory(t.ctx, 1, 100)
+	// 获取上次扫描之后的下载历史
+	downloadHistory, downloadScan, err := t.fetchNewDownloadHistory()
 	if err != nil {
 		t.logger.Warn("Failed to get download history", zap.Error(err))
 		// 不返回错误，继续检查入库历史
-	} else if len(downloadHistory) > 0 {
-		t.logger.Debug("Got download history", zap.Int("count", len(downloadHistory)))
-		t.processDownloadHistory(allTracking, downloadHistory)
+	} else {
+		if len(downloadHistory) > 0 {
+			t.logger.Debug("Got download history", zap.Int("count", len(downloadHistory)))
+			if failedID := t.processDownloadHistory(allTracking, downloadHistory); failedID > 0 {
+				downloadScan = downloadScan.retryFrom(failedID)
+			}
+		}
+		t.saveScan(downloadHistoryCursorKey, downloadScan)
 	}
 
-	// 获取入库历史
-	transferHistory, err := t.mpClient.GetTransferHistory(t.ctx, 1, 100)
+	// 获取下载器中的实时进度
+	t.checkDownloadProgress(allTracking)
+
+	// 获取上次扫描之后的入库历史
+	transferHistory, transferScan, err := t.fetchNewTransferHistory()
 	if err != nil {
 		t.logger.Warn("Failed to get transfer history", zap.Error(err))
-	} else if len(transferHistory) > 0 {
-		t.logger.Debug("Got transfer history", zap.Int("count", len(transferHistory)))
-		t.processTransferHistory(allTracking, transferHistory)
+	} else {
+		if len(transferHistory) > 0 {
+			t.logger.Debug("Got transfer history", zap.Int("count", len(transferHistory)))
+			if failedID := t.processTransferHistory(allTracking, transferHistory); failedID > 0 {
+				transferScan = transferScan.retryFrom(failedID)
+			}
+		}
+		t.saveScan(transferHistoryCursorKey, transferScan)
 	}
 
 	return nil
 }
 
 // processDownloadHistory 处理下载历史
-func (t *Tracker) processDownloadHistory(tracking []*store.SubscriptionTracking, history []mp.DownloadHistoryItem) {
+// 返回未能保存的下载记录中最小的 ID，下次扫描时重新处理；全部保存成功时返回 0
+func (t *Tracker) processDownloadHistory(tracking []*store.SubscriptionTracking, history []mp.DownloadHistoryItem) int {
+	failedID := 0
 	for _, record := range tracking {
 		// 在下载历史中查找匹配的记录
 		for _, item := range history {
@@ -206,104 +327,212 @@ func (t *Tracker) processDownloadHistory(tracking []*store.SubscriptionTracking,
 			}
 
 			if matchType {
-				// 找到匹配的下载记录
-				// 只有从 subscribed 状态才发送"开始下载"通知（避免重复）
-				if record.SubscribeStatus == store.TrackingSubscribed {
-					// 从已订阅变为下载中
-					t.logger.Info("Download started",
-						zap.String("title", record.Title),
-						zap.Int("tmdb_id", record.TMDBID),
-					)
-
-					now := time.Now()
-					record.SubscribeStatus = store.TrackingDownloading
-					record.DownloadStartTime = &now
-					if err := t.store.UpdateTracking(record); err != nil {
-						t.logger.Error("Failed to update tracking", zap.Error(err))
-						continue
+				// 找到匹配的下载记录，已在下载中的记录不重复通知
+				if CanApply(record, store.TrackingDownloading) {
+					if err := t.markDownloadStarted(record); err != nil {
+						failedID = lowestID(failedID, item.ID)
 					}
+				}
+				t.trackTorrent(record, &item)
 
-					// 发送 Telegram 通知（只发送一次）
-					if t.telegram != nil && t.telegram.IsEnabled() {
-						t.telegram.NotifyDownloadStarted(record.Title)
-					}
+				// 下载完成的判断由下载器进度（checkDownloadProgress）和入库历史处理
+				// 因为 MP API 的下载历史不提供明确的完成状态
 
-					// 保存事件
-					event := &store.DownloadEvent{
-						SourceRequestID: record.SourceRequestID,
-						EventType:       store.EventDownloadStarted,
-						EventData:       fmt.Sprintf("{\"tmdb_id\": %d, \"title\": \"%s\"}", record.TMDBID, record.Title),
-					}
-					if err := t.store.SaveEvent(event); err != nil {
-						t.logger.Error("Failed to save event", zap.Error(err))
+				// 剧集需要逐集记录，继续检查其余下载记录
+				if record.MediaType == store.MediaTypeTV {
+					if err := t.markEpisodesDownloading(record, &item); err != nil {
+						failedID = lowestID(failedID, item.ID)
 					}
+					continue
 				}
 
-				// 下载完成的判断放在入库历史中处理
-				// 因为 MP API 的下载历史不提供明确的完成状态
-
 				break
 			}
 		}
 	}
+	return failedID
+}
+
+// markDownloadStarted 将跟踪记录变为下载中
+func (t *Tracker) markDownloadStarted(record *store.SubscriptionTracking) error {
+	t.logger.Info("Download started",
+		zap.String("title", record.Title),
+		zap.Int("tmdb_id", record.TMDBID),
+	)
+
+	return t.apply(record, Change{To: store.TrackingDownloading})
 }
 
 // processTransferHistory 处理入库历史
-func (t *Tracker) processTransferHistory(tracking []*store.SubscriptionTracking, history []mp.TransferHistoryItem) {
+// 返回未能保存的入库记录中最小的 ID，下次扫描时重新处理；全部保存成功时返回 0
+func (t *Tracker) processTransferHistory(tracking []*store.SubscriptionTracking, history []mp.TransferHistoryItem) int {
+	failedID := 0
 	for _, record := range tracking {
-		// 在入库历史中查找匹配的记录
+		mpType := "电影"
+		if record.MediaType == store.MediaTypeTV {
+			mpType = "电视剧"
+		}
+
+		// 收集该媒体的入库记录，区分成功和失败
+		var succeeded, failed []mp.TransferHistoryItem
+		matchedID := 0
 		for _, item := range history {
-			// 匹配 TMDB ID
-			if item.TMDBID != record.TMDBID {
+			if item.TMDBID != record.TMDBID || item.Type != mpType {
 				continue
 			}
+			matchedID = lowestID(matchedID, item.ID)
+			if item.Failed() {
+				failed = append(failed, item)
+			} else {
+				succeeded = append(succeeded, item)
+			}
+		}
 
-			// 匹配媒体类型（MP 使用中文，需要转换）
-			matchType := false
-			if record.MediaType == store.MediaTypeMovie && item.Type == "电影" {
-				matchType = true
-			} else if record.MediaType == store.MediaTypeTV && item.Type == "电视剧" {
-				matchType = true
+		// 记录目标路径，媒体服务器确认时用于扫描
+		if len(succeeded) > 0 && succeeded[0].Dest != "" {
+			record.TransferPath = succeeded[0].Dest
+		}
+
+		// 剧集按集处理
+		var err error
+		if record.MediaType == store.MediaTypeTV {
+			if len(succeeded) > 0 {
+				err = t.processEpisodeTransfers(record, succeeded)
+			}
+			if len(failed) > 0 && !record.SubscribeStatus.IsCompleted() {
+				err = errors.Join(err, t.processEpisodeFailures(record, failed))
+			}
+		} else if CanTransition(record.SubscribeStatus, store.TrackingTransferred) {
+			// 只要有一条成功的入库记录就视为入库完成
+			// 历史记录按游标只扫描一次，下载记录可能已被错过，订阅中的记录也直接标记入库
+			if len(succeeded) > 0 {
+				err = t.markTransferred(record)
+			} else if len(failed) > 0 {
+				err = t.markTransferFailed(record, failed[0])
 			}
+		}
+		if err != nil {
+			failedID = lowestID(failedID, matchedID)
+		}
+	}
+	return failedID
+}
 
-			if matchType {
-				// 找到匹配的入库记录
-				// 只有从 downloaded 或 downloading 状态才发送"入库完成"通知（避免重复）
-				// 同时排除已经是 transferred 状态的记录
-				if (record.SubscribeStatus == store.TrackingDownloaded || record.SubscribeStatus == store.TrackingDownloading) &&
-					record.SubscribeStatus != store.TrackingTransferred {
-					t.logger.Info("Transfer completed",
-						zap.String("title", record.Title),
-						zap.Int("tmdb_id", record.TMDBID),
-					)
-
-					now := time.Now()
-					record.SubscribeStatus = store.TrackingTransferred
-					record.TransferTime = &now
-					if err := t.store.UpdateTracking(record); err != nil {
-						t.logger.Error("Failed to update tracking", zap.Error(err))
-						continue
-					}
+// lowestID 返回两个历史记录 ID 中较小的一个，0 表示没有
+func lowestID(current, id int) int {
+	if current == 0 || id < current {
+		return id
+	}
+	return current
+}
 
-					// 发送 Telegram 通知（只发送一次）
-					if t.telegram != nil && t.telegram.IsEnabled() {
-						t.telegram.NotifyTransferComplete(record.Title)
-					}
+// markTransferred 将跟踪记录标记为已入库
+func (t *Tracker) markTransferred(record *store.SubscriptionTracking) error {
+	t.logger.Info("Transfer completed",
+		zap.String("title", record.Title),
+		zap.Int("tmdb_id", record.TMDBID),
+	)
 
-					// 保存事件
-					event := &store.DownloadEvent{
-						SourceRequestID: record.SourceRequestID,
-						EventType:       store.EventTransferComplete,
-						EventData:       fmt.Sprintf("{\"tmdb_id\": %d, \"title\": \"%s\"}", record.TMDBID, record.Title),
-					}
-					if err := t.store.SaveEvent(event); err != nil {
-						t.logger.Error("Failed to save event", zap.Error(err))
-					}
+	return t.apply(record, Change{To: store.TrackingTransferred})
+}
 
-					break
-				}
-			}
+// Apply 供同步器订阅和接管订阅时执行状态转换，与轮询、SSE 和 Webhook 的变更串行
+// 转换以存储中的最新状态为准，成功后按事件发送通知
+func (t *Tracker) Apply(record *store.SubscriptionTracking, change Change) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current, err := t.store.GetTracking(record.SourceRequestID)
+	if err != nil {
+		return fmt.Errorf("get tracking: %w", err)
+	}
+	if current != nil {
+		*record = *current
+	}
+	return t.lifecycle.Apply(record, change)
+}
+
+// apply 执行状态转换，失败时记录日志
+// 只返回保存失败的错误，非法转换说明记录已被其他来源推进，不需要重新处理
+func (t *Tracker) apply(record *store.SubscriptionTracking, change Change) error {
+	err := t.lifecycle.Apply(record, change)
+	if err == nil {
+		return nil
+	}
+	t.logger.Error("Failed to update tracking",
+		zap.String("source_request_id", record.SourceRequestID),
+		zap.Error(err),
+	)
+	if errors.Is(err, ErrIllegalTransition) {
+		return nil
+	}
+	return err
+}
+
+// notify 发送通知，未配置通知渠道时忽略
+// 跟踪记录不保存海报，发送前从请求中补充，供支持图片的渠道使用
+func (t *Tracker) notify(n *notify.Notification) {
+	if t.notifier == nil {
+		return
+	}
+	if n.PosterPath == "" && n.SourceRequestID != "" {
+		if req, err := t.store.GetRequest(n.SourceRequestID); err == nil && req != nil {
+			n.PosterPath = req.PosterPath
+		}
+	}
+	t.notifier.Notify(n)
+}
+
+// recordNotification 创建与跟踪记录关联的通知
+func recordNotification(event notify.Event, record *store.SubscriptionTracking) *notify.Notification {
+	return &notify.Notification{
+		Event:           event,
+		SourceRequestID: record.SourceRequestID,
+		Title:           record.Title,
+		MediaType:       string(record.MediaType),
+		TMDBID:          record.TMDBID,
+		Status:          string(record.SubscribeStatus),
+		Timestamps: &notify.Times{
+			Subscribed:       record.SubscribeTime,
+			DownloadStarted:  record.DownloadStartTime,
+			DownloadFinished: record.DownloadFinishTime,
+			Transferred:      record.TransferTime,
+			Available:        record.AvailableTime,
+		},
+	}
+}
+
+// notifyTransition 状态转换后按事件类型发送通知
+func (t *Tracker) notifyTransition(record *store.SubscriptionTracking, from store.TrackingStatus, change Change) {
+	switch change.Payload.EventType() {
+	case store.EventSubscribed:
+		t.notify(recordNotification(notify.EventSubscribed, record))
+	case store.EventAlreadyExists:
+		t.notify(recordNotification(notify.EventAlreadyExists, record))
+	case store.EventDownloadStarted:
+		t.notify(recordNotification(notify.EventDownloadStarted, record))
+	case store.EventDownloadComplete:
+		t.notify(recordNotification(notify.EventDownloadComplete, record))
+	case store.EventTransferComplete:
+		t.notify(recordNotification(notify.EventTransferred, record))
+	case store.EventManualSearch:
+		n := recordNotification(notify.EventRetrying, record)
+		n.Attempt = record.RetryCount
+		n.MaxAttempts = t.cfg.SmartRetryMaxAttempts
+		t.notify(n)
+	case store.EventFailed:
+		n := recordNotification(notify.EventFailed, record)
+		n.Reason = "入库失败: " + record.ErrorMessage
+		if failed, ok := change.Payload.(*store.FailedPayload); ok && failed.Retried {
+			n.Reason += "（已请求 MoviePilot 重新整理）"
 		}
+		t.notify(n)
+	case store.EventAvailable:
+		// 订阅时已在库中的请求（入库时间即订阅时间）不再通知
+		if record.SubscribeTime != nil && record.TransferTime != nil && record.TransferTime.Equal(*record.SubscribeTime) {
+			return
+		}
+		t.notify(recordNotification(notify.EventAvailable, record))
 	}
 }
 
@@ -326,16 +555,28 @@ func (t *Tracker) handleNotification(notification *MPNotification) {
 		t.handleSubscribeAdded(title, notification)
 	case "subscribeComplete":
 		t.handleSubscribeComplete(title, notification)
+	case "downloadStart", "downloadComplete", "transferComplete":
+		// 能匹配到跟踪记录时按状态机处理，与轮询共用去重逻辑
+		if t.applyNotification(notification) {
+			return
+		}
+		t.handleUntrackedNotification(title, notification)
+	default:
+		t.logger.Debug("Unhandled notification type",
+			zap.String("content_type", notification.CType),
+		)
+	}
+}
+
+// handleUntrackedNotification 处理未被跟踪的媒体（如直接在 MP 中订阅的），仅发送通知
+func (t *Tracker) handleUntrackedNotification(title string, notification *MPNotification) {
+	switch notification.CType {
 	case "downloadStart":
 		t.handleDownloadStart(title, notification)
 	case "downloadComplete":
 		t.handleDownloadComplete(title, notification)
 	case "transferComplete":
 		t.handleTransferComplete(title, notification)
-	default:
-		t.logger.Debug("Unhandled notification type",
-			zap.String("content_type", notification.CType),
-		)
 	}
 }
 
@@ -355,18 +596,11 @@ func (t *Tracker) handleSubscribeComplete(title string, notification *MPNotifica
 
 	// 这可能意味着 MP 已经找到资源并准备开始下载
 	// 我们可以发送一个通知
-	if t.telegram != nil && t.telegram.IsEnabled() {
-		msg := fmt.Sprintf(
-			"🎯 <b>已找到资源</b>\n\n"+
-				"📺 %s\n"+
-				"👤 用户: %s\n"+
-				"⏰ %s",
-			title,
-			notification.Username,
-			time.Now().Format("2006-01-02 15:04:05"),
-		)
-		t.telegram.SendMessageAsync(msg)
-	}
+	t.notify(&notify.Notification{
+		Event:    notify.EventResourceFound,
+		Title:    title,
+		Username: notification.Username,
+	})
 }
 
 // handleDownloadStart 处理开始下载
@@ -375,10 +609,8 @@ func (t *Tracker) handleDownloadStart(title string, notification *MPNotification
 		zap.String("title", title),
 	)
 
-	// 发送 Telegram 通知
-	if t.telegram != nil && t.telegram.IsEnabled() {
-		t.telegram.NotifyDownloadStarted(title)
-	}
+	// 发送通知
+	t.notify(&notify.Notification{Event: notify.EventDownloadStarted, Title: title})
 }
 
 // handleDownloadComplete 处理下载完成
@@ -387,10 +619,8 @@ func (t *Tracker) handleDownloadComplete(title string, notification *MPNotificat
 		zap.String("title", title),
 	)
 
-	// 发送 Telegram 通知
-	if t.telegram != nil && t.telegram.IsEnabled() {
-		t.telegram.NotifyDownloadComplete(title)
-	}
+	// 发送通知
+	t.notify(&notify.Notification{Event: notify.EventDownloadComplete, Title: title})
 }
 
 // handleTransferComplete 处理入库完成
@@ -399,10 +629,8 @@ func (t *Tracker) handleTransferComplete(title string, notification *MPNotificat
 		zap.String("title", title),
 	)
 
-	// 发送 Telegram 通知
-	if t.telegram != nil && t.telegram.IsEnabled() {
-		t.telegram.NotifyTransferComplete(title)
-	}
+	// 发送通知
+	t.notify(&notify.Notification{Event: notify.EventTransferred, Title: title})
 }
 
 // extractMediaTitle 从通知标题中提取媒体标题
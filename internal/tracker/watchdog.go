@@ -0,0 +1,156 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/mp"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+// runWatchdog 定期检查长时间没有下载活动的订阅
+func (t *Tracker) runWatchdog() {
+	defer t.wg.Done()
+
+	interval := time.Duration(t.cfg.SmartRetryCheckInterval) * time.Hour
+	t.logger.Info("Stalled subscription watchdog started",
+		zap.Duration("interval", interval),
+		zap.Int("initial_delay_hours", t.cfg.SmartRetryInitialDelay),
+		zap.Int("max_attempts", t.cfg.SmartRetryMaxAttempts),
+	)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.ctx.Done():
+			t.logger.Info("Stalled subscription watchdog stopped")
+			return
+		case <-ticker.C:
+			if err := t.checkStalledSubscriptions(); err != nil {
+				t.logger.Error("Failed to check stalled subscriptions", zap.Error(err))
+			}
+		}
+	}
+}
+
+// checkStalledSubscriptions 对停滞的订阅触发 MP 搜索
+func (t *Tracker) checkStalledSubscriptions() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	subscribed, err := t.store.ListTrackingByStatus(store.TrackingSubscribed, 0)
+	if err != nil {
+		return fmt.Errorf("list subscribed tracking: %w", err)
+	}
+	searching, err := t.store.ListTrackingByStatus(store.TrackingManualSearch, 0)
+	if err != nil {
+		return fmt.Errorf("list manual search tracking: %w", err)
+	}
+
+	now := time.Now()
+	for _, record := range append(subscribed, searching...) {
+		if t.ctx.Err() != nil {
+			return nil
+		}
+		if !t.searchDue(record, now) {
+			continue
+		}
+		t.searchStalled(record)
+	}
+
+	return nil
+}
+
+// searchDue 判断是否需要再次搜索
+// 首次搜索在订阅后 SmartRetryInitialDelay 小时，之后每次间隔翻倍
+func (t *Tracker) searchDue(record *store.SubscriptionTracking, now time.Time) bool {
+	if record.RetryCount >= t.cfg.SmartRetryMaxAttempts {
+		return false
+	}
+
+	delay := time.Duration(t.cfg.SmartRetryInitialDelay) * time.Hour
+	since := subscribedAt(record)
+	if record.SubscribeStatus == store.TrackingManualSearch && record.LastRetryTime != nil && record.RetryCount > 0 {
+		delay <<= record.RetryCount - 1
+		since = *record.LastRetryTime
+	}
+
+	return now.Sub(since) >= delay
+}
+
+// subscribedAt 返回订阅时间，缺失时使用记录创建时间
+func subscribedAt(record *store.SubscriptionTracking) time.Time {
+	if record.SubscribeTime != nil {
+		return *record.SubscribeTime
+	}
+	return record.CreatedAt
+}
+
+// searchStalled 触发 MP 搜索并将记录标记为手动搜索
+func (t *Tracker) searchStalled(record *store.SubscriptionTracking) {
+	link, err := t.store.GetMPLink(record.SourceRequestID)
+	if err != nil {
+		t.logger.Error("Failed to get mp link", zap.Error(err))
+		return
+	}
+	if link == nil {
+		return
+	}
+	subscribeID, err := strconv.Atoi(link.MPSubscribeID)
+	if err != nil || subscribeID <= 0 {
+		return
+	}
+
+	err = t.mpClient.SearchSubscribe(t.ctx, subscribeID)
+	if errors.Is(err, mp.ErrSubscribeNotFound) {
+		// 订阅已不存在，交给对账处理
+		t.logger.Debug("Stalled subscription no longer exists in MP",
+			zap.String("source_request_id", record.SourceRequestID),
+			zap.Int("subscribe_id", subscribeID),
+		)
+		return
+	}
+	if err != nil {
+		t.logger.Warn("Failed to search subscription",
+			zap.String("title", record.Title),
+			zap.Int("subscribe_id", subscribeID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	t.logger.Info("Searched stalled subscription",
+		zap.String("title", record.Title),
+		zap.Int("subscribe_id", subscribeID),
+		zap.Int("attempt", record.RetryCount+1),
+		zap.Duration("stalled_for", time.Since(subscribedAt(record))),
+	)
+
+	record.RetryCount++
+	err = t.lifecycle.Apply(record, Change{
+		To: store.TrackingManualSearch,
+		Payload: &store.ManualSearchPayload{
+			SubscribeID: subscribeID,
+			Attempt:     record.RetryCount,
+		},
+	})
+	if err != nil {
+		t.logger.Error("Failed to update tracking", zap.Error(err))
+	}
+}
+
+// needsAttention 订阅超过 TrackerEscalateDays 天仍未开始下载，需要人工处理
+func (t *Tracker) needsAttention(record *store.SubscriptionTracking, now time.Time) bool {
+	if t.cfg.TrackerEscalateDays <= 0 {
+		return false
+	}
+	if record.SubscribeStatus != store.TrackingSubscribed && record.SubscribeStatus != store.TrackingManualSearch {
+		return false
+	}
+	return now.Sub(subscribedAt(record)) >= time.Duration(t.cfg.TrackerEscalateDays)*24*time.Hour
+}
@@ -0,0 +1,93 @@
+package tracker
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/configs"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+)
+
+func TestSearchDue(t *testing.T) {
+	tr := &Tracker{cfg: &configs.Config{SmartRetryInitialDelay: 24, SmartRetryMaxAttempts: 3}}
+	now := time.Now()
+	ago := func(hours int) *time.Time {
+		at := now.Add(-time.Duration(hours) * time.Hour)
+		return &at
+	}
+
+	tests := []struct {
+		name   string
+		record *store.SubscriptionTracking
+		want   bool
+	}{
+		{"fresh subscription", &store.SubscriptionTracking{SubscribeStatus: store.TrackingSubscribed, SubscribeTime: ago(2)}, false},
+		{"stalled subscription", &store.SubscriptionTracking{SubscribeStatus: store.TrackingSubscribed, SubscribeTime: ago(30)}, true},
+		// 第二次搜索需要等待 48 小时
+		{"second search too early", &store.SubscriptionTracking{SubscribeStatus: store.TrackingManualSearch, SubscribeTime: ago(100), RetryCount: 2, LastRetryTime: ago(30)}, false},
+		{"second search due", &store.SubscriptionTracking{SubscribeStatus: store.TrackingManualSearch, SubscribeTime: ago(100), RetryCount: 2, LastRetryTime: ago(50)}, true},
+		{"attempts exhausted", &store.SubscriptionTracking{SubscribeStatus: store.TrackingManualSearch, SubscribeTime: ago(500), RetryCount: 3, LastRetryTime: ago(200)}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tr.searchDue(tt.record, now); got != tt.want {
+			t.Errorf("%s: searchDue = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNeedsAttention(t *testing.T) {
+	tr := &Tracker{cfg: &configs.Config{TrackerEscalateDays: 14}}
+	now := time.Now()
+	old := now.AddDate(0, 0, -20)
+	recent := now.AddDate(0, 0, -3)
+
+	if !tr.needsAttention(&store.SubscriptionTracking{SubscribeStatus: store.TrackingManualSearch, SubscribeTime: &old}, now) {
+		t.Error("expected old manual search record to need attention")
+	}
+	if tr.needsAttention(&store.SubscriptionTracking{SubscribeStatus: store.TrackingSubscribed, SubscribeTime: &recent}, now) {
+		t.Error("expected recent record not to need attention")
+	}
+	if tr.needsAttention(&store.SubscriptionTracking{SubscribeStatus: store.TrackingDownloading, SubscribeTime: &old}, now) {
+		t.Error("expected downloading record not to need attention")
+	}
+}
+
+func TestNextReportTime(t *testing.T) {
+	now := time.Date(2025, 1, 21, 10, 30, 0, 0, time.Local)
+
+	if got := nextReportTime(now, "09:00"); !got.Equal(time.Date(2025, 1, 22, 9, 0, 0, 0, time.Local)) {
+		t.Errorf("nextReportTime(09:00) = %v, want tomorrow 09:00", got)
+	}
+	if got := nextReportTime(now, "21:15"); !got.Equal(time.Date(2025, 1, 21, 21, 15, 0, 0, time.Local)) {
+		t.Errorf("nextReportTime(21:15) = %v, want today 21:15", got)
+	}
+}
+
+func TestFormatDailyReport(t *testing.T) {
+	report := &store.DailyReport{ReportDate: "2025-01-21", TotalSubscribed: 2, TotalTransferred: 1}
+	content := &reportContent{
+		InProgress: 3,
+		Attention:  []attentionItem{{Title: "Tom & Jerry", Days: 20, Searches: 3}},
+		Latency: &latencySummary{
+			Completed: 2,
+			Lines:     []latencyLine{{MediaType: store.MediaTypeMovie, Samples: 2, P50: 26 * time.Hour, P90: 50 * time.Hour}},
+			Slowest:   []slowRequest{{Title: "Dune <2>", MediaType: store.MediaTypeMovie, Total: 50 * time.Hour}},
+		},
+	}
+
+	text := formatDailyReport(report, content)
+	if !strings.Contains(text, "需要人工处理 (1)") {
+		t.Errorf("expected attention section, got:\n%s", text)
+	}
+	if !strings.Contains(text, "Tom &amp; Jerry — 已订阅 20 天，搜索 3 次") {
+		t.Errorf("expected escaped attention item, got:\n%s", text)
+	}
+	if !strings.Contains(text, "• 🎬 电影 (2): 中位 1天2小时，P90 2天2小时") {
+		t.Errorf("expected latency line, got:\n%s", text)
+	}
+	if !strings.Contains(text, "🐢 Dune &lt;2&gt; — 2天2小时") {
+		t.Errorf("expected slowest title, got:\n%s", text)
+	}
+}
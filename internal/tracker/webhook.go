@@ -0,0 +1,274 @@
+package tracker
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// webhookPath MP 通知 Webhook 的接收路径
+	webhookPath = "/webhook/moviepilot"
+	// webhookMaxBody 单个请求体的最大字节数
+	webhookMaxBody = 1 << 20
+)
+
+// contentTypeKeywords 没有 ctype 时按标题关键字推断内容类型（按顺序匹配）
+var contentTypeKeywords = []struct {
+	keyword string
+	ctype   string
+}{
+	{"已添加订阅", "subscribeAdded"},
+	{"已完成订阅", "subscribeComplete"},
+	{"订阅完成", "subscribeComplete"},
+	{"开始下载", "downloadStart"},
+	{"下载完成", "downloadComplete"},
+	{"入库完成", "transferComplete"},
+	{"整理完成", "transferComplete"},
+}
+
+// webhookEnvelope MP Webhook 请求体
+// 兼容三种格式：通知本身、SSE 同款 {"message": {...}}、事件 {"type": "...", "data": {...}}
+type webhookEnvelope struct {
+	Type    string          `json:"type"`
+	Data    json.RawMessage `json:"data"`
+	Message *MPNotification `json:"message"`
+}
+
+// webhookNotice notice.message 事件数据，通知类型放在 type 字段
+type webhookNotice struct {
+	MPNotification
+	Type string `json:"type"`
+}
+
+// webhookMedia 事件中的媒体信息
+type webhookMedia struct {
+	TMDBID int    `json:"tmdb_id"`
+	Type   string `json:"type"` // 电影 或 电视剧
+	Title  string `json:"title"`
+	Year   string `json:"year"`
+}
+
+// webhookMeta 事件中的识别信息
+type webhookMeta struct {
+	SeasonEpisode string `json:"season_episode"` // 如 "S01 E01-E03"
+}
+
+// webhookTransfer transfer.complete 事件数据
+type webhookTransfer struct {
+	MediaInfo    webhookMedia `json:"mediainfo"`
+	Meta         webhookMeta  `json:"meta"`
+	TransferInfo struct {
+		Success bool `json:"success"`
+	} `json:"transferinfo"`
+}
+
+// webhookDownload download.added 事件数据
+type webhookDownload struct {
+	Context struct {
+		MediaInfo webhookMedia `json:"media_info"`
+		MetaInfo  webhookMeta  `json:"meta_info"`
+	} `json:"context"`
+	Username string `json:"username"`
+}
+
+// parseWebhookPayload 将 MP Webhook 请求体转换为通知
+// 返回 nil 表示与跟踪无关的事件，可以忽略
+func parseWebhookPayload(body []byte) (*MPNotification, error) {
+	var envelope webhookEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("unmarshal payload: %w", err)
+	}
+
+	var notification *MPNotification
+	switch {
+	case envelope.Message != nil:
+		notification = envelope.Message
+	case envelope.Type == "transfer.complete":
+		var data webhookTransfer
+		if err := json.Unmarshal(envelope.Data, &data); err != nil {
+			return nil, fmt.Errorf("unmarshal transfer event: %w", err)
+		}
+		// 入库失败由轮询处理
+		if !data.TransferInfo.Success {
+			return nil, nil
+		}
+		notification = mediaNotification(data.MediaInfo, data.Meta, "transferComplete", "入库完成")
+	case envelope.Type == "download.added":
+		var data webhookDownload
+		if err := json.Unmarshal(envelope.Data, &data); err != nil {
+			return nil, fmt.Errorf("unmarshal download event: %w", err)
+		}
+		notification = mediaNotification(data.Context.MediaInfo, data.Context.MetaInfo, "downloadStart", "开始下载")
+		if notification == nil {
+			return nil, nil
+		}
+		notification.Username = data.Username
+	case len(envelope.Data) > 0:
+		var notice webhookNotice
+		if err := json.Unmarshal(envelope.Data, &notice); err != nil {
+			return nil, fmt.Errorf("unmarshal notice event: %w", err)
+		}
+		notification = &notice.MPNotification
+		if notification.MType == "" {
+			notification.MType = notice.Type
+		}
+	default:
+		notification = &MPNotification{}
+		if err := json.Unmarshal(body, notification); err != nil {
+			return nil, fmt.Errorf("unmarshal notification: %w", err)
+		}
+	}
+
+	if notification == nil || notification.Title == "" {
+		return nil, nil
+	}
+	if notification.CType == "" {
+		notification.CType = inferContentType(notification.Title)
+	}
+	return notification, nil
+}
+
+// mediaNotification 根据事件中的媒体信息构造通知，链接指向 TMDB 以便按 ID 匹配
+func mediaNotification(media webhookMedia, meta webhookMeta, ctype, suffix string) *MPNotification {
+	if media.Title == "" {
+		return nil
+	}
+
+	title := media.Title
+	if media.Year != "" {
+		title += " (" + media.Year + ")"
+	}
+	if meta.SeasonEpisode != "" {
+		title += " " + meta.SeasonEpisode
+	}
+
+	notification := &MPNotification{
+		CType: ctype,
+		Title: title + " " + suffix,
+	}
+	if media.TMDBID > 0 {
+		kind := "movie"
+		if media.Type == "电视剧" {
+			kind = "tv"
+		}
+		link := fmt.Sprintf("https://www.themoviedb.org/%s/%d", kind, media.TMDBID)
+		notification.Link = &link
+	}
+	return notification
+}
+
+// inferContentType 按标题关键字推断内容类型
+func inferContentType(title string) string {
+	for _, k := range contentTypeKeywords {
+		if strings.Contains(title, k.keyword) {
+			return k.ctype
+		}
+	}
+	return ""
+}
+
+// webhookHandler 接收 MP Webhook 的 HTTP 处理器
+type webhookHandler struct {
+	token  string
+	logger *zap.Logger
+	handle func(*MPNotification)
+}
+
+// ServeHTTP 校验令牌、解析请求体并交给通知处理器
+func (h *webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !authorizedRequest(r, h.token) {
+		h.logger.Warn("Rejected unauthorized webhook request", zap.String("remote_addr", r.RemoteAddr))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, webhookMaxBody))
+	if err != nil {
+		http.Error(w, "read body failed", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	notification, err := parseWebhookPayload(body)
+	if err != nil {
+		h.logger.Warn("Invalid webhook payload", zap.Error(err))
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if notification == nil {
+		h.logger.Debug("Ignored webhook payload", zap.ByteString("body", body))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	h.handle(notification)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorizedRequest 支持 Authorization: Bearer <token> 或 ?token=<token>
+func authorizedRequest(r *http.Request, expected string) bool {
+	token := r.URL.Query().Get("token")
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		token = strings.TrimPrefix(auth, "Bearer ")
+	}
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+// runWebhookServer 运行 Webhook 接收服务（MP 通知和 Jellyfin 新增条目），直到跟踪器停止
+func (t *Tracker) runWebhookServer() {
+	defer t.wg.Done()
+
+	mux := http.NewServeMux()
+	mux.Handle(webhookPath, &webhookHandler{
+		token:  t.cfg.TrackerWebhookToken,
+		logger: t.logger,
+		handle: t.handleNotification,
+	})
+	mux.Handle(jellyfinWebhookPath, &jellyfinHandler{
+		token:  t.cfg.TrackerWebhookToken,
+		logger: t.logger,
+		handle: t.handleJellyfinItemAdded,
+	})
+	server := &http.Server{
+		Addr:              t.cfg.TrackerWebhookAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- server.ListenAndServe()
+	}()
+
+	t.logger.Info("Webhook receiver started",
+		zap.String("addr", t.cfg.TrackerWebhookAddr),
+		zap.Strings("paths", []string{webhookPath, jellyfinWebhookPath}),
+	)
+
+	select {
+	case <-t.ctx.Done():
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			t.logger.Warn("Failed to shutdown webhook receiver", zap.Error(err))
+		}
+		t.logger.Info("Webhook receiver stopped")
+	case err := <-errChan:
+		if !errors.Is(err, http.ErrServerClosed) {
+			t.logger.Error("Webhook receiver failed", zap.Error(err))
+		}
+	}
+}
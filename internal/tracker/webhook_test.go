@@ -0,0 +1,150 @@
+package tracker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+// loadFixture 读取 testdata 中录制的请求体
+func loadFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture %s: %v", name, err)
+	}
+	return data
+}
+
+func TestParseWebhookPayload(t *testing.T) {
+	tests := []struct {
+		fixture   string
+		wantNil   bool
+		wantCType string
+		wantTitle string
+		wantMedia notificationMedia
+	}{
+		{
+			fixture:   "webhook_notice_download.json",
+			wantCType: "downloadStart",
+			wantTitle: "沙丘2 (2024) 开始下载",
+			wantMedia: notificationMedia{TMDBID: 693134, MediaType: store.MediaTypeMovie},
+		},
+		{
+			fixture:   "webhook_notice_transfer_tv.json",
+			wantCType: "transferComplete",
+			wantTitle: "三体 (2023) S01 E01-E03 入库完成",
+			wantMedia: notificationMedia{MediaType: store.MediaTypeTV, Seasons: "S01", Episodes: "E01-E03"},
+		},
+		{
+			fixture:   "webhook_message.json",
+			wantCType: "subscribeAdded",
+			wantTitle: "辛德勒的名单 (1993) 已添加订阅",
+			wantMedia: notificationMedia{TMDBID: 424, MediaType: store.MediaTypeMovie},
+		},
+		{
+			fixture:   "webhook_transfer_complete.json",
+			wantCType: "transferComplete",
+			wantTitle: "三体 (2023) S01 E04 入库完成",
+			wantMedia: notificationMedia{TMDBID: 108545, MediaType: store.MediaTypeTV, Seasons: "S01", Episodes: "E04"},
+		},
+		{
+			fixture:   "webhook_download_added.json",
+			wantCType: "downloadStart",
+			wantTitle: "沙丘2 (2024) 开始下载",
+			wantMedia: notificationMedia{TMDBID: 693134, MediaType: store.MediaTypeMovie},
+		},
+		{
+			fixture: "webhook_transfer_failed.json",
+			wantNil: true,
+		},
+		{
+			fixture: "webhook_download_added_no_title.json",
+			wantNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			notification, err := parseWebhookPayload(loadFixture(t, tt.fixture))
+			if err != nil {
+				t.Fatalf("parseWebhookPayload() error = %v", err)
+			}
+			if tt.wantNil {
+				if notification != nil {
+					t.Fatalf("parseWebhookPayload() = %+v, want nil", notification)
+				}
+				return
+			}
+			if notification == nil {
+				t.Fatal("parseWebhookPayload() = nil")
+			}
+			if notification.CType != tt.wantCType {
+				t.Errorf("CType = %q, want %q", notification.CType, tt.wantCType)
+			}
+			if notification.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", notification.Title, tt.wantTitle)
+			}
+			if got := parseNotificationMedia(notification); got != tt.wantMedia {
+				t.Errorf("media = %+v, want %+v", got, tt.wantMedia)
+			}
+		})
+	}
+
+	if _, err := parseWebhookPayload([]byte("not json")); err == nil {
+		t.Error("expected error for invalid payload")
+	}
+}
+
+func TestWebhookHandler(t *testing.T) {
+	var received []*MPNotification
+	handler := &webhookHandler{
+		token:  "secret",
+		logger: zap.NewNop(),
+		handle: func(n *MPNotification) { received = append(received, n) },
+	}
+	body := string(loadFixture(t, "webhook_notice_download.json"))
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		auth   string
+		body   string
+		want   int
+	}{
+		{"missing token", http.MethodPost, webhookPath, "", body, http.StatusUnauthorized},
+		{"wrong token", http.MethodPost, webhookPath + "?token=nope", "", body, http.StatusUnauthorized},
+		{"wrong method", http.MethodGet, webhookPath + "?token=secret", "", "", http.StatusMethodNotAllowed},
+		{"invalid body", http.MethodPost, webhookPath + "?token=secret", "", "{", http.StatusBadRequest},
+		{"query token", http.MethodPost, webhookPath + "?token=secret", "", body, http.StatusNoContent},
+		{"bearer token", http.MethodPost, webhookPath, "Bearer secret", body, http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+
+	if len(received) != 2 {
+		t.Fatalf("received %d notifications, want 2", len(received))
+	}
+	if received[0].CType != "downloadStart" {
+		t.Errorf("CType = %q", received[0].CType)
+	}
+}
@@ -0,0 +1,262 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/notify"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+// 请求头
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderSignature = "X-Signature-256"
+)
+
+// defaultBackoff 第一次重试前的等待时间，之后每次翻倍
+const defaultBackoff = 2 * time.Second
+
+// Sender 将通知以 JSON POST 到配置的地址
+// 每个地址的投递都记录到 webhook_deliveries，重试耗尽后标记为失败，可以通过 Replay 重放
+type Sender struct {
+	urls       []string
+	secret     string
+	maxRetries int
+	backoff    time.Duration
+	store      store.Store
+	httpClient *http.Client
+	logger     *zap.Logger
+	wg         sync.WaitGroup
+}
+
+// NewSender 创建 Webhook 发送器
+func NewSender(urls []string, secret string, maxRetries int, st store.Store, logger *zap.Logger) *Sender {
+	return &Sender{
+		urls:       urls,
+		secret:     secret,
+		maxRetries: maxRetries,
+		backoff:    defaultBackoff,
+		store:      st,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		logger: logger,
+	}
+}
+
+// Name 实现 notify.Notifier
+func (s *Sender) Name() string {
+	return "webhook"
+}
+
+// Notify 实现 notify.Notifier，为每个地址记录一条投递并异步发送
+func (s *Sender) Notify(n *notify.Notification) {
+	payload, err := json.Marshal(n)
+	if err != nil {
+		s.logger.Error("Failed to marshal webhook payload", zap.Error(err))
+		return
+	}
+
+	for _, url := range s.urls {
+		delivery := &store.WebhookDelivery{
+			URL:             url,
+			Event:           string(n.Event),
+			SourceRequestID: n.SourceRequestID,
+			Payload:         string(payload),
+		}
+		if err := s.store.SaveWebhookDelivery(delivery); err != nil {
+			s.logger.Error("Failed to save webhook delivery",
+				zap.String("url", url),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.deliver(context.Background(), delivery)
+		}()
+	}
+}
+
+// Wait 等待正在进行的投递完成
+func (s *Sender) Wait() {
+	s.wg.Wait()
+}
+
+// WaitTimeout 最多等待 timeout，返回投递是否已全部完成
+// 超时未完成的投递保持 pending，超过重试窗口后由 ReplayFailed 重放
+func (s *Sender) WaitTimeout(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// retryWindow 一次投递（含全部重试）最长持续的时间
+func (s *Sender) retryWindow() time.Duration {
+	window := time.Duration(s.maxRetries+1) * s.httpClient.Timeout
+	delay := s.backoff
+	for i := 0; i < s.maxRetries; i++ {
+		window += delay
+		delay *= 2
+	}
+	return window
+}
+
+// Replay 重新投递指定记录，与首次投递一样失败后按退避重试
+func (s *Sender) Replay(ctx context.Context, id int64) error {
+	delivery, err := s.store.GetWebhookDelivery(id)
+	if err != nil {
+		return fmt.Errorf("get webhook delivery: %w", err)
+	}
+	if delivery == nil {
+		return fmt.Errorf("webhook delivery %d not found", id)
+	}
+	if !s.deliver(ctx, delivery) {
+		return fmt.Errorf("webhook delivery %d failed: %s", id, delivery.LastError)
+	}
+	return nil
+}
+
+// ReplayFailed 重新投递所有失败的记录，以及超过重试窗口仍为 pending 的记录（进程在投递完成前退出），返回成功的数量
+func (s *Sender) ReplayFailed(ctx context.Context) (int, error) {
+	deliveries, err := s.store.ListWebhookDeliveries(store.DeliveryFailed, 0)
+	if err != nil {
+		return 0, fmt.Errorf("list failed webhook deliveries: %w", err)
+	}
+	pending, err := s.store.ListWebhookDeliveries(store.DeliveryPending, 0)
+	if err != nil {
+		return 0, fmt.Errorf("list pending webhook deliveries: %w", err)
+	}
+	staleBefore := time.Now().Add(-s.retryWindow())
+	for _, delivery := range pending {
+		if delivery.UpdatedAt.Before(staleBefore) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	delivered := 0
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return delivered, ctx.Err()
+		}
+		if s.deliver(ctx, delivery) {
+			delivered++
+		}
+	}
+
+	s.logger.Info("Webhook deliveries replayed",
+		zap.Int("replayed", len(deliveries)),
+		zap.Int("delivered", delivered),
+	)
+	return delivered, nil
+}
+
+// deliver 发送一条投递，失败时按指数退避重试，返回是否成功
+func (s *Sender) deliver(ctx context.Context, delivery *store.WebhookDelivery) bool {
+	delivery.Status = store.DeliveryPending
+	delay := s.backoff
+
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			if !notify.SleepContext(ctx, delay) {
+				delivery.LastError = ctx.Err().Error()
+				break
+			}
+			delay *= 2
+		}
+
+		delivery.Attempts++
+		statusCode, err := s.post(ctx, delivery)
+		delivery.StatusCode = statusCode
+		if err == nil {
+			now := time.Now()
+			delivery.Status = store.DeliveryDelivered
+			delivery.LastError = ""
+			delivery.DeliveredAt = &now
+			s.save(delivery)
+			return true
+		}
+
+		delivery.LastError = err.Error()
+		s.logger.Warn("Webhook delivery failed",
+			zap.Int64("delivery_id", delivery.ID),
+			zap.String("url", delivery.URL),
+			zap.String("event", delivery.Event),
+			zap.Int("attempt", delivery.Attempts),
+			zap.Error(err),
+		)
+	}
+
+	delivery.Status = store.DeliveryFailed
+	s.save(delivery)
+	return false
+}
+
+// post 发送一次请求，非 2xx 响应视为失败
+func (s *Sender) post(ctx context.Context, delivery *store.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, "POST", delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	if s.secret != "" {
+		req.Header.Set(HeaderSignature, Sign(s.secret, body))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(respBody))
+	}
+	return resp.StatusCode, nil
+}
+
+// save 保存投递结果
+func (s *Sender) save(delivery *store.WebhookDelivery) {
+	if err := s.store.UpdateWebhookDelivery(delivery); err != nil {
+		s.logger.Error("Failed to update webhook delivery",
+			zap.Int64("delivery_id", delivery.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+// Sign 计算请求体的签名："sha256=" + HMAC-SHA256(secret, body) 的十六进制值
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,164 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/notify"
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/store"
+	"go.uber.org/zap"
+)
+
+func TestSender(t *testing.T) {
+	st, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+
+	var calls atomic.Int32
+	var failing atomic.Bool
+	failing.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(HeaderSignature) != Sign("secret", body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(HeaderEvent) != "failed" || r.Header.Get(HeaderDelivery) == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var n notify.Notification
+		if err := json.Unmarshal(body, &n); err != nil || n.SourceRequestID != "movie-1" || n.TMDBID != 100 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// 前两次失败，第三次成功
+		if failing.Load() && calls.Load() < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender := NewSender([]string{server.URL}, "secret", 2, st, zap.NewNop())
+	sender.backoff = time.Millisecond
+
+	n := &notify.Notification{
+		Event:           notify.EventFailed,
+		Time:            time.Now(),
+		SourceRequestID: "movie-1",
+		TMDBID:          100,
+		Title:           "测试",
+		Status:          "failed",
+	}
+	sender.Notify(n)
+	sender.Wait()
+
+	delivery, err := st.GetWebhookDelivery(1)
+	if err != nil || delivery == nil {
+		t.Fatalf("get delivery: %v", err)
+	}
+	if delivery.Status != store.DeliveryDelivered || delivery.Attempts != 3 || delivery.StatusCode != http.StatusNoContent || delivery.DeliveredAt == nil {
+		t.Errorf("unexpected delivery %+v", delivery)
+	}
+
+	// 重试耗尽后标记为失败
+	calls.Store(0)
+	sender.maxRetries = 1
+	sender.Notify(n)
+	sender.Wait()
+
+	failed, err := st.ListWebhookDeliveries(store.DeliveryFailed, 0)
+	if err != nil {
+		t.Fatalf("list deliveries: %v", err)
+	}
+	if len(failed) != 1 || failed[0].Attempts != 2 || failed[0].StatusCode != http.StatusInternalServerError || failed[0].LastError == "" {
+		t.Fatalf("unexpected failed deliveries %+v", failed)
+	}
+
+	// 接收端恢复后重放
+	failing.Store(false)
+	delivered, err := sender.ReplayFailed(context.Background())
+	if err != nil || delivered != 1 {
+		t.Fatalf("ReplayFailed() = %d, %v", delivered, err)
+	}
+	replayed, _ := st.GetWebhookDelivery(failed[0].ID)
+	if replayed.Status != store.DeliveryDelivered || replayed.Attempts != 3 || replayed.LastError != "" {
+		t.Errorf("unexpected replayed delivery %+v", replayed)
+	}
+
+	if err := sender.Replay(context.Background(), 99); err == nil {
+		t.Error("expected error for missing delivery")
+	}
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '{"event":"failed"}' | openssl dgst -sha256 -hmac secret
+	want := "sha256=2cfe34156711bc6b623da98f179a49f10a70d93a48e0baf1940da7e58a6b1d8a"
+	if got := Sign("secret", []byte(`{"event":"failed"}`)); got != want {
+		t.Errorf("Sign() = %q, want %q", got, want)
+	}
+}
+
+func TestReplayStalePending(t *testing.T) {
+	st, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	defer st.Close()
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(HeaderEvent) == "slow" {
+			<-release
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender := NewSender([]string{server.URL}, "", 0, st, zap.NewNop())
+	sender.httpClient.Timeout = 100 * time.Millisecond
+
+	// 退出前等待投递，超时后返回
+	sender.Notify(&notify.Notification{Event: "slow"})
+	if sender.WaitTimeout(10 * time.Millisecond) {
+		t.Error("WaitTimeout() should time out while a delivery is in flight")
+	}
+	close(release)
+	if !sender.WaitTimeout(time.Second) {
+		t.Error("WaitTimeout() should return after the delivery finishes")
+	}
+
+	// 进程退出时留下的 pending 记录，超过重试窗口后才重放
+	stale := &store.WebhookDelivery{URL: server.URL, Event: "failed", Payload: "{}"}
+	if err := st.SaveWebhookDelivery(stale); err != nil {
+		t.Fatalf("save delivery: %v", err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	fresh := &store.WebhookDelivery{URL: server.URL, Event: "failed", Payload: "{}"}
+	if err := st.SaveWebhookDelivery(fresh); err != nil {
+		t.Fatalf("save delivery: %v", err)
+	}
+
+	delivered, err := sender.ReplayFailed(context.Background())
+	if err != nil || delivered != 1 {
+		t.Fatalf("ReplayFailed() = %d, %v", delivered, err)
+	}
+	if got, _ := st.GetWebhookDelivery(stale.ID); got.Status != store.DeliveryDelivered {
+		t.Errorf("stale delivery status = %s", got.Status)
+	}
+	if got, _ := st.GetWebhookDelivery(fresh.ID); got.Status != store.DeliveryPending {
+		t.Errorf("fresh delivery status = %s", got.Status)
+	}
+}
@@ -0,0 +1,233 @@
+package wecom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/notify"
+	"go.uber.org/zap"
+)
+
+// DefaultBaseURL 企业微信 API 地址，服务器 IP 不在可信 IP 列表时可以改为代理地址
+const DefaultBaseURL = "https://qyapi.weixin.qq.com"
+
+// tokenExpiryMargin access_token 提前过期的余量，避免临界时间使用失效的 token
+const tokenExpiryMargin = 5 * time.Minute
+
+// 企业微信错误码
+const (
+	errInvalidToken = 40014 // access_token 无效
+	errTokenExpired = 42001 // access_token 已过期
+)
+
+// Config 企业微信应用消息配置
+type Config struct {
+	BaseURL string
+	CorpID  string
+	AgentID int
+	Secret  string
+	ToUser  string // 接收成员，多个用 | 分隔，@all 表示全部成员
+}
+
+// App 企业微信应用消息通知
+// 有海报时发送图文消息（news），否则发送文本消息；access_token 缓存到过期前，失效时重新获取一次
+type App struct {
+	cfg        Config
+	httpClient *http.Client
+	logger     *zap.Logger
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewApp 创建企业微信应用消息通知
+func NewApp(cfg Config, logger *zap.Logger) *App {
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	if cfg.ToUser == "" {
+		cfg.ToUser = "@all"
+	}
+	return &App{
+		cfg: cfg,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		logger: logger,
+	}
+}
+
+// Name 实现 notify.Notifier
+func (a *App) Name() string {
+	return "wecom"
+}
+
+// Notify 实现 notify.Notifier，异步发送
+func (a *App) Notify(n *notify.Notification) {
+	go func() {
+		if err := a.send(context.Background(), n); err != nil {
+			a.logger.Error("Failed to send wecom message",
+				zap.String("event", string(n.Event)),
+				zap.String("title", n.Title),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// article 图文消息中的一篇文章
+type article struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url"`
+	PicURL      string `json:"picurl,omitempty"`
+}
+
+// message 应用消息
+type message struct {
+	ToUser  string `json:"touser"`
+	MsgType string `json:"msgtype"`
+	AgentID int    `json:"agentid"`
+	Text    *struct {
+		Content string `json:"content"`
+	} `json:"text,omitempty"`
+	News *struct {
+		Articles []article `json:"articles"`
+	} `json:"news,omitempty"`
+}
+
+// apiResponse 企业微信接口的通用响应
+type apiResponse struct {
+	ErrCode     int    `json:"errcode"`
+	ErrMsg      string `json:"errmsg"`
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// buildMessage 按通知生成应用消息
+func (a *App) buildMessage(n *notify.Notification) *message {
+	rendered := notify.Render(n)
+	msg := &message{ToUser: a.cfg.ToUser, AgentID: a.cfg.AgentID}
+
+	// 图文消息必须有跳转链接，使用 TMDB 条目页面
+	poster, link := notify.PosterURL(n), notify.TMDBURL(n)
+	if poster != "" && link != "" {
+		msg.MsgType = "news"
+		msg.News = &struct {
+			Articles []article `json:"articles"`
+		}{Articles: []article{{
+			Title:       rendered.Title(),
+			Description: rendered.Body(),
+			URL:         link,
+			PicURL:      poster,
+		}}}
+		return msg
+	}
+
+	msg.MsgType = "text"
+	msg.Text = &struct {
+		Content string `json:"content"`
+	}{Content: rendered.Title() + "\n\n" + rendered.Body()}
+	return msg
+}
+
+// send 发送一条通知，token 失效时刷新后重试一次
+func (a *App) send(ctx context.Context, n *notify.Notification) error {
+	data, err := json.Marshal(a.buildMessage(n))
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		token, err := a.accessToken(ctx)
+		if err != nil {
+			return err
+		}
+
+		var resp apiResponse
+		endpoint := a.cfg.BaseURL + "/cgi-bin/message/send?access_token=" + url.QueryEscape(token)
+		if err := a.do(ctx, "POST", endpoint, data, &resp); err != nil {
+			return err
+		}
+		switch resp.ErrCode {
+		case 0:
+			return nil
+		case errInvalidToken, errTokenExpired:
+			a.invalidateToken(token)
+			continue
+		default:
+			return fmt.Errorf("send message: errcode %d: %s", resp.ErrCode, resp.ErrMsg)
+		}
+	}
+	return fmt.Errorf("send message: access token rejected")
+}
+
+// accessToken 返回缓存的 access_token，过期时重新获取
+func (a *App) accessToken(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && time.Now().Before(a.tokenExpiry) {
+		return a.token, nil
+	}
+
+	q := url.Values{}
+	q.Set("corpid", a.cfg.CorpID)
+	q.Set("corpsecret", a.cfg.Secret)
+
+	var resp apiResponse
+	if err := a.do(ctx, "GET", a.cfg.BaseURL+"/cgi-bin/gettoken?"+q.Encode(), nil, &resp); err != nil {
+		return "", err
+	}
+	if resp.ErrCode != 0 || resp.AccessToken == "" {
+		return "", fmt.Errorf("get access token: errcode %d: %s", resp.ErrCode, resp.ErrMsg)
+	}
+
+	a.token = resp.AccessToken
+	a.tokenExpiry = time.Now().Add(time.Duration(resp.ExpiresIn)*time.Second - tokenExpiryMargin)
+	a.logger.Debug("WeCom access token refreshed", zap.Int("expires_in", resp.ExpiresIn))
+	return a.token, nil
+}
+
+// invalidateToken 清除失效的 token（已被其他请求刷新时不处理）
+func (a *App) invalidateToken(token string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.token == token {
+		a.token = ""
+	}
+}
+
+// do 发送请求并解析 JSON 响应
+func (a *App) do(ctx context.Context, method, endpoint string, body []byte, out *apiResponse) error {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package wecom
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/jellyseerr-moviepilot-syncer/internal/notify"
+	"go.uber.org/zap"
+)
+
+func TestAppSend(t *testing.T) {
+	tokenRequests := 0
+	var messages []message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cgi-bin/gettoken":
+			if r.URL.Query().Get("corpid") != "corp" || r.URL.Query().Get("corpsecret") != "secret" {
+				json.NewEncoder(w).Encode(apiResponse{ErrCode: 40001, ErrMsg: "invalid credential"})
+				return
+			}
+			tokenRequests++
+			json.NewEncoder(w).Encode(apiResponse{AccessToken: "token-" + strconv.Itoa(tokenRequests), ExpiresIn: 7200})
+		case "/cgi-bin/message/send":
+			// 第一个 token 在第二次发送时过期
+			if r.URL.Query().Get("access_token") == "token-1" && len(messages) == 1 {
+				json.NewEncoder(w).Encode(apiResponse{ErrCode: errTokenExpired, ErrMsg: "access_token expired"})
+				return
+			}
+			var msg message
+			json.NewDecoder(r.Body).Decode(&msg)
+			messages = append(messages, msg)
+			json.NewEncoder(w).Encode(apiResponse{ErrMsg: "ok"})
+		}
+	}))
+	defer server.Close()
+
+	app := NewApp(Config{BaseURL: server.URL + "/", CorpID: "corp", AgentID: 1000002, Secret: "secret"}, zap.NewNop())
+
+	// 有海报时发送图文消息
+	err := app.send(context.Background(), &notify.Notification{
+		Event:      notify.EventSubscribed,
+		Title:      "沙丘",
+		MediaType:  "movie",
+		TMDBID:     438631,
+		PosterPath: "/dune.jpg",
+	})
+	if err != nil {
+		t.Fatalf("send() error = %v", err)
+	}
+	// token 过期后重新获取并重试
+	if err := app.send(context.Background(), &notify.Notification{Event: notify.EventError, Reason: "同步失败"}); err != nil {
+		t.Fatalf("send() error = %v", err)
+	}
+
+	if tokenRequests != 2 {
+		t.Errorf("expected 2 token requests, got %d", tokenRequests)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+
+	news := messages[0]
+	if news.MsgType != "news" || news.ToUser != "@all" || news.AgentID != 1000002 || news.News == nil {
+		t.Fatalf("unexpected news message %+v", news)
+	}
+	card := news.News.Articles[0]
+	if card.Title != "✅ 已自动订阅" || card.PicURL != notify.PosterBaseURL+"/dune.jpg" ||
+		card.URL != "https://www.themoviedb.org/movie/438631" || !strings.Contains(card.Description, "📺 沙丘") {
+		t.Errorf("unexpected article %+v", card)
+	}
+
+	text := messages[1]
+	if text.MsgType != "text" || text.Text == nil || !strings.HasPrefix(text.Text.Content, "⚠️ 系统错误\n\n💬 同步失败") {
+		t.Errorf("unexpected text message %+v", text)
+	}
+
+	// token 仍有效时不重新获取
+	if err := app.send(context.Background(), &notify.Notification{Event: notify.EventTransferred, Title: "沙丘"}); err != nil {
+		t.Fatalf("send() error = %v", err)
+	}
+	if tokenRequests != 2 {
+		t.Errorf("expected cached token, got %d token requests", tokenRequests)
+	}
+
+	bad := NewApp(Config{BaseURL: server.URL, CorpID: "corp", AgentID: 1, Secret: "wrong"}, zap.NewNop())
+	if err := bad.send(context.Background(), &notify.Notification{Event: notify.EventError}); err == nil {
+		t.Error("expected token error")
+	}
+}